@@ -18,7 +18,7 @@ var (
 func configAddressesRouter(router *httprouter.Router) {
 	router.GET("/addresses", GetAllAddresses)
 	router.POST("/addresses", AddAddresses)
-	router.GET("/addresses/:argID", GetAddresses)
+	router.GET("/addresses/:argID", withTrash("addresses", GetAddresses))
 	router.PUT("/addresses/:argID", UpdateAddresses)
 	router.DELETE("/addresses/:argID", DeleteAddresses)
 }
@@ -26,7 +26,7 @@ func configAddressesRouter(router *httprouter.Router) {
 func configGinAddressesRouter(router gin.IRoutes) {
 	router.GET("/addresses", ConverHttprouterToGin(GetAllAddresses))
 	router.POST("/addresses", ConverHttprouterToGin(AddAddresses))
-	router.GET("/addresses/:argID", ConverHttprouterToGin(GetAddresses))
+	router.GET("/addresses/:argID", ConverHttprouterToGin(withTrash("addresses", GetAddresses)))
 	router.PUT("/addresses/:argID", ConverHttprouterToGin(UpdateAddresses))
 	router.DELETE("/addresses/:argID", ConverHttprouterToGin(DeleteAddresses))
 }
@@ -0,0 +1,504 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/policy"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// APIKeyScheme Authorization scheme of api keys, ie Authorization: ApiKey rk_3f9a1c2e5b7d_...
+	APIKeyScheme = "ApiKey"
+
+	// apiKeyPrefixLength length of the public prefix of a key, rk_ followed by 12 hex digits
+	apiKeyPrefixLength = 15
+
+	// apiKeyTouchInterval how often last_used_at is written for a key in constant use
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey error when the Authorization header carries an unknown, revoked or expired api key
+var ErrInvalidAPIKey = errors.New("invalid, revoked or expired api key")
+
+// APIKeyRequest body posted to /apikeys, on update empty fields keep their current value
+type APIKeyRequest struct {
+	Name string `json:"name" example:"iot gateway"`
+
+	// Scopes comma separated table:action pairs the key may use, * for any table or action, ie interventions:RetrieveMany,elevators:*
+	Scopes string `json:"scopes" example:"elevators:*,columns:*,batteries:*"`
+
+	// Roles comma separated policy roles the key acts with, the default role of the policy when empty
+	Roles string `json:"roles" example:"technician"`
+
+	ExpiresAt *time.Time `json:"expires_at"`
+
+	// NoExpiry remove the expiry of the key on update
+	NoExpiry bool `json:"no_expiry"`
+
+	Active *bool `json:"active"`
+}
+
+// APIKeyRotateRequest body posted to /apikeys/{argID}/rotate
+type APIKeyRotateRequest struct {
+	// Grace how long the replaced key is still accepted, ie 24h, empty or 0 revokes it at once
+	Grace string `json:"grace" example:"24h"`
+}
+
+// APIKeyCreated response to a new or rotated key, the only response carrying the key
+type APIKeyCreated struct {
+	APIKey interface{} `json:"api_key"`
+	Key    string      `json:"key" example:"rk_3f9a1c2e5b7d_Xq0c..."`
+}
+
+func configAPIKeysRouter(router *httprouter.Router) {
+	router.GET("/apikeys", GetAllAPIKeys)
+	router.POST("/apikeys", AddAPIKeys)
+	router.GET("/apikeys/:argID", GetAPIKeys)
+	router.PUT("/apikeys/:argID", UpdateAPIKeys)
+	router.DELETE("/apikeys/:argID", DeleteAPIKeys)
+	router.POST("/apikeys/:argID/rotate", RotateAPIKeys)
+}
+
+func configGinAPIKeysRouter(router gin.IRoutes) {
+	router.GET("/apikeys", ConverHttprouterToGin(GetAllAPIKeys))
+	router.POST("/apikeys", ConverHttprouterToGin(AddAPIKeys))
+	router.GET("/apikeys/:argID", ConverHttprouterToGin(GetAPIKeys))
+	router.PUT("/apikeys/:argID", ConverHttprouterToGin(UpdateAPIKeys))
+	router.DELETE("/apikeys/:argID", ConverHttprouterToGin(DeleteAPIKeys))
+	router.POST("/apikeys/:argID/rotate", ConverHttprouterToGin(RotateAPIKeys))
+}
+
+// GetAllAPIKeys is a function to get a slice of api keys, the keys and their hashes are never returned
+// @Summary Get list of api keys
+// @Tags ApiKeys
+// @Description GetAllAPIKeys is a handler to list the api keys of machine clients with their scopes, expiry and last use
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.APIKeys}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /apikeys [get]
+// http "https://xinqi.dev:443/apikeys?page=0&pagesize=20" X-Api-User:user123
+func GetAllAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.RetrieveMany); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	records, totalRows, err := dao.GetAllAPIKeys(ctx, page, pagesize, order)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(ctx, w, result)
+}
+
+// GetAPIKeys is a function to get a single api key, the key and its hash are never returned
+// @Summary Get an api key by argID
+// @Tags ApiKeys
+// @Description GetAPIKeys is a handler to get a single api key
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Success 200 {object} model.APIKeys
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /apikeys/{argID} [get]
+// http "https://xinqi.dev:443/apikeys/1" X-Api-User:user123
+func GetAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.RetrieveOne); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetAPIKeys(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// AddAPIKeys add an api key, the key is generated and returned only in this response, only its hash is stored
+// @Summary Add an api key
+// @Tags ApiKeys
+// @Description AddAPIKeys issues a key for a machine client, sent as Authorization: ApiKey <key> and restricted to its scopes and roles
+// @Accept  json
+// @Produce  json
+// @Param  APIKeyRequest body api.APIKeyRequest true "api key"
+// @Success 200 {object} api.APIKeyCreated
+// @Failure 400 {object} api.HTTPError
+// @Router /apikeys [post]
+// echo '{"name": "iot gateway", "scopes": "elevators:*,columns:*,batteries:*", "roles": "technician"}' | http POST "https://xinqi.dev:443/apikeys" X-Api-User:user123
+func AddAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	request := &APIKeyRequest{}
+	if err := readJSON(r, request); err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	record := &model.APIKeys{Scopes: policy.Wildcard, Active: true}
+	request.apply(record)
+
+	key, prefix, err := newAPIKey()
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+	record.Prefix, record.KeyHash = prefix, hashAPIKey(key)
+
+	if err := validateAPIKey(record); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.Create); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, _, err = dao.AddAPIKeys(ctx, record)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, &APIKeyCreated{APIKey: model.Redact(record), Key: key})
+}
+
+// UpdateAPIKeys update an api key, fields left out of the request keep their value
+// @Summary Update an api key
+// @Tags ApiKeys
+// @Description UpdateAPIKeys changes the name, scopes, roles, expiry or state of a key, set active to false to revoke it
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Param  APIKeyRequest body api.APIKeyRequest true "changed fields"
+// @Success 200 {object} model.APIKeys
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /apikeys/{argID} [put]
+// echo '{"active": false}' | http PUT "https://xinqi.dev:443/apikeys/1" X-Api-User:user123
+func UpdateAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	request := &APIKeyRequest{}
+	if err := readJSON(r, request); err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.Update); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetAPIKeys(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	request.apply(record)
+	if err := validateAPIKey(record); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, _, err = dao.SaveAPIKeys(ctx, record)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// DeleteAPIKeys delete an api key, requests carrying it are rejected at once
+// @Summary Delete an api key
+// @Tags ApiKeys
+// @Description DeleteAPIKeys removes a key, set active to false instead to keep its record
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Success 204 {object} model.APIKeys
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /apikeys/{argID} [delete]
+// http DELETE "https://xinqi.dev:443/apikeys/1" X-Api-User:user123
+func DeleteAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.Delete); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	rowsAffected, err := dao.DeleteAPIKeys(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
+
+// RotateAPIKeys replace the key of an api key, the new key is returned only in this response
+// @Summary Rotate an api key
+// @Tags ApiKeys
+// @Description RotateAPIKeys issues a new key keeping the scopes and roles, the replaced key stays valid for the grace period so clients can switch over
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Param  APIKeyRotateRequest body api.APIKeyRotateRequest false "grace period"
+// @Success 200 {object} api.APIKeyCreated
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /apikeys/{argID}/rotate [post]
+// echo '{"grace": "24h"}' | http POST "https://xinqi.dev:443/apikeys/1/rotate" X-Api-User:user123
+func RotateAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	request := &APIKeyRotateRequest{}
+	if r.ContentLength != 0 {
+		if err := readJSON(r, request); err != nil {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+	}
+
+	var grace time.Duration
+	if request.Grace != "" {
+		if grace, err = time.ParseDuration(request.Grace); err != nil || grace < 0 {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+	}
+
+	if err := ValidateRequest(ctx, r, "api_keys", model.Update); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetAPIKeys(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	key, _, err := newAPIKey()
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	// the prefix is kept so logs and rate limits follow the key across rotations
+	key = record.Prefix + key[apiKeyPrefixLength:]
+	if grace > 0 {
+		record.PreviousKeyHash = null.StringFrom(record.KeyHash)
+		record.PreviousKeyExpiresAt = null.TimeFrom(time.Now().Add(grace))
+	} else {
+		record.PreviousKeyHash = null.String{}
+		record.PreviousKeyExpiresAt = null.Time{}
+	}
+	record.KeyHash = hashAPIKey(key)
+
+	record, _, err = dao.SaveAPIKeys(ctx, record)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, &APIKeyCreated{APIKey: model.Redact(record), Key: key})
+}
+
+// apply copy the fields set in the request to record
+func (k *APIKeyRequest) apply(record *model.APIKeys) {
+	if k.Name != "" {
+		record.Name = k.Name
+	}
+
+	if k.Scopes != "" {
+		record.Scopes = k.Scopes
+	}
+
+	if k.Roles != "" {
+		record.Roles = k.Roles
+	}
+
+	if k.ExpiresAt != nil {
+		record.ExpiresAt = null.TimeFrom(*k.ExpiresAt)
+	} else if k.NoExpiry {
+		record.ExpiresAt = null.Time{}
+	}
+
+	if k.Active != nil {
+		record.Active = *k.Active
+	}
+}
+
+// validateAPIKey require a name and scopes naming known tables and actions or *
+func validateAPIKey(record *model.APIKeys) error {
+	if strings.TrimSpace(record.Name) == "" || len(splitList(record.Scopes)) == 0 {
+		return dao.ErrBadParams
+	}
+
+	for _, scope := range splitList(record.Scopes) {
+		table, action := splitScope(scope)
+		if _, ok := model.GetTableInfo(table); !ok && table != policy.Wildcard && !policy.PseudoTables[table] {
+			return dao.ErrBadParams
+		}
+		if action != policy.Wildcard && policy.ParseAction(action) < 0 {
+			return dao.ErrBadParams
+		}
+	}
+	return nil
+}
+
+// splitScope the table and action of a table:action scope, a bare table allows every action
+func splitScope(scope string) (table, action string) {
+	parts := strings.SplitN(scope, ":", 2)
+	if len(parts) == 1 {
+		return strings.TrimSpace(parts[0]), policy.Wildcard
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// splitList the non empty trimmed items of a comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newAPIKey a random key made of its public prefix, rk_ and 12 hex digits, an underscore and 32 random bytes
+func newAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 38)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	prefix = "rk_" + hex.EncodeToString(buf[:6])
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(buf[6:]), prefix, nil
+}
+
+// hashAPIKey the sha256 stored for a key, keys are random enough that a slow hash adds nothing
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticateAPIKey resolve the key of an Authorization: ApiKey header into the principal of its api_keys record
+func authenticateAPIKey(r *http.Request, key string) (*Principal, error) {
+	if len(key) <= apiKeyPrefixLength || key[apiKeyPrefixLength] != '_' {
+		return nil, ErrInvalidAPIKey
+	}
+
+	ctx := r.Context()
+	record, err := dao.GetAPIKeysByPrefix(ctx, key[:apiKeyPrefixLength])
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	hash := []byte(hashAPIKey(key))
+	current := subtle.ConstantTimeCompare(hash, []byte(record.KeyHash)) == 1
+	previous := record.PreviousKeyHash.Valid && record.PreviousKeyExpiresAt.Valid && now.Before(record.PreviousKeyExpiresAt.Time) &&
+		subtle.ConstantTimeCompare(hash, []byte(record.PreviousKeyHash.String)) == 1
+	if !current && !previous {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if !record.Active || (record.ExpiresAt.Valid && !now.Before(record.ExpiresAt.Time)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	touchAPIKey(ctx, record, now, ClientIP(r))
+
+	principal := &Principal{ID: record.ID, Type: PrincipalAPIKey, Roles: splitList(record.Roles), Scopes: splitList(record.Scopes)}
+	if len(principal.Roles) == 0 && AccessPolicy != nil {
+		principal.Roles = []string{AccessPolicy.DefaultRole}
+	}
+	if principal.Scopes == nil {
+		principal.Scopes = []string{}
+	}
+	return principal, nil
+}
+
+// touchAPIKey record the use of a key at most once per apiKeyTouchInterval and whenever it is used from another address
+func touchAPIKey(ctx context.Context, record *model.APIKeys, now time.Time, ip string) {
+	if record.LastUsedAt.Valid && now.Sub(record.LastUsedAt.Time) < apiKeyTouchInterval && record.LastUsedIP.String == ip {
+		return
+	}
+
+	// failing to record the use must not fail the request
+	_ = dao.TouchAPIKeys(ctx, record.ID, now, ip)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+func configAuditRouter(router *httprouter.Router) {
+	router.GET("/audit", GetAllAuditLogs)
+}
+
+func configGinAuditRouter(router gin.IRoutes) {
+	router.GET("/audit", ConverHttprouterToGin(GetAllAuditLogs))
+}
+
+// GetAllAuditLogs is a function to get a slice of audit_logs entries recorded for creates, updates and deletes, newest first
+// @Summary Get list of audit log entries
+// @Tags AuditLogs
+// @Description GetAllAuditLogs is a handler to query who changed which record, when, from where and the field level diff of the change
+// @Accept  json
+// @Produce  json
+// @Param   table    query    string  false        "table name, ie elevators"
+// @Param   id       query    string  false        "primary key of the changed record"
+// @Param   actor    query    string  false        "email or id of the account that made the change"
+// @Param   since    query    string  false        "RFC3339 time or yyyy-mm-dd date of the oldest entry"
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Success 200 {object} api.PagedResults{data=[]model.AuditLogs}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /audit [get]
+// http "https://xinqi.dev:443/audit?table=elevators&id=12&since=2021-03-01" X-Api-User:user123
+func GetAllAuditLogs(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	filter := &dao.AuditFilter{
+		Table:    r.FormValue("table"),
+		RecordID: r.FormValue("id"),
+		Actor:    r.FormValue("actor"),
+	}
+
+	if since := r.FormValue("since"); since != "" {
+		if filter.Since, err = parseSince(since); err != nil {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+	}
+
+	if err := ValidateRequest(ctx, r, "audit_logs", model.RetrieveMany); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	records, totalRows, err := dao.GetAllAuditLogs(ctx, filter, page, pagesize)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(ctx, w, result)
+}
+
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+// requestActor the actor changes made by a request are attributed to
+func requestActor(ctx context.Context, r *http.Request) *dao.Actor {
+	actor := &dao.Actor{IPAddress: ClientIP(r)}
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		actor.Type = principal.Type
+		actor.ID = principal.ID
+		actor.Email = principal.Email
+	}
+	return actor
+}
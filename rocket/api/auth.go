@@ -0,0 +1,419 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/notify"
+	"rocket/policy"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// PrincipalAdminUser principal type of an account from the admin_users table
+	PrincipalAdminUser = "AdminUser"
+
+	// PrincipalUser principal type of an account from the users table
+	PrincipalUser = "User"
+
+	// PrincipalAPIKey principal type of a machine client authenticated with a key from the api_keys table
+	PrincipalAPIKey = "ApiKey"
+)
+
+var (
+	// ErrUnauthorized error when a request carries no valid credentials
+	ErrUnauthorized = errors.New("authentication required")
+
+	// ErrInvalidToken error when the Authorization header carries a malformed, forged or expired token
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	// ErrInvalidCredentials error when login email or password do not match
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// Auth settings used to issue and verify tokens, installed by ConfigureAuth
+	Auth *AuthConfig
+
+	// dummyPasswordHash compared against when an email is unknown so unknown and known emails take the same time
+	dummyPasswordHash = []byte("$2a$11$HAtX0dHjj3hZXyvsFZH.mOXcWbF19nTVfK1NaoBPggmomFyYFWHym")
+)
+
+// AuthConfig settings for token authentication
+type AuthConfig struct {
+	// Secret key used to sign tokens
+	Secret []byte
+
+	// AccessTTL lifetime of access tokens
+	AccessTTL time.Duration
+
+	// RefreshTTL lifetime of refresh tokens
+	RefreshTTL time.Duration
+
+	// Pepper devise pepper appended to passwords before hashing, empty unless config.pepper is set in the rails app
+	Pepper string
+
+	// Mailer delivers password reset instructions, nil disables password resets
+	Mailer notify.Notifier
+
+	// ResetTTL how long a password reset token stays valid
+	ResetTTL time.Duration
+
+	// ResetURL page of the front end the reset token is appended to as the reset_password_token parameter
+	ResetURL string
+}
+
+// Principal the authenticated caller of a request
+type Principal struct {
+	ID         int64    `json:"id"`
+	Type       string   `json:"type"`
+	Email      string   `json:"email"`
+	EmployeeID int64    `json:"employee_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+
+	// Scopes table:action pairs an api key is restricted to, nil for accounts which are only restricted by their roles
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// IsAdmin reports if the principal is an admin_users account
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Type == PrincipalAdminUser
+}
+
+// InScope reports if the scopes of the principal allow action on table, principals without scopes are not restricted
+func (p *Principal) InScope(table string, action model.Action) bool {
+	if p.Scopes == nil {
+		return true
+	}
+
+	for _, scope := range p.Scopes {
+		scopeTable, scopeAction := splitScope(scope)
+		if (scopeTable == policy.Wildcard || scopeTable == table) && (scopeAction == policy.Wildcard || policy.ParseAction(scopeAction) == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginRequest credentials posted to /auth/login, Account selects the table ("admin" or "user"), empty tries admin_users then users
+type LoginRequest struct {
+	Email    string `json:"email" example:"admin@example.com"`
+	Password string `json:"password" example:"password"`
+	Account  string `json:"account" example:"admin"`
+}
+
+// RefreshRequest body posted to /auth/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse tokens issued by /auth/login and /auth/refresh
+type TokenResponse struct {
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token"`
+	TokenType    string     `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64      `json:"expires_in"`
+	Principal    *Principal `json:"principal"`
+}
+
+type authContextKey int
+
+const (
+	principalContextKey authContextKey = iota
+	authErrorContextKey
+)
+
+// ConfigureAuth install token authentication, every request validated through ValidateRequest must carry a valid access token
+func ConfigureAuth(config *AuthConfig) {
+	Auth = config
+	ContextInitializer = AuthenticateRequest
+	RequestValidator = RequireAuthentication
+}
+
+// AuthenticateRequest ContextInitializerFunc that resolves the Authorization header of a request into a Principal stored in the context,
+// a request an earlier middleware already authenticated, ie GinRateLimit, is returned as is
+func AuthenticateRequest(r *http.Request) context.Context {
+	ctx := r.Context()
+	if Auth == nil || ctx.Value(principalContextKey) != nil || ctx.Value(authErrorContextKey) != nil {
+		return ctx
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return ctx
+	}
+
+	principal, err := authenticateHeader(r, header)
+	if err != nil {
+		return context.WithValue(ctx, authErrorContextKey, err)
+	}
+
+	return WithPrincipal(ctx, principal)
+}
+
+// RequireAuthentication RequestValidatorFunc rejecting requests without an authenticated principal and api keys used outside their scopes
+func RequireAuthentication(ctx context.Context, r *http.Request, table string, action model.Action) error {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		if !principal.InScope(table, action) {
+			return ErrForbidden
+		}
+		return nil
+	}
+
+	if err, ok := ctx.Value(authErrorContextKey).(error); ok {
+		return err
+	}
+	return ErrUnauthorized
+}
+
+// WithPrincipal return a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+// PrincipalFromContext return the authenticated principal of a request, nil when the request is anonymous
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey).(*Principal)
+	return principal
+}
+
+func authenticateHeader(r *http.Request, header string) (*Principal, error) {
+	scheme, credentials := splitAuthorization(header)
+	if strings.EqualFold(scheme, APIKeyScheme) && credentials != "" {
+		return authenticateAPIKey(r, credentials)
+	}
+
+	if !strings.EqualFold(scheme, "Bearer") || credentials == "" {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := ParseToken(Auth.Secret, credentials, time.Now())
+	if err != nil || claims.Kind != TokenAccess {
+		return nil, ErrInvalidToken
+	}
+
+	// a password change or reset, or a deleted account, revokes the access tokens issued before it
+	_, encryptedPassword, err := findAccountByID(r.Context(), claims.Type, claims.Subject)
+	if err != nil || claims.PasswordFingerprint != passwordFingerprint(Auth.Secret, encryptedPassword) {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		ID:         claims.Subject,
+		Type:       claims.Type,
+		Email:      claims.Email,
+		EmployeeID: claims.EmployeeID,
+		Roles:      claims.Roles,
+	}, nil
+}
+
+func splitAuthorization(header string) (scheme, credentials string) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+func configAuthRouter(router *httprouter.Router) {
+	router.POST("/auth/login", Login)
+	router.POST("/auth/refresh", RefreshToken)
+	router.GET("/auth/me", GetCurrentPrincipal)
+}
+
+func configGinAuthRouter(router gin.IRoutes) {
+	router.POST("/auth/login", ConverHttprouterToGin(Login))
+	router.POST("/auth/refresh", ConverHttprouterToGin(RefreshToken))
+	router.GET("/auth/me", ConverHttprouterToGin(GetCurrentPrincipal))
+}
+
+// Login is a function to verify an email and password against the devise encrypted_password of admin_users or users and issue tokens
+// @Summary Login with email and password
+// @Tags Auth
+// @Description Login verifies the email and password against the bcrypt encrypted_password column and returns an access and a refresh token
+// @Accept  json
+// @Produce  json
+// @Param LoginRequest body api.LoginRequest true "credentials"
+// @Success 200 {object} api.TokenResponse
+// @Failure 400 {object} api.HTTPError
+// @Failure 401 {object} api.HTTPError
+// @Router /auth/login [post]
+// echo '{"email": "admin@example.com", "password": "password"}' | http POST "https://xinqi.dev:443/auth/login"
+func Login(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if Auth == nil {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	login := &LoginRequest{}
+	if err := readJSON(r, login); err != nil || login.Email == "" || login.Password == "" {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	principal, encryptedPassword, err := findAccount(ctx, login.Account, login.Email)
+	if err != nil {
+		// spend the same time as a real comparison so response times do not reveal which emails exist
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(login.Password+Auth.Pepper))
+		returnError(ctx, w, r, ErrInvalidCredentials)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(encryptedPassword), []byte(login.Password+Auth.Pepper)); err != nil {
+		returnError(ctx, w, r, ErrInvalidCredentials)
+		return
+	}
+
+	issueTokens(ctx, w, r, principal, encryptedPassword)
+}
+
+// RefreshToken is a function to exchange a refresh token for a new pair of tokens
+// @Summary Refresh tokens
+// @Tags Auth
+// @Description RefreshToken exchanges a valid refresh token for new access and refresh tokens, tokens issued before a password change are rejected
+// @Accept  json
+// @Produce  json
+// @Param RefreshRequest body api.RefreshRequest true "refresh token"
+// @Success 200 {object} api.TokenResponse
+// @Failure 400 {object} api.HTTPError
+// @Failure 401 {object} api.HTTPError
+// @Router /auth/refresh [post]
+// echo '{"refresh_token": "eyJ..."}' | http POST "https://xinqi.dev:443/auth/refresh"
+func RefreshToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if Auth == nil {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	refresh := &RefreshRequest{}
+	if err := readJSON(r, refresh); err != nil || refresh.RefreshToken == "" {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	claims, err := ParseToken(Auth.Secret, refresh.RefreshToken, time.Now())
+	if err != nil || claims.Kind != TokenRefresh {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	principal, encryptedPassword, err := findAccountByID(ctx, claims.Type, claims.Subject)
+	if err != nil || claims.PasswordFingerprint != passwordFingerprint(Auth.Secret, encryptedPassword) {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	issueTokens(ctx, w, r, principal, encryptedPassword)
+}
+
+// GetCurrentPrincipal is a function to return the principal authenticated by the request token
+// @Summary Get the authenticated principal
+// @Tags Auth
+// @Description GetCurrentPrincipal returns the account the access token was issued to
+// @Produce  json
+// @Success 200 {object} api.Principal
+// @Failure 401 {object} api.HTTPError
+// @Router /auth/me [get]
+// http "https://xinqi.dev:443/auth/me" "Authorization:Bearer eyJ..."
+func GetCurrentPrincipal(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	writeJSON(ctx, w, principal)
+}
+
+func issueTokens(ctx context.Context, w http.ResponseWriter, r *http.Request, principal *Principal, encryptedPassword string) {
+	resolvePrincipal(ctx, principal)
+
+	now := time.Now()
+	claims := &TokenClaims{
+		Subject:             principal.ID,
+		Type:                principal.Type,
+		Email:               principal.Email,
+		EmployeeID:          principal.EmployeeID,
+		Roles:               principal.Roles,
+		Kind:                TokenAccess,
+		IssuedAt:            now.Unix(),
+		ExpiresAt:           now.Add(Auth.AccessTTL).Unix(),
+		PasswordFingerprint: passwordFingerprint(Auth.Secret, encryptedPassword),
+	}
+
+	access, err := SignToken(Auth.Secret, claims)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	claims.Kind = TokenRefresh
+	claims.ExpiresAt = now.Add(Auth.RefreshTTL).Unix()
+	refresh, err := SignToken(Auth.Secret, claims)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, &TokenResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(Auth.AccessTTL / time.Second),
+		Principal:    principal,
+	})
+}
+
+// findAccount look up an account by email in admin_users and/or users depending on account
+func findAccount(ctx context.Context, account, email string) (principal *Principal, encryptedPassword string, err error) {
+	switch account {
+	case "", "admin", "admin_user", PrincipalAdminUser:
+		if record, err := dao.GetAdminUsersByEmail(ctx, email); err == nil {
+			return &Principal{ID: record.ID, Type: PrincipalAdminUser, Email: record.Email}, record.EncryptedPassword, nil
+		}
+		if account != "" {
+			return nil, "", dao.ErrNotFound
+		}
+		fallthrough
+	case "user", PrincipalUser:
+		record, err := dao.GetUsersByEmail(ctx, email)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Principal{ID: record.ID, Type: PrincipalUser, Email: record.Email}, record.EncryptedPassword, nil
+	default:
+		return nil, "", dao.ErrBadParams
+	}
+}
+
+// findAccountByID reload the account a token was issued to
+func findAccountByID(ctx context.Context, principalType string, id int64) (principal *Principal, encryptedPassword string, err error) {
+	switch principalType {
+	case PrincipalAdminUser:
+		record, err := dao.GetAdminUsers(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Principal{ID: record.ID, Type: PrincipalAdminUser, Email: record.Email}, record.EncryptedPassword, nil
+	case PrincipalUser:
+		record, err := dao.GetUsers_(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		return &Principal{ID: record.ID, Type: PrincipalUser, Email: record.Email}, record.EncryptedPassword, nil
+	default:
+		return nil, "", dao.ErrNotFound
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/policy"
+
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrForbidden error when the authenticated principal is not allowed to perform an action
+	ErrForbidden = errors.New("access denied")
+
+	// AccessPolicy role based policy installed by ConfigurePolicy, nil when only authentication is enforced
+	AccessPolicy *policy.Policy
+)
+
+// ConfigurePolicy install role based authorization on top of token authentication.
+// Table level decisions are made by RequestValidator, row level conditions are enforced by the dao hooks.
+func ConfigurePolicy(p *policy.Policy) {
+	AccessPolicy = p
+	RequestValidator = AuthorizeRequest
+	dao.RecordAuthorizer = AuthorizeRecord
+	dao.QueryScoper = ScopeQuery
+}
+
+// AuthorizeRequest RequestValidatorFunc requiring an authenticated principal whose roles allow action on table
+func AuthorizeRequest(ctx context.Context, r *http.Request, table string, action model.Action) error {
+	if err := RequireAuthentication(ctx, r, table, action); err != nil {
+		return err
+	}
+
+	if AccessPolicy == nil {
+		return nil
+	}
+
+	if !AccessPolicy.Decide(subjectOf(PrincipalFromContext(ctx)), table, action).Allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeRecord dao.RecordAuthorizerFunc enforcing row level policy conditions.
+// Calls without a principal come from background jobs inside the service and are not restricted.
+func AuthorizeRecord(ctx context.Context, table string, action model.Action, record interface{}) error {
+	principal := PrincipalFromContext(ctx)
+	if AccessPolicy == nil || principal == nil {
+		return nil
+	}
+
+	if !AccessPolicy.Check(subjectOf(principal), table, action, record) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ScopeQuery dao.QueryScoperFunc restricting list queries to the rows the principal may read
+func ScopeQuery(ctx context.Context, table string, action model.Action, db *gorm.DB) *gorm.DB {
+	principal := PrincipalFromContext(ctx)
+	if AccessPolicy == nil || principal == nil {
+		return db
+	}
+
+	subject := subjectOf(principal)
+	decision := AccessPolicy.Decide(subject, table, action)
+	if !decision.Allowed {
+		return db.Where("1 = 0")
+	}
+
+	if !decision.Unconditional() {
+		clause, args := conditionsClause(db, table, decision.Conditions)
+		db = db.Where(clause, args...)
+	}
+
+	for _, cond := range AccessPolicy.DenyConditions(subject, table, action) {
+		clause, args := conditionsClause(db, table, []policy.Condition{cond})
+		db = db.Where("NOT ("+clause+")", args...)
+	}
+
+	return db
+}
+
+// conditionsClause build "(a = ? AND b = ?) OR (c = ?)" from a list of conditions
+func conditionsClause(db *gorm.DB, table string, conditions []policy.Condition) (string, []interface{}) {
+	var (
+		ors  []string
+		args []interface{}
+	)
+
+	for _, cond := range conditions {
+		var ands []string
+		for column, value := range cond {
+			if col, ok := policy.FindColumn(table, column); ok {
+				column = col.Name
+			}
+			ands = append(ands, fmt.Sprintf("%s = ?", db.Dialect().Quote(column)))
+			args = append(args, value)
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return strings.Join(ors, " OR "), args
+}
+
+// subjectOf describe a principal to the policy engine
+func subjectOf(principal *Principal) *policy.Subject {
+	subject := &policy.Subject{
+		Roles:      principal.Roles,
+		Attributes: map[string]interface{}{"email": principal.Email},
+	}
+
+	switch principal.Type {
+	case PrincipalUser:
+		subject.Attributes["user_id"] = principal.ID
+	case PrincipalAdminUser:
+		subject.Attributes["admin_user_id"] = principal.ID
+	case PrincipalAPIKey:
+		subject.Attributes["api_key_id"] = principal.ID
+	}
+
+	if principal.EmployeeID != 0 {
+		subject.Attributes["employee_id"] = principal.EmployeeID
+	}
+
+	return subject
+}
+
+// resolvePrincipal fill in the employee record and roles of a principal when tokens are issued
+func resolvePrincipal(ctx context.Context, principal *Principal) {
+	title := ""
+	if principal.Type == PrincipalUser {
+		if employee, err := dao.GetEmployeesByUserID(ctx, principal.ID); err == nil {
+			principal.EmployeeID = employee.ID
+			title = employee.Title.String
+		}
+	}
+
+	if AccessPolicy != nil {
+		principal.Roles = AccessPolicy.RolesFor(principal.IsAdmin(), principal.Email, title)
+	}
+}
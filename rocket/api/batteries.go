@@ -18,7 +18,7 @@ var (
 func configBatteriesRouter(router *httprouter.Router) {
 	router.GET("/batteries", GetAllBatteries)
 	router.POST("/batteries", AddBatteries)
-	router.GET("/batteries/:argID", GetBatteries)
+	router.GET("/batteries/:argID", withTrash("batteries", GetBatteries))
 	router.PUT("/batteries/:argID", UpdateBatteries)
 	router.DELETE("/batteries/:argID", DeleteBatteries)
 }
@@ -26,7 +26,7 @@ func configBatteriesRouter(router *httprouter.Router) {
 func configGinBatteriesRouter(router gin.IRoutes) {
 	router.GET("/batteries", ConverHttprouterToGin(GetAllBatteries))
 	router.POST("/batteries", ConverHttprouterToGin(AddBatteries))
-	router.GET("/batteries/:argID", ConverHttprouterToGin(GetBatteries))
+	router.GET("/batteries/:argID", ConverHttprouterToGin(withTrash("batteries", GetBatteries)))
 	router.PUT("/batteries/:argID", ConverHttprouterToGin(UpdateBatteries))
 	router.DELETE("/batteries/:argID", ConverHttprouterToGin(DeleteBatteries))
 }
@@ -0,0 +1,375 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	defaultDashboardWorkers = 4
+	maxDashboardWorkers     = 16
+	defaultDashboardTimeout = 30 * time.Second
+	maxDashboardTimeout     = 5 * time.Minute
+	defaultDashboardMaxRows = 1000
+)
+
+// BlazerDashboardFull a dashboard together with its queries in display order
+type BlazerDashboardFull struct {
+	*model.BlazerDashboards
+	Queries []*BlazerDashboardFullQuery `json:"queries"`
+}
+
+// BlazerDashboardFullQuery a query placed on a dashboard, Result is only set when the dashboard was requested with run=true
+type BlazerDashboardFullQuery struct {
+	DashboardQueryID int64                `json:"dashboard_query_id"`
+	Position         int64                `json:"position"`
+	Query            *model.BlazerQueries `json:"query"`
+	Result           *dao.StatementResult `json:"result,omitempty"`
+	Error            string               `json:"error,omitempty"`
+}
+
+// BlazerDashboardLayout request body to replace the ordered list of queries on a dashboard
+type BlazerDashboardLayout struct {
+	QueryIDs []int64 `json:"query_ids"`
+}
+
+// BlazerDashboardQueryPlacement request body to add a query to a dashboard, a missing position appends the query
+type BlazerDashboardQueryPlacement struct {
+	QueryID  int64  `json:"query_id"`
+	Position *int64 `json:"position"`
+}
+
+func configBlazerDashboardsFullRouter(router *httprouter.Router) {
+	router.GET("/blazerdashboards/:argID/full", GetBlazerDashboardsFull)
+	router.PUT("/blazerdashboards/:argID/queries", SetBlazerDashboardsQueries)
+	router.POST("/blazerdashboards/:argID/queries", AddBlazerDashboardsQuery)
+	router.DELETE("/blazerdashboards/:argID/queries/:queryID", RemoveBlazerDashboardsQuery)
+}
+
+func configGinBlazerDashboardsFullRouter(router gin.IRoutes) {
+	router.GET("/blazerdashboards/:argID/full", ConverHttprouterToGin(GetBlazerDashboardsFull))
+	router.PUT("/blazerdashboards/:argID/queries", ConverHttprouterToGin(SetBlazerDashboardsQueries))
+	router.POST("/blazerdashboards/:argID/queries", ConverHttprouterToGin(AddBlazerDashboardsQuery))
+	router.DELETE("/blazerdashboards/:argID/queries/:queryID", ConverHttprouterToGin(RemoveBlazerDashboardsQuery))
+}
+
+// GetBlazerDashboardsFull is a function to get a dashboard with its queries ordered by position, optionally running every query
+// @Summary Get a dashboard with its queries
+// @Tags BlazerDashboards
+// @Description GetBlazerDashboardsFull returns the dashboard, its blazer_dashboard_queries ordered by position and the blazer_queries they reference.
+// @Description With run=true each query must be a single select statement, it runs in a read only transaction and sensitive columns are left out of its result.
+// @Accept  json
+// @Produce  json
+// @Param  argID    path  int64  true  "dashboard id"
+// @Param  run      query bool   false "execute every query and include the results, requires the Execute action on blazer_queries"
+// @Param  workers  query int    false "number of queries executed concurrently (defaults to 4, max 16)"
+// @Param  timeout  query string false "per query timeout as a duration (defaults to 30s, max 5m)"
+// @Param  max_rows query int    false "maximum number of rows returned per query (defaults to 1000)"
+// @Success 200 {object} api.BlazerDashboardFull
+// @Failure 400 {object} api.HTTPError
+// @Router /blazerdashboards/{argID}/full [get]
+// http "https://xinqi.dev:443/blazerdashboards/1/full?run=true&timeout=10s" X-Api-User:user123
+func GetBlazerDashboardsFull(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	run := r.FormValue("run") == "true" || r.FormValue("run") == "1"
+
+	workers, err := readInt(r, "workers", defaultDashboardWorkers)
+	if err != nil || workers <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+	if workers > maxDashboardWorkers {
+		workers = maxDashboardWorkers
+	}
+
+	timeout := defaultDashboardTimeout
+	if v := r.FormValue("timeout"); v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil || timeout <= 0 {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+	}
+	if timeout > maxDashboardTimeout {
+		timeout = maxDashboardTimeout
+	}
+
+	maxRows, err := readInt(r, "max_rows", defaultDashboardMaxRows)
+	if err != nil || maxRows <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	for table, action := range map[string]model.Action{
+		"blazer_dashboards":        model.RetrieveOne,
+		"blazer_dashboard_queries": model.RetrieveMany,
+		"blazer_queries":           model.RetrieveMany,
+	} {
+		if err := ValidateRequest(ctx, r, table, action); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+	}
+
+	// running the statements is a separate permission, every role may read the queries of a dashboard
+	if run {
+		if err := ValidateRequest(ctx, r, "blazer_queries", model.Execute); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+	}
+
+	dashboard, err := dao.GetBlazerDashboards(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	layout, err := dao.GetBlazerDashboardLayout(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result, err := buildBlazerDashboardFull(ctx, dashboard, layout)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if run {
+		runBlazerDashboardQueries(ctx, result.Queries, int(workers), timeout, int(maxRows))
+	}
+
+	writeJSON(ctx, w, result)
+}
+
+// SetBlazerDashboardsQueries replace the queries of a dashboard in a single transaction
+// @Summary Reorder the queries of a dashboard
+// @Tags BlazerDashboards
+// @Description SetBlazerDashboardsQueries replaces the queries of a dashboard with the listed query ids in order, queries not listed are removed
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "dashboard id"
+// @Param  BlazerDashboardLayout body api.BlazerDashboardLayout true "ordered query ids"
+// @Success 200 {object} api.BlazerDashboardFull
+// @Failure 400 {object} api.HTTPError
+// @Router /blazerdashboards/{argID}/queries [put]
+// echo '{"query_ids": [3, 1, 2]}' | http PUT "https://xinqi.dev:443/blazerdashboards/1/queries" X-Api-User:user123
+func SetBlazerDashboardsQueries(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	layout := &BlazerDashboardLayout{}
+	if err := readJSON(r, layout); err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if !validateBlazerDashboardLayoutRequest(ctx, w, r) {
+		return
+	}
+
+	records, err := dao.SetBlazerDashboardLayout(ctx, argID, layout.QueryIDs)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeBlazerDashboardFull(ctx, w, r, argID, records)
+}
+
+// AddBlazerDashboardsQuery add a query to a dashboard
+// @Summary Add a query to a dashboard
+// @Tags BlazerDashboards
+// @Description AddBlazerDashboardsQuery adds a query at the requested position, following queries move down
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "dashboard id"
+// @Param  BlazerDashboardQueryPlacement body api.BlazerDashboardQueryPlacement true "query to add"
+// @Success 200 {object} api.BlazerDashboardFull
+// @Failure 400 {object} api.HTTPError
+// @Router /blazerdashboards/{argID}/queries [post]
+// echo '{"query_id": 4, "position": 0}' | http POST "https://xinqi.dev:443/blazerdashboards/1/queries" X-Api-User:user123
+func AddBlazerDashboardsQuery(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	placement := &BlazerDashboardQueryPlacement{}
+	if err := readJSON(r, placement); err != nil || placement.QueryID == 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	position := int64(-1)
+	if placement.Position != nil {
+		position = *placement.Position
+	}
+
+	if !validateBlazerDashboardLayoutRequest(ctx, w, r) {
+		return
+	}
+
+	records, err := dao.AddBlazerDashboardQuery(ctx, argID, placement.QueryID, position)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeBlazerDashboardFull(ctx, w, r, argID, records)
+}
+
+// RemoveBlazerDashboardsQuery remove a query from a dashboard
+// @Summary Remove a query from a dashboard
+// @Tags BlazerDashboards
+// @Description RemoveBlazerDashboardsQuery removes a query from a dashboard and closes the gap in positions
+// @Accept  json
+// @Produce  json
+// @Param  argID   path int64 true "dashboard id"
+// @Param  queryID path int64 true "query id"
+// @Success 200 {object} api.BlazerDashboardFull
+// @Failure 400 {object} api.HTTPError
+// @Router /blazerdashboards/{argID}/queries/{queryID} [delete]
+// http DELETE "https://xinqi.dev:443/blazerdashboards/1/queries/4" X-Api-User:user123
+func RemoveBlazerDashboardsQuery(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	queryID, err := parseInt64(ps, "queryID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if !validateBlazerDashboardLayoutRequest(ctx, w, r) {
+		return
+	}
+
+	records, err := dao.RemoveBlazerDashboardQuery(ctx, argID, queryID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeBlazerDashboardFull(ctx, w, r, argID, records)
+}
+
+// validateBlazerDashboardLayoutRequest layout changes may create, update and delete blazer_dashboard_queries records
+func validateBlazerDashboardLayoutRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) bool {
+	for _, action := range []model.Action{model.Create, model.Update, model.Delete} {
+		if err := ValidateRequest(ctx, r, "blazer_dashboard_queries", action); err != nil {
+			returnError(ctx, w, r, err)
+			return false
+		}
+	}
+	return true
+}
+
+func writeBlazerDashboardFull(ctx context.Context, w http.ResponseWriter, r *http.Request, dashboardID int64, layout []*model.BlazerDashboardQueries) {
+	dashboard, err := dao.GetBlazerDashboards(ctx, dashboardID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result, err := buildBlazerDashboardFull(ctx, dashboard, layout)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, result)
+}
+
+func buildBlazerDashboardFull(ctx context.Context, dashboard *model.BlazerDashboards, layout []*model.BlazerDashboardQueries) (*BlazerDashboardFull, error) {
+	ids := make([]int64, 0, len(layout))
+	for _, record := range layout {
+		if record.QueryID.Valid {
+			ids = append(ids, record.QueryID.Int64)
+		}
+	}
+
+	queries, err := dao.GetBlazerQueriesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BlazerDashboardFull{BlazerDashboards: dashboard, Queries: make([]*BlazerDashboardFullQuery, 0, len(layout))}
+	for _, record := range layout {
+		query, ok := queries[record.QueryID.Int64]
+		if !record.QueryID.Valid || !ok {
+			// dangling dashboard query, the referenced query was deleted
+			continue
+		}
+
+		result.Queries = append(result.Queries, &BlazerDashboardFullQuery{
+			DashboardQueryID: record.ID,
+			Position:         record.Position.Int64,
+			Query:            query,
+		})
+	}
+
+	return result, nil
+}
+
+// runBlazerDashboardQueries executes the queries with at most workers running at once, each bounded by timeout
+func runBlazerDashboardQueries(ctx context.Context, queries []*BlazerDashboardFullQuery, workers int, timeout time.Duration, maxRows int) {
+	jobs := make(chan *BlazerDashboardFullQuery)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(queries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := range jobs {
+				runCtx, cancel := context.WithTimeout(ctx, timeout)
+				result, err := dao.ExecuteStatement(runCtx, q.Query.Statement.String, maxRows)
+				cancel()
+
+				switch {
+				case err == context.DeadlineExceeded:
+					q.Error = "query timed out after " + timeout.String()
+				case err != nil:
+					q.Error = err.Error()
+				default:
+					q.Result = redactStatementResult(result)
+				}
+			}
+		}()
+	}
+
+	for _, q := range queries {
+		jobs <- q
+	}
+	close(jobs)
+	wg.Wait()
+}
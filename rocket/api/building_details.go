@@ -18,7 +18,7 @@ var (
 func configBuildingDetailsRouter(router *httprouter.Router) {
 	router.GET("/buildingdetails", GetAllBuildingDetails)
 	router.POST("/buildingdetails", AddBuildingDetails)
-	router.GET("/buildingdetails/:argID", GetBuildingDetails)
+	router.GET("/buildingdetails/:argID", withTrash("building_details", GetBuildingDetails))
 	router.PUT("/buildingdetails/:argID", UpdateBuildingDetails)
 	router.DELETE("/buildingdetails/:argID", DeleteBuildingDetails)
 }
@@ -26,7 +26,7 @@ func configBuildingDetailsRouter(router *httprouter.Router) {
 func configGinBuildingDetailsRouter(router gin.IRoutes) {
 	router.GET("/buildingdetails", ConverHttprouterToGin(GetAllBuildingDetails))
 	router.POST("/buildingdetails", ConverHttprouterToGin(AddBuildingDetails))
-	router.GET("/buildingdetails/:argID", ConverHttprouterToGin(GetBuildingDetails))
+	router.GET("/buildingdetails/:argID", ConverHttprouterToGin(withTrash("building_details", GetBuildingDetails)))
 	router.PUT("/buildingdetails/:argID", ConverHttprouterToGin(UpdateBuildingDetails))
 	router.DELETE("/buildingdetails/:argID", ConverHttprouterToGin(DeleteBuildingDetails))
 }
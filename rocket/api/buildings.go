@@ -18,7 +18,7 @@ var (
 func configBuildingsRouter(router *httprouter.Router) {
 	router.GET("/buildings", GetAllBuildings)
 	router.POST("/buildings", AddBuildings)
-	router.GET("/buildings/:argID", GetBuildings)
+	router.GET("/buildings/:argID", withTrash("buildings", GetBuildings))
 	router.PUT("/buildings/:argID", UpdateBuildings)
 	router.DELETE("/buildings/:argID", DeleteBuildings)
 }
@@ -26,7 +26,7 @@ func configBuildingsRouter(router *httprouter.Router) {
 func configGinBuildingsRouter(router gin.IRoutes) {
 	router.GET("/buildings", ConverHttprouterToGin(GetAllBuildings))
 	router.POST("/buildings", ConverHttprouterToGin(AddBuildings))
-	router.GET("/buildings/:argID", ConverHttprouterToGin(GetBuildings))
+	router.GET("/buildings/:argID", ConverHttprouterToGin(withTrash("buildings", GetBuildings)))
 	router.PUT("/buildings/:argID", ConverHttprouterToGin(UpdateBuildings))
 	router.DELETE("/buildings/:argID", ConverHttprouterToGin(DeleteBuildings))
 }
@@ -18,7 +18,7 @@ var (
 func configColumnsRouter(router *httprouter.Router) {
 	router.GET("/columns", GetAllColumns)
 	router.POST("/columns", AddColumns)
-	router.GET("/columns/:argID", GetColumns)
+	router.GET("/columns/:argID", withTrash("columns", GetColumns))
 	router.PUT("/columns/:argID", UpdateColumns)
 	router.DELETE("/columns/:argID", DeleteColumns)
 }
@@ -26,7 +26,7 @@ func configColumnsRouter(router *httprouter.Router) {
 func configGinColumnsRouter(router gin.IRoutes) {
 	router.GET("/columns", ConverHttprouterToGin(GetAllColumns))
 	router.POST("/columns", ConverHttprouterToGin(AddColumns))
-	router.GET("/columns/:argID", ConverHttprouterToGin(GetColumns))
+	router.GET("/columns/:argID", ConverHttprouterToGin(withTrash("columns", GetColumns)))
 	router.PUT("/columns/:argID", ConverHttprouterToGin(UpdateColumns))
 	router.DELETE("/columns/:argID", ConverHttprouterToGin(DeleteColumns))
 }
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"net/http"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null"
+	"github.com/julienschmidt/httprouter"
+)
+
+// defaultCommentNamespace namespace ActiveAdmin registers its resources in unless configured otherwise
+const defaultCommentNamespace = "admin"
+
+// CommentThread a comment with the replies posted to it
+type CommentThread struct {
+	*model.ActiveAdminComments
+	Replies []*CommentThread `json:"replies"`
+}
+
+// CommentRequest body posted to /<resource>/{argID}/comments, ParentID makes the comment a reply
+type CommentRequest struct {
+	Body      string `json:"body" example:"Door sensor replaced"`
+	ParentID  int64  `json:"parent_id"`
+	Namespace string `json:"namespace" example:"admin"`
+}
+
+func configCommentsRouter(router *httprouter.Router) {
+	for _, crud := range commentableEndpoints() {
+		router.GET(crud.RetrieveOneURL+"/:argID/comments", GetResourceComments(crud.Name))
+		router.POST(crud.RetrieveOneURL+"/:argID/comments", AddResourceComment(crud.Name))
+	}
+}
+
+func configGinCommentsRouter(router gin.IRoutes) {
+	for _, crud := range commentableEndpoints() {
+		router.GET(crud.RetrieveOneURL+"/:argID/comments", ConverHttprouterToGin(GetResourceComments(crud.Name)))
+		router.POST(crud.RetrieveOneURL+"/:argID/comments", ConverHttprouterToGin(AddResourceComment(crud.Name)))
+	}
+}
+
+// commentableEndpoints tables with a single integer primary key, the only kind active_admin_comments.resource_id can reference
+func commentableEndpoints() []*CrudAPI {
+	var endpoints []*CrudAPI
+	for _, crud := range crudEndpoints {
+		if crud.Name == "active_admin_comments" || crud.TableInfo == nil {
+			continue
+		}
+
+		keys := 0
+		integer := false
+		for _, col := range crud.TableInfo.Columns {
+			if col.IsPrimaryKey {
+				keys++
+				integer = col.GoFieldType == "int64" || col.GoFieldType == "int32"
+			}
+		}
+
+		if keys == 1 && integer {
+			endpoints = append(endpoints, crud)
+		}
+	}
+	return endpoints
+}
+
+// GetResourceComments returns a handler listing the ActiveAdmin comments of a record of table as threads
+// @Summary Get the comments of a record
+// @Tags Comments
+// @Description GetResourceComments lists the active_admin_comments of a record, oldest first, with replies nested under the comment they answer
+// @Accept  json
+// @Produce  json
+// @Param  resource  path string true "resource url, ie elevators"
+// @Param  argID     path int64  true "id of the record"
+// @Param  namespace query string false "ActiveAdmin namespace (defaults to admin)"
+// @Success 200 {array} api.CommentThread
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /{resource}/{argID}/comments [get]
+// http "https://xinqi.dev:443/elevators/1/comments" X-Api-User:user123
+func GetResourceComments(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+
+		argID, err := parseInt64(ps, "argID")
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		namespace := r.FormValue("namespace")
+		if namespace == "" {
+			namespace = defaultCommentNamespace
+		}
+
+		if err := ValidateRequest(ctx, r, table, model.RetrieveOne); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		if err := ValidateRequest(ctx, r, "active_admin_comments", model.RetrieveMany); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		if err := dao.ResourceExists(ctx, table, argID); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		records, err := dao.GetResourceComments(ctx, model.RailsClassName(table), argID, namespace)
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		writeJSON(ctx, w, commentThreads(records))
+	}
+}
+
+// AddResourceComment returns a handler adding an ActiveAdmin comment to a record of table, the author is the authenticated caller.
+// Api keys may not comment as ActiveAdmin could not load them as the author.
+// @Summary Add a comment to a record
+// @Tags Comments
+// @Description AddResourceComment stores an active_admin_comments row for the record that ActiveAdmin shows on the resource page, resource and author are taken from the route and the access token
+// @Accept  json
+// @Produce  json
+// @Param  resource       path string true "resource url, ie elevators"
+// @Param  argID          path int64  true "id of the record"
+// @Param  CommentRequest body api.CommentRequest true "comment"
+// @Success 200 {object} model.ActiveAdminComments
+// @Failure 400 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /{resource}/{argID}/comments [post]
+// echo '{"body": "Door sensor replaced", "parent_id": 3}' | http POST "https://xinqi.dev:443/elevators/1/comments" X-Api-User:user123
+func AddResourceComment(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+
+		argID, err := parseInt64(ps, "argID")
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		comment := &CommentRequest{}
+		if err := readJSON(r, comment); err != nil || comment.Body == "" {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+
+		if comment.Namespace == "" {
+			comment.Namespace = defaultCommentNamespace
+		}
+
+		if err := ValidateRequest(ctx, r, table, model.RetrieveOne); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		if err := ValidateRequest(ctx, r, "comments", model.Create); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		// ActiveAdmin loads the author as a rails model, api keys have none
+		if principal := PrincipalFromContext(ctx); principal != nil && principal.Type == PrincipalAPIKey {
+			returnError(ctx, w, r, ErrForbidden)
+			return
+		}
+
+		if err := dao.ResourceExists(ctx, table, argID); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		record := &model.ActiveAdminComments{
+			Namespace:    null.StringFrom(comment.Namespace),
+			Body:         null.StringFrom(comment.Body),
+			ResourceType: null.StringFrom(model.RailsClassName(table)),
+			ResourceID:   null.IntFrom(argID),
+		}
+
+		if comment.ParentID != 0 {
+			parent, err := dao.GetActiveAdminComments(ctx, comment.ParentID)
+			if err != nil || parent.ResourceType != record.ResourceType || parent.ResourceID != record.ResourceID || parent.Namespace != record.Namespace {
+				// replies must stay on the record and namespace of the comment they answer
+				returnError(ctx, w, r, dao.ErrBadParams)
+				return
+			}
+			record.ParentID = null.IntFrom(parent.ID)
+		}
+
+		if principal := PrincipalFromContext(ctx); principal != nil {
+			record.AuthorType = null.StringFrom(principal.Type)
+			record.AuthorID = null.IntFrom(principal.ID)
+		}
+
+		record.Prepare()
+		if err := record.Validate(model.Create); err != nil {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+
+		record, err = dao.AddResourceComment(ctx, record)
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		writeJSON(ctx, w, record)
+	}
+}
+
+// commentThreads nest replies under their parent comment, comments whose parent is missing are listed at the top level
+func commentThreads(records []*model.ActiveAdminComments) []*CommentThread {
+	threads := make(map[int64]*CommentThread, len(records))
+	for _, record := range records {
+		threads[record.ID] = &CommentThread{ActiveAdminComments: record, Replies: []*CommentThread{}}
+	}
+
+	roots := []*CommentThread{}
+	for _, record := range records {
+		thread := threads[record.ID]
+		if parent, ok := threads[record.ParentID.Int64]; ok && record.ParentID.Valid && parent != thread {
+			parent.Replies = append(parent.Replies, thread)
+			continue
+		}
+		roots = append(roots, thread)
+	}
+	return roots
+}
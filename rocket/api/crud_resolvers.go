@@ -0,0 +1,678 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"rocket/dao"
+	"rocket/model"
+)
+
+// crudResolver the dao functions backing the graphql and grpc reads and writes of a table
+type crudResolver struct {
+	get    func(ctx context.Context, id string) (model.Model, error)
+	add    func(ctx context.Context, record model.Model) (model.Model, error)
+	update func(ctx context.Context, id string, record model.Model) (model.Model, error)
+	delete func(ctx context.Context, id string) (int64, error)
+}
+
+// parseRecordID the int64 primary key given as a graphql ID or grpc key
+func parseRecordID(id string) (int64, error) {
+	argID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return -1, dao.ErrBadParams
+	}
+	return argID, nil
+}
+
+// crudResolvers resolvers of the tables exposed by /graphql and the grpc services
+var crudResolvers = map[string]*crudResolver{
+	"active_admin_comments": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetActiveAdminComments(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddActiveAdminComments(ctx, record.(*model.ActiveAdminComments))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateActiveAdminComments(ctx, argID, record.(*model.ActiveAdminComments))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteActiveAdminComments(ctx, argID)
+		},
+	},
+	"active_storage_attachments": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetActiveStorageAttachments(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddActiveStorageAttachments(ctx, record.(*model.ActiveStorageAttachments))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateActiveStorageAttachments(ctx, argID, record.(*model.ActiveStorageAttachments))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteActiveStorageAttachments(ctx, argID)
+		},
+	},
+	"active_storage_blobs": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetActiveStorageBlobs(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddActiveStorageBlobs(ctx, record.(*model.ActiveStorageBlobs))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateActiveStorageBlobs(ctx, argID, record.(*model.ActiveStorageBlobs))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteActiveStorageBlobs(ctx, argID)
+		},
+	},
+	"addresses": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetAddresses(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddAddresses(ctx, record.(*model.Addresses))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateAddresses(ctx, argID, record.(*model.Addresses))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteAddresses(ctx, argID)
+		},
+	},
+	"admin_users": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetAdminUsers(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddAdminUsers(ctx, record.(*model.AdminUsers))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateAdminUsers(ctx, argID, record.(*model.AdminUsers))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteAdminUsers(ctx, argID)
+		},
+	},
+	"ar_internal_metadata": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			return dao.GetArInternalMetadata(ctx, id)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddArInternalMetadata(ctx, record.(*model.ArInternalMetadata))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			result, _, err := dao.UpdateArInternalMetadata(ctx, id, record.(*model.ArInternalMetadata))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			return dao.DeleteArInternalMetadata(ctx, id)
+		},
+	},
+	"batteries": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBatteries(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBatteries(ctx, record.(*model.Batteries))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBatteries(ctx, argID, record.(*model.Batteries))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBatteries(ctx, argID)
+		},
+	},
+	"blazer_audits": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBlazerAudits(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBlazerAudits(ctx, record.(*model.BlazerAudits))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBlazerAudits(ctx, argID, record.(*model.BlazerAudits))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBlazerAudits(ctx, argID)
+		},
+	},
+	"blazer_checks": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBlazerChecks(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBlazerChecks(ctx, record.(*model.BlazerChecks))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBlazerChecks(ctx, argID, record.(*model.BlazerChecks))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBlazerChecks(ctx, argID)
+		},
+	},
+	"blazer_dashboard_queries": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBlazerDashboardQueries(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBlazerDashboardQueries(ctx, record.(*model.BlazerDashboardQueries))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBlazerDashboardQueries(ctx, argID, record.(*model.BlazerDashboardQueries))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBlazerDashboardQueries(ctx, argID)
+		},
+	},
+	"blazer_dashboards": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBlazerDashboards(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBlazerDashboards(ctx, record.(*model.BlazerDashboards))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBlazerDashboards(ctx, argID, record.(*model.BlazerDashboards))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBlazerDashboards(ctx, argID)
+		},
+	},
+	"blazer_queries": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBlazerQueries(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBlazerQueries(ctx, record.(*model.BlazerQueries))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBlazerQueries(ctx, argID, record.(*model.BlazerQueries))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBlazerQueries(ctx, argID)
+		},
+	},
+	"building_details": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBuildingDetails(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBuildingDetails(ctx, record.(*model.BuildingDetails))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBuildingDetails(ctx, argID, record.(*model.BuildingDetails))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBuildingDetails(ctx, argID)
+		},
+	},
+	"buildings": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetBuildings(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddBuildings(ctx, record.(*model.Buildings))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateBuildings(ctx, argID, record.(*model.Buildings))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteBuildings(ctx, argID)
+		},
+	},
+	"columns": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetColumns(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddColumns(ctx, record.(*model.Columns))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateColumns(ctx, argID, record.(*model.Columns))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteColumns(ctx, argID)
+		},
+	},
+	"customers": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetCustomers(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddCustomers(ctx, record.(*model.Customers))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateCustomers(ctx, argID, record.(*model.Customers))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteCustomers(ctx, argID)
+		},
+	},
+	"elevators": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetElevators(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddElevators(ctx, record.(*model.Elevators))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateElevators(ctx, argID, record.(*model.Elevators))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteElevators(ctx, argID)
+		},
+	},
+	"employees": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetEmployees(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddEmployees(ctx, record.(*model.Employees))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateEmployees(ctx, argID, record.(*model.Employees))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteEmployees(ctx, argID)
+		},
+	},
+	"interventions": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetInterventions(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddInterventions(ctx, record.(*model.Interventions))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateInterventions(ctx, argID, record.(*model.Interventions))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteInterventions(ctx, argID)
+		},
+	},
+	"leads": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetLeads(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddLeads(ctx, record.(*model.Leads))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateLeads(ctx, argID, record.(*model.Leads))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteLeads(ctx, argID)
+		},
+	},
+	"maps": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetMaps(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddMaps(ctx, record.(*model.Maps))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateMaps(ctx, argID, record.(*model.Maps))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteMaps(ctx, argID)
+		},
+	},
+	"quotes": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetQuotes(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddQuotes(ctx, record.(*model.Quotes))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateQuotes(ctx, argID, record.(*model.Quotes))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteQuotes(ctx, argID)
+		},
+	},
+	"schema_migrations": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			return dao.GetSchemaMigrations(ctx, id)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddSchemaMigrations(ctx, record.(*model.SchemaMigrations))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			result, _, err := dao.UpdateSchemaMigrations(ctx, id, record.(*model.SchemaMigrations))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			return dao.DeleteSchemaMigrations(ctx, id)
+		},
+	},
+	"users": {
+		get: func(ctx context.Context, id string) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			return dao.GetUsers_(ctx, argID)
+		},
+		add: func(ctx context.Context, record model.Model) (model.Model, error) {
+			result, _, err := dao.AddUsers_(ctx, record.(*model.Users_))
+			return result, err
+		},
+		update: func(ctx context.Context, id string, record model.Model) (model.Model, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return nil, err
+			}
+			result, _, err := dao.UpdateUsers_(ctx, argID, record.(*model.Users_))
+			return result, err
+		},
+		delete: func(ctx context.Context, id string) (int64, error) {
+			argID, err := parseRecordID(id)
+			if err != nil {
+				return -1, err
+			}
+			return dao.DeleteUsers_(ctx, argID)
+		},
+	},
+}
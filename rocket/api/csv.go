@@ -0,0 +1,406 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocket/dao"
+	"rocket/logging"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// maxImportSize largest csv file accepted by /<resource>/import
+	maxImportSize = 32 << 20
+
+	// csvFlushRows number of exported rows buffered before they are flushed to the client
+	csvFlushRows = 100
+)
+
+// csvTimeLayouts layouts accepted for date and time cells, spreadsheets rarely keep RFC3339
+var csvTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func configCSVRouter(router *httprouter.Router) {
+	for _, crud := range crudEndpoints {
+		router.GET(crud.RetrieveManyURL+".csv", ExportCSV(crud.Name))
+		router.POST(crud.CreateURL+"/:argID", withImport(crud.Name))
+	}
+}
+
+func configGinCSVRouter(router gin.IRoutes) {
+	for _, crud := range crudEndpoints {
+		router.GET(crud.RetrieveManyURL+".csv", ConverHttprouterToGin(ExportCSV(crud.Name)))
+		router.POST(crud.CreateURL+"/:argID", ConverHttprouterToGin(withImport(crud.Name)))
+	}
+}
+
+// withImport route POST <resource>/import, the path shares its position with the {argID} of the record routes
+func withImport(table string) httprouter.Handle {
+	importCSV := ImportCSV(table)
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName("argID") != "import" {
+			http.NotFound(w, r)
+			return
+		}
+		importCSV(w, r, ps)
+	}
+}
+
+// ExportCSV returns a handler streaming the records of table as csv, the header row holds the json field names
+// @Summary Export records as csv
+// @Tags CSV
+// @Description ExportCSV streams every record of a table the caller may read as csv, query parameters named after a column filter on that column
+// @Produce  text/csv
+// @Param   resource path   string true  "resource url, ie elevators"
+// @Param   order    query  string false "sort order, comma separated columns each optionally followed by asc or desc, ie status,id desc"
+// @Success 200 {string} string "csv file"
+// @Failure 400 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Router /{resource}.csv [get]
+// http "https://xinqi.dev:443/elevators.csv?status=Active&order=column_id,id" X-Api-User:user123
+func ExportCSV(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+
+		if err := ValidateRequest(ctx, r, table, model.RetrieveMany); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		info, _ := model.GetTableInfo(table)
+		filter := &dao.RecordFilter{Where: make(map[string]interface{}), Order: r.FormValue("order")}
+		for name, values := range r.URL.Query() {
+			if name == "order" {
+				continue
+			}
+
+			col := csvColumn(info, name)
+			if col == nil || col.IsSensitive || len(values) != 1 {
+				returnError(ctx, w, r, dao.ErrBadParams)
+				return
+			}
+			filter.Where[col.Name] = values[0]
+		}
+
+		columns := info.Redacted().Columns
+		out := csv.NewWriter(w)
+		flusher, _ := w.(http.Flusher)
+
+		started := false
+		start := func() error {
+			started = true
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, table))
+			w.WriteHeader(http.StatusOK)
+
+			header := make([]string, len(columns))
+			for i, col := range columns {
+				header[i] = col.JSONFieldName
+			}
+			return out.Write(header)
+		}
+
+		rows := 0
+		row := make([]string, len(columns))
+		err := dao.StreamRecords(ctx, table, filter, func(record model.Model) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			for i, col := range columns {
+				row[i] = csvValue(record, col)
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+
+			if rows++; rows%csvFlushRows == 0 {
+				out.Flush()
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			return out.Error()
+		})
+
+		if err != nil && !started {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		if err != nil {
+			// the status is already sent, an incomplete file is the only signal left
+			logging.Error(ctx, "csv export aborted", logging.Fields{"table": table, "rows": rows, "error": err})
+			return
+		}
+
+		if !started {
+			start()
+		}
+		out.Flush()
+	}
+}
+
+// ImportCSV returns a handler creating or updating records of table from a csv file
+// @Summary Import records from csv
+// @Tags CSV
+// @Description ImportCSV reads a csv file whose header row names columns by their json field name. Rows whose primary key matches a record update
+// @Description the columns present in the file, other rows are created. The import is all or nothing, with dry_run nothing is written and the report
+// @Description tells what would happen. The file is the request body or the file field of a multipart form.
+// @Accept  text/csv
+// @Produce  json
+// @Param   resource path  string true  "resource url, ie elevators"
+// @Param   dry_run  query bool   false "validate the file without writing it"
+// @Success 200 {object} dao.ImportResult
+// @Failure 400 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Router /{resource}/import [post]
+// http POST "https://xinqi.dev:443/elevators/import?dry_run=true" Content-Type:text/csv X-Api-User:user123 < elevators.csv
+func ImportCSV(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+
+		dryRun := false
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				returnError(ctx, w, r, dao.ErrBadParams)
+				return
+			}
+		}
+
+		allowed := make(map[model.Action]error)
+		for _, action := range []model.Action{model.Create, model.Update} {
+			allowed[action] = ValidateRequest(ctx, r, table, action)
+		}
+
+		if allowed[model.Create] != nil && allowed[model.Update] != nil {
+			returnError(ctx, w, r, allowed[model.Create])
+			return
+		}
+
+		body, err := csvBody(w, r)
+		if err != nil {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+		defer body.Close()
+
+		info, _ := model.GetTableInfo(table)
+		rows, err := readCSVRows(info, body)
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		result, err := dao.ImportRecords(ctx, table, rows, dryRun, func(action model.Action) error {
+			return allowed[action]
+		})
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		writeJSON(ctx, w, result)
+	}
+}
+
+// csvBody the uploaded file of a multipart form or the request body
+func csvBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// readCSVRows convert the rows of a csv file to json objects of the columns of info, rows with unreadable cells carry their error
+func readCSVRows(info *model.TableInfo, body io.Reader) ([]*dao.ImportRow, error) {
+	in := csv.NewReader(body)
+	in.FieldsPerRecord = -1
+
+	header, err := in.Read()
+	if err != nil {
+		return nil, dao.ErrBadParams
+	}
+
+	columns := make([]*model.ColumnInfo, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		if i == 0 {
+			// spreadsheets save utf-8 with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+
+		col := csvColumn(info, strings.TrimSpace(name))
+		switch {
+		case col == nil:
+			return nil, fmt.Errorf("unknown column %s", name)
+		case col.IsSensitive:
+			return nil, fmt.Errorf("column %s can not be written", name)
+		case seen[col.Name]:
+			return nil, fmt.Errorf("column %s appears twice", name)
+		}
+		seen[col.Name] = true
+		columns[i] = col
+	}
+
+	var rows []*dao.ImportRow
+	for line := 2; ; line++ {
+		cells, err := in.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		row := &dao.ImportRow{Line: line}
+		rows = append(rows, row)
+
+		if _, ok := err.(*csv.ParseError); ok {
+			row.Err = &dao.ImportError{Row: line, Message: err.Error()}
+			continue
+		}
+
+		if err != nil {
+			return nil, dao.ErrBadParams
+		}
+
+		if len(cells) != len(columns) {
+			row.Err = &dao.ImportError{Row: line, Message: fmt.Sprintf("expected %d cells, got %d", len(columns), len(cells))}
+			continue
+		}
+
+		values := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			if cells[i] == "" && (col.IsAutoIncrement || col.Name == "created_at" || col.Name == "updated_at") {
+				// left to the database and gorm, ie new rows of an exported file
+				continue
+			}
+
+			if col.Name == "deleted_at" {
+				// exported files carry deleted_at, records are only soft deleted and restored by their own endpoints
+				continue
+			}
+
+			value, err := csvCell(col, cells[i])
+			if err != nil {
+				row.Err = &dao.ImportError{Row: line, Column: col.JSONFieldName, Message: err.Error()}
+				break
+			}
+			values[col.JSONFieldName] = value
+		}
+
+		if row.Err == nil {
+			row.Data, err = json.Marshal(values)
+			if err != nil {
+				row.Err = &dao.ImportError{Row: line, Message: err.Error()}
+			}
+		}
+	}
+}
+
+// csvColumn the column of info with the json or db name name, nil when there is none
+func csvColumn(info *model.TableInfo, name string) *model.ColumnInfo {
+	for _, col := range info.Columns {
+		if strings.EqualFold(col.JSONFieldName, name) || strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return nil
+}
+
+// csvCell the json value of a csv cell of col, empty cells of nullable columns are null
+func csvCell(col *model.ColumnInfo, cell string) (interface{}, error) {
+	if cell == "" {
+		switch {
+		case col.Nullable:
+			return nil, nil
+		case col.GoFieldType == "string":
+			return "", nil
+		default:
+			return nil, fmt.Errorf("a value is required")
+		}
+	}
+
+	trimmed := strings.TrimSpace(cell)
+	switch col.GoFieldType {
+	case "int64", "int32", "null.Int":
+		n, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", cell)
+		}
+		return n, nil
+	case "float64", "float32", "null.Float":
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", cell)
+		}
+		return f, nil
+	case "bool", "null.Bool":
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q, use true or false", cell)
+		}
+		return b, nil
+	case "time.Time", "null.Time":
+		for _, layout := range csvTimeLayouts {
+			if t, err := time.ParseInLocation(layout, trimmed, time.UTC); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %q, use RFC3339 or yyyy-mm-dd hh:mm:ss", cell)
+	case "json.RawMessage":
+		if !json.Valid([]byte(cell)) {
+			return nil, fmt.Errorf("invalid json")
+		}
+		return json.RawMessage(cell), nil
+	case "[]byte":
+		if _, err := base64.StdEncoding.DecodeString(cell); err != nil {
+			return nil, fmt.Errorf("invalid base64")
+		}
+		return cell, nil
+	default:
+		return cell, nil
+	}
+}
+
+// csvValue the csv cell of the value of col in record, nulls are empty cells and times RFC3339
+func csvValue(record model.Model, col *model.ColumnInfo) string {
+	value, _ := model.ColumnValue(record, col)
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case json.RawMessage:
+		return string(v)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
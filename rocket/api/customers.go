@@ -18,7 +18,7 @@ var (
 func configCustomersRouter(router *httprouter.Router) {
 	router.GET("/customers", GetAllCustomers)
 	router.POST("/customers", AddCustomers)
-	router.GET("/customers/:argID", GetCustomers)
+	router.GET("/customers/:argID", withTrash("customers", GetCustomers))
 	router.PUT("/customers/:argID", UpdateCustomers)
 	router.DELETE("/customers/:argID", DeleteCustomers)
 }
@@ -26,7 +26,7 @@ func configCustomersRouter(router *httprouter.Router) {
 func configGinCustomersRouter(router gin.IRoutes) {
 	router.GET("/customers", ConverHttprouterToGin(GetAllCustomers))
 	router.POST("/customers", ConverHttprouterToGin(AddCustomers))
-	router.GET("/customers/:argID", ConverHttprouterToGin(GetCustomers))
+	router.GET("/customers/:argID", ConverHttprouterToGin(withTrash("customers", GetCustomers)))
 	router.PUT("/customers/:argID", ConverHttprouterToGin(UpdateCustomers))
 	router.DELETE("/customers/:argID", ConverHttprouterToGin(DeleteCustomers))
 }
@@ -18,7 +18,7 @@ var (
 func configElevatorsRouter(router *httprouter.Router) {
 	router.GET("/elevators", GetAllElevators)
 	router.POST("/elevators", AddElevators)
-	router.GET("/elevators/:argID", GetElevators)
+	router.GET("/elevators/:argID", withTrash("elevators", GetElevators))
 	router.PUT("/elevators/:argID", UpdateElevators)
 	router.DELETE("/elevators/:argID", DeleteElevators)
 }
@@ -26,7 +26,7 @@ func configElevatorsRouter(router *httprouter.Router) {
 func configGinElevatorsRouter(router gin.IRoutes) {
 	router.GET("/elevators", ConverHttprouterToGin(GetAllElevators))
 	router.POST("/elevators", ConverHttprouterToGin(AddElevators))
-	router.GET("/elevators/:argID", ConverHttprouterToGin(GetElevators))
+	router.GET("/elevators/:argID", ConverHttprouterToGin(withTrash("elevators", GetElevators)))
 	router.PUT("/elevators/:argID", ConverHttprouterToGin(UpdateElevators))
 	router.DELETE("/elevators/:argID", ConverHttprouterToGin(DeleteElevators))
 }
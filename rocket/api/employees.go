@@ -18,7 +18,7 @@ var (
 func configEmployeesRouter(router *httprouter.Router) {
 	router.GET("/employees", GetAllEmployees)
 	router.POST("/employees", AddEmployees)
-	router.GET("/employees/:argID", GetEmployees)
+	router.GET("/employees/:argID", withTrash("employees", GetEmployees))
 	router.PUT("/employees/:argID", UpdateEmployees)
 	router.DELETE("/employees/:argID", DeleteEmployees)
 }
@@ -26,7 +26,7 @@ func configEmployeesRouter(router *httprouter.Router) {
 func configGinEmployeesRouter(router gin.IRoutes) {
 	router.GET("/employees", ConverHttprouterToGin(GetAllEmployees))
 	router.POST("/employees", ConverHttprouterToGin(AddEmployees))
-	router.GET("/employees/:argID", ConverHttprouterToGin(GetEmployees))
+	router.GET("/employees/:argID", ConverHttprouterToGin(withTrash("employees", GetEmployees)))
 	router.PUT("/employees/:argID", ConverHttprouterToGin(UpdateEmployees))
 	router.DELETE("/employees/:argID", ConverHttprouterToGin(DeleteEmployees))
 }
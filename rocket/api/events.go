@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+// eventsHeartbeat how often a comment is sent on idle streams so proxies do not close them
+const eventsHeartbeat = 15 * time.Second
+
+var (
+	// ErrEventsDisabled error when /events is requested while no event broker is configured
+	ErrEventsDisabled = errors.New("event stream is disabled")
+
+	// Events broker installed by ConfigureEvents, nil when the change stream is disabled
+	Events *stream.Broker
+)
+
+// ConfigureEvents install the broker streamed by /events
+func ConfigureEvents(broker *stream.Broker) {
+	Events = broker
+}
+
+func configEventsRouter(router *httprouter.Router) {
+	router.GET("/events", GetEvents)
+}
+
+func configGinEventsRouter(router gin.IRoutes) {
+	router.GET("/events", ConverHttprouterToGin(GetEvents))
+}
+
+// GetEvents streams creates, updates and deletes as Server-Sent Events
+// @Summary Stream record changes
+// @Tags Events
+// @Description GetEvents is a text/event-stream of the changes to the requested tables, each event carries the table, action, record and changed fields.
+// @Description Only records the caller may read are sent. Reconnecting with the Last-Event-ID header resumes after that event,
+// @Description a reset event is sent instead when events were missed and the client must reload.
+// @Produce  text/event-stream
+// @Param   tables        query  string false "comma separated tables, ie elevators,interventions (defaults to every readable table)"
+// @Param   Last-Event-ID header string false "id of the last event received"
+// @Success 200 {object} stream.Event
+// @Failure 400 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Failure 503 {object} api.HTTPError
+// @Router /events [get]
+// http --stream "https://xinqi.dev:443/events?tables=elevators,interventions" X-Api-User:user123
+func GetEvents(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if Events == nil {
+		returnError(ctx, w, r, ErrEventsDisabled)
+		return
+	}
+
+	tables, err := eventTables(ctx, r)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		returnError(ctx, w, r, ErrEventsDisabled)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.FormValue("last_event_id")
+	}
+
+	sub, missed, resumed := Events.Subscribe(lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !resumed {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+
+	send := func(event *stream.Event) {
+		if !tables[event.Table] || AuthorizeRecord(ctx, event.Table, model.RetrieveOne, event.Source) != nil {
+			return
+		}
+		fmt.Fprintf(w, "id: %s\ndata: %s\n\n", event.ID, event.Data)
+	}
+
+	for _, event := range missed {
+		send(event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-sub.C:
+			if !ok {
+				// dropped for falling behind or the broker closed on shutdown, the client reconnects with Last-Event-ID
+				return
+			}
+			send(event)
+		}
+		flusher.Flush()
+	}
+}
+
+// eventTables the tables requested with ?tables that the caller may list, all readable tables when none are requested
+func eventTables(ctx context.Context, r *http.Request) (map[string]bool, error) {
+	tables := make(map[string]bool)
+
+	if requested := r.FormValue("tables"); requested != "" {
+		for _, table := range strings.Split(requested, ",") {
+			table = strings.TrimSpace(table)
+			if _, ok := model.GetTableInfo(table); !ok {
+				return nil, dao.ErrBadParams
+			}
+
+			if err := ValidateRequest(ctx, r, table, model.RetrieveMany); err != nil {
+				return nil, err
+			}
+			tables[table] = true
+		}
+		return tables, nil
+	}
+
+	for table := range crudEndpoints {
+		if err := ValidateRequest(ctx, r, table, model.RetrieveMany); err == nil {
+			tables[table] = true
+		}
+	}
+
+	if len(tables) == 0 {
+		return nil, ErrForbidden
+	}
+	return tables, nil
+}
@@ -0,0 +1,862 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+
+	"rocket/dao"
+	"rocket/graphql"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+// maxGraphQLRequestSize largest request body accepted by /graphql
+const maxGraphQLRequestSize = 1 << 20
+
+func configGraphQLRouter(router *httprouter.Router) {
+	router.GET("/graphql", GraphQL)
+	router.POST("/graphql", GraphQL)
+	router.GET("/graphql/schema", GetGraphQLSchema)
+}
+
+func configGinGraphQLRouter(router gin.IRoutes) {
+	router.GET("/graphql", ConverHttprouterToGin(GraphQL))
+	router.POST("/graphql", ConverHttprouterToGin(GraphQL))
+	router.GET("/graphql/schema", ConverHttprouterToGin(GetGraphQLSchema))
+}
+
+// GraphQL executes a graphql query or mutation against the tables
+// @Summary Execute a graphql query or mutation
+// @Tags GraphQL
+// @Description GraphQL executes a query reading records with their related records, or a mutation creating, updating or deleting
+// @Description a record. Related records of a selection are loaded with one query per relation, the schema is served at /graphql/schema.
+// @Description Queries may be sent with GET, mutations must be posted. An operation selects at most 500 fields and reads at most
+// @Description 10000 records.
+// @Accept  json
+// @Produce  json
+// @Param   request       body     graphql.Request  false  "query, operationName and variables"
+// @Param   query         query    string           false  "query, when sent with GET"
+// @Param   operationName query    string           false  "operation to execute, when sent with GET"
+// @Param   variables     query    string           false  "json object of the variables, when sent with GET"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} graphql.Response
+// @Failure 405 {object} graphql.Response
+// @Router /graphql [post]
+// echo '{"query": "{ building(id: 1) { id batteries { id columns { id elevators { id status } } } } }"}' | http POST "https://xinqi.dev:443/graphql" X-Api-User:user123
+func GraphQL(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	request, err := readGraphQLRequest(w, r)
+	if err != nil {
+		writeGraphQLError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	doc, err := graphql.Parse(request.Query)
+	if err != nil {
+		writeGraphQLError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	operation, err := doc.Operation(request.OperationName)
+	if err != nil {
+		writeGraphQLError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case operation.Type == "subscription":
+		writeGraphQLError(ctx, w, http.StatusBadRequest, errors.New("subscriptions are not supported, changes are streamed by /events"))
+		return
+	case operation.Type == "mutation" && r.Method != http.MethodPost:
+		w.Header().Set("Allow", http.MethodPost)
+		writeGraphQLError(ctx, w, http.StatusMethodNotAllowed, errors.New("mutations must be sent with POST"))
+		return
+	}
+
+	vars, err := graphql.CoerceVariables(operation, request.Variables)
+	if err != nil {
+		writeGraphQLError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err = graphql.CountFields(doc, operation.Selections, maxGraphQLFields); err != nil {
+		writeGraphQLError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	executor := &graphqlExecutor{ctx: ctx, r: r, schema: graphqlSchema(), doc: doc, vars: vars}
+	data := executor.execute(operation)
+	writeJSON(ctx, w, &graphql.Response{Data: data, Errors: executor.errors})
+}
+
+// GetGraphQLSchema returns the schema of /graphql
+// @Summary Get the graphql schema
+// @Tags GraphQL
+// @Description GetGraphQLSchema returns the schema of /graphql in the schema definition language, one type per table with its columns
+// @Description and the relations following its foreign keys. Only the tables the caller may read are included, with the queries and
+// @Description mutations of the actions the caller is allowed.
+// @Produce  plain
+// @Success 200 {string} string
+// @Failure 401 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Router /graphql/schema [get]
+// http "https://xinqi.dev:443/graphql/schema" X-Api-User:user123
+func GetGraphQLSchema(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	permissions, err := graphqlPermissions(ctx, r)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(graphqlSDL(permissions)))
+}
+
+// readGraphQLRequest read the request from the query string of a GET, or from a json or application/graphql body
+func readGraphQLRequest(w http.ResponseWriter, r *http.Request) (*graphql.Request, error) {
+	request := &graphql.Request{}
+	if r.Method == http.MethodGet {
+		request.Query = r.FormValue("query")
+		request.OperationName = r.FormValue("operationName")
+		if variables := r.FormValue("variables"); variables != "" {
+			if err := decodeJSONNumbers([]byte(variables), &request.Variables); err != nil {
+				return nil, errors.New("variables must be a json object")
+			}
+		}
+	} else {
+		buf, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize))
+		if err != nil {
+			return nil, errors.New("request body is too large")
+		}
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			request.Query = string(buf)
+		} else if err := decodeJSONNumbers(buf, request); err != nil {
+			return nil, errors.New("request body must be a json object with a query")
+		}
+	}
+
+	if strings.TrimSpace(request.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	return request, nil
+}
+
+// decodeJSONNumbers unmarshal data keeping numbers as json.Number so integers are not rounded through float64
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func writeGraphQLError(ctx context.Context, w http.ResponseWriter, status int, err error) {
+	gqlErr, ok := err.(*graphql.Error)
+	if !ok {
+		gqlErr = &graphql.Error{Message: err.Error()}
+	}
+
+	data, _ := json.Marshal(&graphql.Response{Errors: []*graphql.Error{gqlErr}})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// graphqlExecutor executes an operation, field errors are collected and the field set to null
+type graphqlExecutor struct {
+	ctx    context.Context
+	r      *http.Request
+	schema map[string]*graphqlType
+	doc    *graphql.Document
+	vars   map[string]interface{}
+	errors []*graphql.Error
+
+	// records number of records read so far, bounded by maxGraphQLRecords
+	records int
+}
+
+// graphqlRootField a query or mutation field of the root type
+type graphqlRootField struct {
+	t      *graphqlType
+	action model.Action
+	many   bool
+}
+
+func (e *graphqlExecutor) fail(field *graphql.Selection, path []interface{}, err error) {
+	gqlErr := &graphql.Error{Message: err.Error(), Path: path}
+	if located, ok := err.(*graphql.Error); ok {
+		gqlErr.Message, gqlErr.Locations = located.Message, located.Locations
+	}
+
+	if len(gqlErr.Locations) == 0 {
+		gqlErr.Locations = []graphql.Location{field.Loc}
+	}
+	e.errors = append(e.errors, gqlErr)
+}
+
+// read count n more records read by the operation
+// error - the operation read more than maxGraphQLRecords records
+func (e *graphqlExecutor) read(n int) error {
+	if e.records += n; e.records > maxGraphQLRecords {
+		return fmt.Errorf("operation reads more than %d records, select fewer relations or lower page_size and limit", maxGraphQLRecords)
+	}
+	return nil
+}
+
+func (e *graphqlExecutor) execute(operation *graphql.Operation) interface{} {
+	typeName := "Query"
+	if operation.Type == "mutation" {
+		typeName = "Mutation"
+	}
+
+	fields, err := graphql.CollectFields(e.doc, operation.Selections, e.vars, typeName)
+	if err != nil {
+		e.fail(&graphql.Selection{Loc: operation.Loc}, nil, err)
+		return nil
+	}
+
+	// mutations are executed one after the other in the order of the document
+	data := graphql.NewObject()
+	for _, field := range fields {
+		path := []interface{}{field.ResponseKey()}
+		if field.Name == "__typename" {
+			data.Set(field.ResponseKey(), typeName)
+			continue
+		}
+
+		value, err := e.resolveRoot(typeName, field, path)
+		if err != nil {
+			e.fail(field, path, err)
+			value = nil
+		}
+		data.Set(field.ResponseKey(), value)
+	}
+	return data
+}
+
+// rootField the table and action of a root field, ie battery is a RetrieveOne of batteries and delete_battery a Delete
+func (e *graphqlExecutor) rootField(typeName, name string) (*graphqlRootField, bool) {
+	for _, t := range e.schema {
+		if typeName == "Query" {
+			switch name {
+			case t.One:
+				return &graphqlRootField{t: t, action: model.RetrieveOne}, true
+			case t.Many:
+				return &graphqlRootField{t: t, action: model.RetrieveMany, many: true}, true
+			}
+			continue
+		}
+
+		switch name {
+		case "create_" + t.One:
+			return &graphqlRootField{t: t, action: model.Create}, true
+		case "update_" + t.One:
+			return &graphqlRootField{t: t, action: model.Update}, true
+		case "delete_" + t.One:
+			return &graphqlRootField{t: t, action: model.Delete}, true
+		}
+	}
+	return nil, false
+}
+
+func (e *graphqlExecutor) resolveRoot(typeName string, field *graphql.Selection, path []interface{}) (interface{}, error) {
+	root, ok := e.rootField(typeName, field.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s on type %s", field.Name, typeName)
+	}
+
+	var argNames []string
+	switch {
+	case root.many:
+		argNames = []string{"page", "page_size", "order", "filter"}
+	case root.action == model.Create:
+		argNames = []string{"input"}
+	case root.action == model.Update:
+		argNames = []string{"id", "input"}
+	default:
+		argNames = []string{"id"}
+	}
+
+	args, err := e.arguments(field, argNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateRequest(e.ctx, e.r, root.t.Table, root.action); err != nil {
+		return nil, err
+	}
+
+	if root.many {
+		return e.resolvePage(root.t, field, args, path)
+	}
+
+	var id string
+	if root.action != model.Create {
+		if id, err = graphqlID(args["id"]); err != nil {
+			return nil, err
+		}
+	}
+
+	var record model.Model
+	switch root.action {
+	case model.RetrieveOne:
+		record, err = root.t.Resolver.get(e.ctx, id)
+	case model.Create, model.Update:
+		if record, err = e.inputRecord(root.t, args["input"], root.action); err != nil {
+			return nil, err
+		}
+
+		if root.action == model.Create {
+			record, err = root.t.Resolver.add(e.ctx, record)
+		} else {
+			record, err = root.t.Resolver.update(e.ctx, id, record)
+		}
+	case model.Delete:
+		if len(field.Selections) > 0 {
+			return nil, fmt.Errorf("field %s returns an Int and takes no selection", field.Name)
+		}
+		return root.t.Resolver.delete(e.ctx, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := e.resolveRecords(root.t, []model.Model{record}, field, [][]interface{}{path}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return objects[0], nil
+}
+
+// resolvePage resolve a list query, the records of the page are read with dao.GetAllRecords
+func (e *graphqlExecutor) resolvePage(t *graphqlType, field *graphql.Selection, args map[string]interface{}, path []interface{}) (interface{}, error) {
+	page, err := graphqlInt(args, "page", 0)
+	if err != nil || page < 0 {
+		return nil, fmt.Errorf("page must be a positive Int")
+	}
+
+	pagesize, err := graphqlInt(args, "page_size", 20)
+	if err != nil || pagesize <= 0 || pagesize > maxGraphQLPageSize {
+		return nil, fmt.Errorf("page_size must be an Int between 1 and %d", maxGraphQLPageSize)
+	}
+
+	filter, err := e.recordFilter(t, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(field.Selections) == 0 {
+		return nil, fmt.Errorf("field %s of type %sPage must have a selection of subfields", field.Name, t.Name)
+	}
+
+	fields, err := graphql.CollectFields(e.doc, field.Selections, e.vars, t.Name+"Page")
+	if err != nil {
+		return nil, err
+	}
+
+	records, totalRows, err := dao.GetAllRecords(e.ctx, t.Table, filter, page, pagesize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = e.read(len(records)); err != nil {
+		return nil, err
+	}
+
+	result := graphql.NewObject()
+	for _, sub := range fields {
+		key := sub.ResponseKey()
+		switch sub.Name {
+		case "__typename":
+			result.Set(key, t.Name+"Page")
+		case "page":
+			result.Set(key, page)
+		case "page_size":
+			result.Set(key, pagesize)
+		case "total_records":
+			result.Set(key, totalRows)
+		case "data":
+			paths := make([][]interface{}, len(records))
+			for i := range records {
+				paths[i] = append(append([]interface{}{}, path...), key, i)
+			}
+
+			objects, err := e.resolveRecords(t, records, sub, paths, 1)
+			if err != nil {
+				e.fail(sub, append(append([]interface{}{}, path...), key), err)
+				result.Set(key, nil)
+				continue
+			}
+			result.Set(key, objects)
+		default:
+			e.fail(sub, append(append([]interface{}{}, path...), key), fmt.Errorf("unknown field %s on type %sPage", sub.Name, t.Name))
+			result.Set(key, nil)
+		}
+	}
+	return result, nil
+}
+
+// resolveRecords resolve the selection of field on every record, records are resolved together so each relation is loaded
+// with a single query whatever the number of records. paths holds the response path of each record.
+func (e *graphqlExecutor) resolveRecords(t *graphqlType, records []model.Model, field *graphql.Selection, paths [][]interface{}, depth int) ([]*graphql.Object, error) {
+	if len(field.Selections) == 0 {
+		return nil, fmt.Errorf("field %s of type %s must have a selection of subfields", field.Name, t.Name)
+	}
+
+	fields, err := graphql.CollectFields(e.doc, field.Selections, e.vars, t.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]*graphql.Object, len(records))
+	rows := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		objects[i] = graphql.NewObject()
+		data, err := json.Marshal(model.Redact(record))
+		if err != nil {
+			return nil, err
+		}
+
+		if err = decodeJSONNumbers(data, &rows[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sub := range fields {
+		key := sub.ResponseKey()
+		if sub.Name == "__typename" {
+			for _, object := range objects {
+				object.Set(key, t.Name)
+			}
+			continue
+		}
+
+		if col := t.column(sub.Name); col != nil {
+			if len(sub.Selections) > 0 || len(sub.Arguments) > 0 {
+				return nil, fmt.Errorf("field %s of type %s is a %s and takes no arguments or selection", sub.Name, t.Name, graphqlScalar(col))
+			}
+
+			for i, object := range objects {
+				object.Set(key, graphqlOutput(col, rows[i][col.JSONFieldName]))
+			}
+			continue
+		}
+
+		relation := t.relation(sub.Name)
+		if relation == nil {
+			return nil, fmt.Errorf("unknown field %s on type %s", sub.Name, t.Name)
+		}
+
+		if err := e.resolveRelation(t, relation, records, objects, sub, paths, depth); err != nil {
+			if len(records) > 0 {
+				e.fail(sub, append(append([]interface{}{}, paths[0]...), key), err)
+			}
+
+			for _, object := range objects {
+				object.Set(key, nil)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// resolveRelation load the records related to every record with one query and set field on objects
+func (e *graphqlExecutor) resolveRelation(t *graphqlType, relation *graphqlRelation, records []model.Model, objects []*graphql.Object,
+	field *graphql.Selection, paths [][]interface{}, depth int) error {
+	if depth >= maxGraphQLDepth {
+		return fmt.Errorf("relations may not be nested more than %d levels deep", maxGraphQLDepth)
+	}
+
+	argNames := []string{}
+	if relation.Many {
+		argNames = []string{"filter", "order", "limit"}
+	}
+
+	args, err := e.arguments(field, argNames...)
+	if err != nil {
+		return err
+	}
+
+	target := e.schema[relation.Target]
+	if err := ValidateRequest(e.ctx, e.r, target.Table, model.RetrieveMany); err != nil {
+		return err
+	}
+
+	key := field.ResponseKey()
+	if !relation.Many {
+		return e.resolveBelongsTo(t, target, relation, records, objects, field, paths, depth)
+	}
+
+	limit, err := graphqlInt(args, "limit", defaultGraphQLLimit)
+	if err != nil || limit <= 0 || limit > maxGraphQLPageSize {
+		return fmt.Errorf("limit must be an Int between 1 and %d", maxGraphQLPageSize)
+	}
+
+	filter, err := e.recordFilter(target, args)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		if id, ok := model.ColumnValue(record, t.primaryKey()); ok && id != nil {
+			ids = append(ids, id)
+		}
+	}
+
+	var (
+		grouped = make(map[string][]model.Model)
+		read    int64
+	)
+	fk := target.column(relation.Column)
+	group := func(child model.Model) error {
+		if err := e.read(1); err != nil {
+			return err
+		}
+
+		read++
+		parent, _ := model.ColumnValue(child, fk)
+		id := fmt.Sprint(parent)
+		if int64(len(grouped[id])) < limit {
+			grouped[id] = append(grouped[id], child)
+		}
+		return nil
+	}
+
+	// the children of every parent are read with one query of at most limit rows per parent, when the rows run out the
+	// parents left short by a parent with more children are read again one by one
+	if len(ids) > 0 {
+		filter.In = map[string][]interface{}{relation.Column: ids}
+		filter.Limit = int64(len(ids)) * limit
+		if err = dao.StreamRecords(e.ctx, target.Table, filter, group); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) > 1 && read == filter.Limit {
+		for _, id := range ids {
+			parent := fmt.Sprint(id)
+			if int64(len(grouped[parent])) == limit {
+				continue
+			}
+
+			grouped[parent] = nil
+			filter.In = map[string][]interface{}{relation.Column: {id}}
+			filter.Limit = limit
+			if err = dao.StreamRecords(e.ctx, target.Table, filter, group); err != nil {
+				return err
+			}
+		}
+	}
+
+	var (
+		children     []model.Model
+		childPaths   [][]interface{}
+		childIndexes = make([][]int, len(records))
+	)
+	for i, record := range records {
+		id, _ := model.ColumnValue(record, t.primaryKey())
+		for j, child := range grouped[fmt.Sprint(id)] {
+			childIndexes[i] = append(childIndexes[i], len(children))
+			children = append(children, child)
+			childPaths = append(childPaths, append(append([]interface{}{}, paths[i]...), key, j))
+		}
+	}
+
+	resolved, err := e.resolveRecords(target, children, field, childPaths, depth+1)
+	if err != nil {
+		return err
+	}
+
+	for i, object := range objects {
+		list := make([]*graphql.Object, 0, len(childIndexes[i]))
+		for _, index := range childIndexes[i] {
+			list = append(list, resolved[index])
+		}
+		object.Set(key, list)
+	}
+	return nil
+}
+
+// resolveBelongsTo load the records referenced by the foreign key of every record with one query
+func (e *graphqlExecutor) resolveBelongsTo(t, target *graphqlType, relation *graphqlRelation, records []model.Model, objects []*graphql.Object,
+	field *graphql.Selection, paths [][]interface{}, depth int) error {
+	key := field.ResponseKey()
+	fk := t.column(relation.Column)
+
+	var (
+		ids     []interface{}
+		parents = make(map[string]int)
+	)
+	for i, record := range records {
+		value, ok := model.ColumnValue(record, fk)
+		if !ok || value == nil {
+			continue
+		}
+
+		id := fmt.Sprint(value)
+		if _, ok := parents[id]; !ok {
+			parents[id] = i
+			ids = append(ids, value)
+		}
+	}
+
+	var (
+		loaded []model.Model
+		index  = make(map[string]int)
+	)
+	if len(ids) > 0 {
+		pk := target.primaryKey()
+		filter := &dao.RecordFilter{In: map[string][]interface{}{pk.JSONFieldName: ids}}
+		err := dao.StreamRecords(e.ctx, target.Table, filter, func(record model.Model) error {
+			if err := e.read(1); err != nil {
+				return err
+			}
+
+			id, _ := model.ColumnValue(record, pk)
+			index[fmt.Sprint(id)] = len(loaded)
+			loaded = append(loaded, record)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// a record referenced by several records is resolved once, errors are reported on the path of the first one
+	loadedPaths := make([][]interface{}, len(loaded))
+	for id, i := range index {
+		loadedPaths[i] = append(append([]interface{}{}, paths[parents[id]]...), key)
+	}
+
+	resolved, err := e.resolveRecords(target, loaded, field, loadedPaths, depth+1)
+	if err != nil {
+		return err
+	}
+
+	for i, object := range objects {
+		value, _ := model.ColumnValue(records[i], fk)
+		if j, ok := index[fmt.Sprint(value)]; ok && value != nil {
+			object.Set(key, resolved[j])
+		} else {
+			object.Set(key, nil)
+		}
+	}
+	return nil
+}
+
+// arguments the resolved arguments of field, arguments not in names are rejected
+func (e *graphqlExecutor) arguments(field *graphql.Selection, names ...string) (map[string]interface{}, error) {
+	for _, arg := range field.Arguments {
+		known := false
+		for _, name := range names {
+			known = known || arg.Name == name
+		}
+
+		if !known {
+			return nil, fmt.Errorf("unknown argument %s of field %s", arg.Name, field.Name)
+		}
+	}
+	return graphql.ArgumentValues(field.Arguments, e.vars)
+}
+
+// recordFilter the dao filter of the filter and order arguments of a list or has many field
+func (e *graphqlExecutor) recordFilter(t *graphqlType, args map[string]interface{}) (*dao.RecordFilter, error) {
+	filter := &dao.RecordFilter{Where: make(map[string]interface{})}
+	if order, ok := args["order"]; ok && order != nil {
+		if filter.Order, ok = order.(string); !ok {
+			return nil, fmt.Errorf("order must be a String")
+		}
+	}
+
+	conditions, ok := args["filter"]
+	if !ok || conditions == nil {
+		return filter, nil
+	}
+
+	fields, ok := conditions.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("filter must be a %sFilter object", t.Name)
+	}
+
+	for name, value := range fields {
+		col := t.column(name)
+		if col == nil {
+			return nil, fmt.Errorf("unknown field %s of %sFilter", name, t.Name)
+		}
+
+		coerced, err := graphqlInput(col, value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Where[col.JSONFieldName] = coerced
+	}
+	return filter, nil
+}
+
+// inputRecord the record of a create or update input object, prepared and validated as the rest handlers do
+func (e *graphqlExecutor) inputRecord(t *graphqlType, input interface{}, action model.Action) (model.Model, error) {
+	fields, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("input must be a %sInput object", t.Name)
+	}
+
+	values := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		col := t.column(name)
+		if col == nil || col.IsAutoIncrement {
+			return nil, fmt.Errorf("unknown field %s of %sInput", name, t.Name)
+		}
+
+		coerced, err := graphqlInput(col, value)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = coerced
+	}
+
+	buf, err := json.Marshal(values)
+	if err != nil {
+		return nil, dao.ErrBadParams
+	}
+
+	record := dao.Records[t.Table]()
+	if err := rejectSensitive(buf, record); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(buf, record); err != nil {
+		return nil, err
+	}
+	dao.Untrash(record)
+
+	if err := record.BeforeSave(); err != nil {
+		return nil, dao.ErrBadParams
+	}
+
+	record.Prepare()
+	if err := record.Validate(action); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// graphqlOutput the response value of a column read from the json encoding of a record, IDs are serialized as strings
+func graphqlOutput(col *model.ColumnInfo, value interface{}) interface{} {
+	if number, ok := value.(json.Number); ok && graphqlScalar(col) == "ID" {
+		return number.String()
+	}
+	return value
+}
+
+// graphqlInput coerce an argument value to the scalar of col and to the value stored in the db
+func graphqlInput(col *model.ColumnInfo, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	scalar := graphqlScalar(col)
+	invalid := fmt.Errorf("%s expects a %s, got %v", col.JSONFieldName, scalar, value)
+	switch scalar {
+	case "ID", "Int":
+		if id, ok := value.(string); ok && scalar == "ID" {
+			n, err := parseRecordID(id)
+			if err != nil {
+				return nil, invalid
+			}
+			return n, nil
+		}
+
+		n, ok := graphqlInteger(value)
+		if !ok {
+			return nil, invalid
+		}
+		return n, nil
+	case "Float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, invalid
+			}
+			return f, nil
+		}
+		return nil, invalid
+	case "Boolean":
+		if _, ok := value.(bool); !ok {
+			return nil, invalid
+		}
+		return value, nil
+	case "JSON":
+		return value, nil
+	default:
+		if _, ok := value.(string); !ok {
+			return nil, invalid
+		}
+		return value, nil
+	}
+}
+
+// graphqlInteger the int64 of a literal or json variable value
+func graphqlInteger(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// graphqlInt the Int argument name, v when it is not given
+func graphqlInt(args map[string]interface{}, name string, v int64) (int64, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return v, nil
+	}
+
+	n, ok := graphqlInteger(value)
+	if !ok {
+		return 0, dao.ErrBadParams
+	}
+	return n, nil
+}
+
+// graphqlID the string of an ID argument, IDs may be given as strings or integers
+func graphqlID(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("argument id of type ID! is required")
+	}
+
+	n, ok := graphqlInteger(value)
+	if !ok {
+		return "", fmt.Errorf("argument id must be an ID")
+	}
+	return fmt.Sprint(n), nil
+}
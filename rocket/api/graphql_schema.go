@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"rocket/model"
+)
+
+const (
+	// maxGraphQLPageSize largest page_size of a list query
+	maxGraphQLPageSize = 1000
+
+	// defaultGraphQLLimit records returned per parent by a has many relation when no limit is given
+	defaultGraphQLLimit = 100
+
+	// maxGraphQLDepth deepest nesting of relations in a query
+	maxGraphQLDepth = 8
+
+	// maxGraphQLFields most fields an operation selects, fragments expanded and aliases counted apart
+	maxGraphQLFields = 500
+
+	// maxGraphQLRecords most records an operation reads, list pages and relations included
+	maxGraphQLRecords = 10000
+)
+
+// graphqlType the object type of a table, its fields are the columns that are not sensitive followed by the relations
+type graphqlType struct {
+	Table string
+
+	// Name type name, ie Battery
+	Name string
+
+	// One query field reading a record by id, ie battery
+	One string
+
+	// Many query field reading a page of records, ie batteries
+	Many string
+
+	Columns   []*model.ColumnInfo
+	Relations []*graphqlRelation
+	Resolver  *crudResolver
+}
+
+// graphqlRelation a field following a foreign key, to the referenced record or, when Many is set, to the records referencing it
+type graphqlRelation struct {
+	Name   string
+	Many   bool
+	Target string
+
+	// Column json name of the foreign key column, a column of the type for belongs to relations and of Target for has many
+	Column string
+}
+
+var (
+	graphqlSchemaOnce sync.Once
+	graphqlTypes      map[string]*graphqlType
+)
+
+// graphqlSchema the types of the tables exposed by /graphql by table name, built from the table info and foreign keys
+func graphqlSchema() map[string]*graphqlType {
+	graphqlSchemaOnce.Do(func() {
+		graphqlTypes = make(map[string]*graphqlType)
+		for table, resolver := range crudResolvers {
+			info, ok := model.GetTableInfo(table)
+			if !ok {
+				continue
+			}
+
+			singular := model.SingularName(table)
+			t := &graphqlType{
+				Table:    table,
+				Name:     model.TypeName(table),
+				One:      singular,
+				Many:     table,
+				Columns:  info.Redacted().Columns,
+				Resolver: resolver,
+			}
+			if t.One == t.Many {
+				t.Many = table + "_list"
+			}
+			graphqlTypes[table] = t
+		}
+
+		for _, t := range graphqlTypes {
+			t.Relations = graphqlRelations(t)
+		}
+	})
+	return graphqlTypes
+}
+
+// graphqlRelations the relations of t to the other exposed tables, a belongs to relation is named after its column without _id and
+// a has many relation after the referencing table, names taken by a column or another relation are suffixed with the column
+func graphqlRelations(t *graphqlType) []*graphqlRelation {
+	var relations []*graphqlRelation
+	taken := make(map[string]bool)
+	for _, col := range t.Columns {
+		taken[col.JSONFieldName] = true
+	}
+
+	add := func(relation *graphqlRelation) {
+		if taken[relation.Name] {
+			relation.Name += "_by_" + relation.Column
+		}
+		taken[relation.Name] = true
+		relations = append(relations, relation)
+	}
+
+	for _, key := range model.ForeignKeysOf(t.Table) {
+		if _, ok := graphqlTypes[key.RefTable]; ok {
+			add(&graphqlRelation{Name: strings.TrimSuffix(key.Column, "_id"), Target: key.RefTable, Column: key.Column})
+		}
+	}
+
+	for _, key := range model.ReferencesTo(t.Table) {
+		if _, ok := graphqlTypes[key.Table]; ok {
+			add(&graphqlRelation{Name: key.Table, Many: true, Target: key.Table, Column: key.Column})
+		}
+	}
+	return relations
+}
+
+// graphqlScalar the scalar type of a column, integer primary keys are IDs and timestamps DateTime strings
+func graphqlScalar(col *model.ColumnInfo) string {
+	switch {
+	case col.GoFieldType == "json.RawMessage":
+		return "JSON"
+	case col.ProtobufType == "int32" || col.ProtobufType == "int64":
+		if col.IsPrimaryKey {
+			return "ID"
+		}
+		return "Int"
+	case col.ProtobufType == "float" || col.ProtobufType == "double":
+		return "Float"
+	case col.ProtobufType == "bool":
+		return "Boolean"
+	case col.ProtobufType == "google.protobuf.Timestamp":
+		return "DateTime"
+	default:
+		return "String"
+	}
+}
+
+// column the column of t with the json name name
+func (t *graphqlType) column(name string) *model.ColumnInfo {
+	for _, col := range t.Columns {
+		if col.JSONFieldName == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// relation the relation of t named name
+func (t *graphqlType) relation(name string) *graphqlRelation {
+	for _, relation := range t.Relations {
+		if relation.Name == name {
+			return relation
+		}
+	}
+	return nil
+}
+
+// primaryKey the primary key column of t, tables exposed by /graphql have a single column key
+func (t *graphqlType) primaryKey() *model.ColumnInfo {
+	for _, col := range t.Columns {
+		if col.IsPrimaryKey {
+			return col
+		}
+	}
+	return nil
+}
+
+// inputColumns columns accepted by the create and update mutations, auto increment keys are assigned by the db
+func (t *graphqlType) inputColumns() []*model.ColumnInfo {
+	var columns []*model.ColumnInfo
+	for _, col := range t.Columns {
+		if !col.IsAutoIncrement {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// graphqlPermissions the actions allowed on every table of the schema, tables the caller can not read are left out
+// error - the first error of ValidateRequest, the caller can not read any table
+func graphqlPermissions(ctx context.Context, r *http.Request) (map[string]map[model.Action]bool, error) {
+	var denied error
+	permissions := make(map[string]map[model.Action]bool)
+	for table := range graphqlSchema() {
+		allowed := make(map[model.Action]bool)
+		for _, action := range []model.Action{model.RetrieveOne, model.RetrieveMany, model.Create, model.Update, model.Delete} {
+			if err := ValidateRequest(ctx, r, table, action); err != nil {
+				if denied == nil {
+					denied = err
+				}
+				continue
+			}
+			allowed[action] = true
+		}
+
+		if allowed[model.RetrieveOne] || allowed[model.RetrieveMany] {
+			permissions[table] = allowed
+		}
+	}
+
+	if len(permissions) == 0 {
+		if denied == nil {
+			denied = ErrForbidden
+		}
+		return nil, denied
+	}
+	return permissions, nil
+}
+
+// graphqlSDL the schema served at /graphql/schema in the graphql schema definition language, limited to the tables and
+// actions of permissions
+func graphqlSDL(permissions map[string]map[model.Action]bool) string {
+	schema := graphqlSchema()
+	tables := make([]string, 0, len(permissions))
+	for table := range permissions {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var b strings.Builder
+	b.WriteString("\"\"\"An RFC 3339 date and time, ie 2020-04-22T10:46:58Z\"\"\"\nscalar DateTime\n\n")
+	b.WriteString("\"\"\"Any json value\"\"\"\nscalar JSON\n\n")
+
+	b.WriteString("type Query {\n")
+	for _, table := range tables {
+		t := schema[table]
+		if permissions[table][model.RetrieveOne] {
+			fmt.Fprintf(&b, "  %s(id: ID!): %s\n", t.One, t.Name)
+		}
+		if permissions[table][model.RetrieveMany] {
+			fmt.Fprintf(&b, "  %s(page: Int = 0, page_size: Int = 20, order: String, filter: %sFilter): %sPage!\n", t.Many, t.Name, t.Name)
+		}
+	}
+	b.WriteString("}\n")
+
+	var mutations strings.Builder
+	for _, table := range tables {
+		t := schema[table]
+		if permissions[table][model.Create] {
+			fmt.Fprintf(&mutations, "  create_%s(input: %sInput!): %s!\n", t.One, t.Name, t.Name)
+		}
+		if permissions[table][model.Update] {
+			fmt.Fprintf(&mutations, "  update_%s(id: ID!, input: %sInput!): %s!\n", t.One, t.Name, t.Name)
+		}
+		if permissions[table][model.Delete] {
+			fmt.Fprintf(&mutations, "  delete_%s(id: ID!): Int!\n", t.One)
+		}
+	}
+	if mutations.Len() > 0 {
+		fmt.Fprintf(&b, "\ntype Mutation {\n%s}\n", mutations.String())
+	}
+
+	for _, table := range tables {
+		t := schema[table]
+		fmt.Fprintf(&b, "\ntype %s {\n", t.Name)
+		for _, col := range t.Columns {
+			nonNull := ""
+			if !col.Nullable {
+				nonNull = "!"
+			}
+			fmt.Fprintf(&b, "  %s: %s%s\n", col.JSONFieldName, graphqlScalar(col), nonNull)
+		}
+
+		for _, relation := range t.Relations {
+			if _, ok := permissions[relation.Target]; !ok {
+				continue
+			}
+
+			target := schema[relation.Target]
+			if relation.Many {
+				fmt.Fprintf(&b, "  %s(filter: %sFilter, order: String, limit: Int = %d): [%s!]!\n", relation.Name, target.Name, defaultGraphQLLimit, target.Name)
+			} else {
+				fmt.Fprintf(&b, "  %s: %s\n", relation.Name, target.Name)
+			}
+		}
+		b.WriteString("}\n")
+
+		fmt.Fprintf(&b, "\ntype %sPage {\n  page: Int!\n  page_size: Int!\n  total_records: Int!\n  data: [%s!]!\n}\n", t.Name, t.Name)
+
+		fmt.Fprintf(&b, "\n\"\"\"Equality conditions on the columns of %s, null matches NULL\"\"\"\ninput %sFilter {\n", t.Table, t.Name)
+		for _, col := range t.Columns {
+			fmt.Fprintf(&b, "  %s: %s\n", col.JSONFieldName, graphqlScalar(col))
+		}
+		b.WriteString("}\n")
+
+		if permissions[table][model.Create] || permissions[table][model.Update] {
+			fmt.Fprintf(&b, "\ninput %sInput {\n", t.Name)
+			for _, col := range t.inputColumns() {
+				fmt.Fprintf(&b, "  %s: %s\n", col.JSONFieldName, graphqlScalar(col))
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,258 @@
+//go:generate go run ../app/protogen --out ../proto/rocket.proto
+
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/rpc"
+)
+
+// defaultGRPCPageSize records per page of a List call that sets no page_size, as the rest endpoints
+const defaultGRPCPageSize = 20
+
+// GRPCTables the tables served by the grpc services, the tables with crud endpoints
+func GRPCTables() []string {
+	tables := make([]string, 0, len(crudResolvers))
+	for table := range crudResolvers {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// GRPCServer the grpc services of the tables, calls are authenticated and authorized as the rest requests and read and write
+// records with the same dao functions. The services are defined by rpc.ProtoFile(GRPCTables()).
+func GRPCServer() *rpc.Server {
+	server := rpc.NewServer()
+	for _, table := range GRPCTables() {
+		info, ok := model.GetTableInfo(table)
+		if !ok {
+			continue
+		}
+
+		s := &grpcService{table: table, pk: rpc.PrimaryKey(info), resolver: crudResolvers[table]}
+		name := rpc.ServiceName(table)
+		server.Handle(name, "Get", rateLimited(s.get))
+		server.Handle(name, "List", rateLimited(s.list))
+		server.Handle(name, "StreamList", rateLimited(s.streamList))
+		server.Handle(name, "Create", rateLimited(s.create))
+		server.Handle(name, "Update", rateLimited(s.update))
+		server.Handle(name, "Delete", rateLimited(s.delete))
+	}
+	return server
+}
+
+// rateLimited count the calls of h against the rate limits of the rest api with the same client key, a method is limited as the
+// route "POST /rocket.v1.<Service>/<Method>"
+func rateLimited(h rpc.Handler) rpc.Handler {
+	return func(call *rpc.Call) error {
+		r, result, _ := allowRequest(call.Request, http.MethodPost+" "+call.Request.URL.Path)
+		call.Request = r
+		if result != nil && !result.Allowed {
+			return rpc.Errorf(rpc.ResourceExhausted, "rate limit exceeded, retry after %ss", seconds(result.RetryAfter))
+		}
+		return h(call)
+	}
+}
+
+// grpcService the methods of the service of a table
+type grpcService struct {
+	table    string
+	pk       *model.ColumnInfo
+	resolver *crudResolver
+}
+
+func (s *grpcService) get(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	request, err := rpc.UnmarshalKeyRequest(call.Message, s.pk)
+	if err != nil {
+		return rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.RetrieveOne); err != nil {
+		return grpcStatus(err)
+	}
+
+	record, err := s.resolver.get(ctx, request.Key)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	return s.send(call, record)
+}
+
+func (s *grpcService) list(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	request, err := s.listRequest(call)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.RetrieveMany); err != nil {
+		return grpcStatus(err)
+	}
+
+	records, totalRows, err := dao.GetAllRecords(ctx, s.table, &dao.RecordFilter{Order: request.Order}, request.Page, request.PageSize)
+	if err != nil {
+		return grpcStatus(err)
+	}
+
+	messages := make([][]byte, len(records))
+	for i, record := range records {
+		if messages[i], err = rpc.MarshalRecord(record); err != nil {
+			return rpc.Errorf(rpc.Internal, "%v", err)
+		}
+	}
+	return call.Send(rpc.MarshalListResponse(request.Page, request.PageSize, totalRows, messages))
+}
+
+func (s *grpcService) streamList(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	request, err := s.listRequest(call)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.RetrieveMany); err != nil {
+		return grpcStatus(err)
+	}
+
+	err = dao.StreamRecords(ctx, s.table, &dao.RecordFilter{Order: request.Order}, func(record model.Model) error {
+		return s.send(call, record)
+	})
+	if err != nil {
+		return grpcStatus(err)
+	}
+	return nil
+}
+
+func (s *grpcService) create(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	record, err := s.inputRecord(call.Message, model.Create)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.Create); err != nil {
+		return grpcStatus(err)
+	}
+
+	record, err = s.resolver.add(ctx, record)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	return s.send(call, record)
+}
+
+func (s *grpcService) update(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	request, err := rpc.UnmarshalKeyRequest(call.Message, s.pk)
+	if err != nil {
+		return rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+
+	record, err := s.inputRecord(request.Record, model.Update)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.Update); err != nil {
+		return grpcStatus(err)
+	}
+
+	record, err = s.resolver.update(ctx, request.Key, record)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	return s.send(call, record)
+}
+
+func (s *grpcService) delete(call *rpc.Call) error {
+	ctx := initializeContext(call.Request)
+	request, err := rpc.UnmarshalKeyRequest(call.Message, s.pk)
+	if err != nil {
+		return rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+
+	if err := ValidateRequest(ctx, call.Request, s.table, model.Delete); err != nil {
+		return grpcStatus(err)
+	}
+
+	rowsAffected, err := s.resolver.delete(ctx, request.Key)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	return call.Send(rpc.MarshalDeleteResponse(rowsAffected))
+}
+
+// listRequest the request of a List or StreamList call, page_size defaults to 20
+func (s *grpcService) listRequest(call *rpc.Call) (*rpc.ListRequest, error) {
+	request, err := rpc.UnmarshalListRequest(call.Message)
+	if err != nil {
+		return nil, rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+
+	if request.Page < 0 || request.PageSize < 0 {
+		return nil, rpc.Errorf(rpc.InvalidArgument, "page and page_size must not be negative")
+	}
+
+	if request.PageSize == 0 {
+		request.PageSize = defaultGRPCPageSize
+	}
+	return request, nil
+}
+
+// inputRecord the record of a Create or Update request, prepared and validated as the rest handlers do
+func (s *grpcService) inputRecord(message []byte, action model.Action) (model.Model, error) {
+	record := dao.Records[s.table]()
+	if err := rpc.UnmarshalRecord(message, record); err != nil {
+		return nil, rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+	dao.Untrash(record)
+
+	if err := record.BeforeSave(); err != nil {
+		return nil, grpcStatus(dao.ErrBadParams)
+	}
+
+	record.Prepare()
+	if err := record.Validate(action); err != nil {
+		return nil, rpc.Errorf(rpc.InvalidArgument, "%v", err)
+	}
+	return record, nil
+}
+
+// send write the message of record
+func (s *grpcService) send(call *rpc.Call, record model.Model) error {
+	message, err := rpc.MarshalRecord(record)
+	if err != nil {
+		return rpc.Errorf(rpc.Internal, "%v", err)
+	}
+	return call.Send(message)
+}
+
+// grpcStatus the status of a dao or authorization error, as returnError maps them to http statuses
+func grpcStatus(err error) error {
+	if _, ok := err.(*rpc.Status); ok {
+		return err
+	}
+
+	code := rpc.Unknown
+	switch err {
+	case dao.ErrNotFound:
+		code = rpc.NotFound
+	case dao.ErrBadParams, dao.ErrUnableToMarshalJSON:
+		code = rpc.InvalidArgument
+	case dao.ErrInsertFailed, dao.ErrUpdateFailed, dao.ErrDeleteFailed:
+		code = rpc.Internal
+	case ErrUnauthorized, ErrInvalidToken, ErrInvalidCredentials, ErrInvalidAPIKey:
+		code = rpc.Unauthenticated
+	case ErrForbidden:
+		code = rpc.PermissionDenied
+	case ErrRateLimited:
+		code = rpc.ResourceExhausted
+	}
+	return &rpc.Status{Code: code, Message: err.Error()}
+}
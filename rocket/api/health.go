@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"rocket/dao"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+// HealthCheckFunc check of something the server needs to serve requests, a non nil error makes /readyz fail
+type HealthCheckFunc func(ctx context.Context) error
+
+// ReadinessConfig the checks run by /readyz
+type ReadinessConfig struct {
+	// Timeout of a probe, checks still running when it expires fail
+	Timeout time.Duration
+
+	// Checks by name run besides the database ping, ie "migrations"
+	Checks map[string]HealthCheckFunc
+}
+
+// HealthStatus response of /healthz and /readyz, Checks holds ok or the error of every check
+type HealthStatus struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// VersionInfo build of the running server as filled in by the -X compile flags
+type VersionInfo struct {
+	BuildDate      string `json:"build_date"`
+	Commit         string `json:"commit"`
+	BuildNumber    string `json:"build_number"`
+	RuntimeVersion string `json:"runtime_version"`
+	BuiltOnOS      string `json:"built_on_os"`
+}
+
+const (
+	healthOK          = "ok"
+	healthUnavailable = "unavailable"
+)
+
+var readiness = &ReadinessConfig{Timeout: 2 * time.Second}
+
+// ConfigureReadiness install the timeout and checks of /readyz
+func ConfigureReadiness(config *ReadinessConfig) {
+	readiness = config
+}
+
+func configHealthRouter(router *httprouter.Router) {
+	router.GET("/healthz", GetHealthz)
+	router.GET("/readyz", GetReadyz)
+	router.GET("/version", GetVersion)
+}
+
+func configGinHealthRouter(router gin.IRoutes) {
+	router.GET("/healthz", ConverHttprouterToGin(GetHealthz))
+	router.GET("/readyz", ConverHttprouterToGin(GetReadyz))
+	router.GET("/version", ConverHttprouterToGin(GetVersion))
+}
+
+// GetHealthz liveness probe, the process is up and serving requests
+// @Summary Liveness probe
+// @Tags Health
+// @Description GetHealthz answers 200 as long as the process serves requests, it checks nothing else
+// @Produce  json
+// @Success 200 {object} api.HealthStatus
+// @Router /healthz [get]
+// http "https://xinqi.dev:443/healthz"
+func GetHealthz(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeHealth(w, http.StatusOK, &HealthStatus{Status: healthOK})
+}
+
+// GetReadyz readiness probe, the database answers and every configured check passes
+// @Summary Readiness probe
+// @Tags Health
+// @Description GetReadyz pings the database and runs the configured checks, ie that migrations are applied, within the readiness timeout.
+// @Description It answers 503 with the error of every failed check when one fails.
+// @Produce  json
+// @Success 200 {object} api.HealthStatus
+// @Failure 503 {object} api.HealthStatus
+// @Router /readyz [get]
+// http "https://xinqi.dev:443/readyz"
+func GetReadyz(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	config := readiness
+	checks := map[string]HealthCheckFunc{"database": dao.Ping}
+	for name, check := range config.Checks {
+		checks[name] = check
+	}
+
+	ctx, cancel := r.Context(), func() {}
+	if config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+	}
+	defer cancel()
+
+	status := &HealthStatus{Status: healthOK, Checks: runHealthChecks(ctx, checks)}
+	code := http.StatusOK
+	for _, result := range status.Checks {
+		if result != healthOK {
+			status.Status, code = healthUnavailable, http.StatusServiceUnavailable
+		}
+	}
+	writeHealth(w, code, status)
+}
+
+// GetVersion returns the build of the running server
+// @Summary Get the build info
+// @Tags Health
+// @Description GetVersion returns the build date, commit, build number and runtime version the server was compiled with
+// @Produce  json
+// @Success 200 {object} api.VersionInfo
+// @Router /version [get]
+// http "https://xinqi.dev:443/version"
+func GetVersion(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	info := dao.AppBuildInfo
+	if info == nil {
+		info = &dao.BuildInfo{}
+	}
+
+	version := &VersionInfo{
+		BuildDate:      info.BuildDate,
+		Commit:         info.LatestCommit,
+		BuildNumber:    info.BuildNumber,
+		RuntimeVersion: info.RuntimeVer,
+		BuiltOnOS:      info.BuiltOnOs,
+	}
+	if version.RuntimeVersion == "" {
+		version.RuntimeVersion = runtime.Version()
+	}
+	writeHealth(w, http.StatusOK, version)
+}
+
+// runHealthChecks run checks concurrently, the checks that did not return once ctx is done are reported as timed out
+func runHealthChecks(ctx context.Context, checks map[string]HealthCheckFunc) map[string]string {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(checks))
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheckFunc) {
+			defer wg.Done()
+			result := healthOK
+			if err := check(ctx); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	reported := make(map[string]string, len(checks))
+	for name := range checks {
+		if result, ok := results[name]; ok {
+			reported[name] = result
+		} else {
+			reported[name] = "timed out"
+		}
+	}
+	return reported
+}
+
+func writeHealth(w http.ResponseWriter, code int, v interface{}) {
+	data, _ := json.Marshal(v)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	w.Write(data)
+}
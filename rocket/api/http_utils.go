@@ -141,6 +141,86 @@ func GetIPAddress(r *http.Request) string {
 	return ip
 }
 
+// trustedProxies networks of the reverse proxies whose forwarding headers ClientIP honors, see ConfigureTrustedProxies
+var trustedProxies []*net.IPNet
+
+// ConfigureTrustedProxies honor X-Forwarded-For, X-Real-Ip, X-Forwarded-Host and X-Forwarded-Proto on requests from proxies,
+// each an ip address or a cidr
+// error - a proxy is neither an ip address nor a cidr
+func ConfigureTrustedProxies(proxies []string) error {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		cidr := proxy
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("proxy %q is not an ip address or cidr", proxy)
+		}
+		networks = append(networks, network)
+	}
+
+	trustedProxies = networks
+	return nil
+}
+
+// peerIP the ip address of the peer connected to the server, the client or a proxy in front of it
+func peerIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// trustedProxy reports if ip is the address of a trusted proxy
+func trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	for _, network := range trustedProxies {
+		if parsed != nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP the ip address of the client of r, unlike GetIPAddress the forwarding headers are only honored on requests from a
+// trusted proxy so clients cannot pick their address. The client is the rightmost X-Forwarded-For address that is not a
+// trusted proxy itself, or X-Real-Ip when the proxy does not set X-Forwarded-For.
+func ClientIP(r *http.Request) string {
+	ip := peerIP(r)
+	if !trustedProxy(ip) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addresses := strings.Split(forwarded, ",")
+		for i := len(addresses) - 1; i >= 0; i-- {
+			address := strings.TrimSpace(addresses[i])
+			if net.ParseIP(address) == nil {
+				break
+			}
+
+			ip = address
+			if !trustedProxy(address) {
+				break
+			}
+		}
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return ip
+}
+
 // FormatRequest generates ascii representation of a request
 func FormatRequest(r *http.Request) string {
 	// Create return string
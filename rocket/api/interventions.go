@@ -18,7 +18,7 @@ var (
 func configInterventionsRouter(router *httprouter.Router) {
 	router.GET("/interventions", GetAllInterventions)
 	router.POST("/interventions", AddInterventions)
-	router.GET("/interventions/:argID", GetInterventions)
+	router.GET("/interventions/:argID", withTrash("interventions", GetInterventions))
 	router.PUT("/interventions/:argID", UpdateInterventions)
 	router.DELETE("/interventions/:argID", DeleteInterventions)
 }
@@ -26,7 +26,7 @@ func configInterventionsRouter(router *httprouter.Router) {
 func configGinInterventionsRouter(router gin.IRoutes) {
 	router.GET("/interventions", ConverHttprouterToGin(GetAllInterventions))
 	router.POST("/interventions", ConverHttprouterToGin(AddInterventions))
-	router.GET("/interventions/:argID", ConverHttprouterToGin(GetInterventions))
+	router.GET("/interventions/:argID", ConverHttprouterToGin(withTrash("interventions", GetInterventions)))
 	router.PUT("/interventions/:argID", ConverHttprouterToGin(UpdateInterventions))
 	router.DELETE("/interventions/:argID", ConverHttprouterToGin(DeleteInterventions))
 }
@@ -18,7 +18,7 @@ var (
 func configLeadsRouter(router *httprouter.Router) {
 	router.GET("/leads", GetAllLeads)
 	router.POST("/leads", AddLeads)
-	router.GET("/leads/:argID", GetLeads)
+	router.GET("/leads/:argID", withTrash("leads", GetLeads))
 	router.PUT("/leads/:argID", UpdateLeads)
 	router.DELETE("/leads/:argID", DeleteLeads)
 }
@@ -26,7 +26,7 @@ func configLeadsRouter(router *httprouter.Router) {
 func configGinLeadsRouter(router gin.IRoutes) {
 	router.GET("/leads", ConverHttprouterToGin(GetAllLeads))
 	router.POST("/leads", ConverHttprouterToGin(AddLeads))
-	router.GET("/leads/:argID", ConverHttprouterToGin(GetLeads))
+	router.GET("/leads/:argID", ConverHttprouterToGin(withTrash("leads", GetLeads)))
 	router.PUT("/leads/:argID", ConverHttprouterToGin(UpdateLeads))
 	router.DELETE("/leads/:argID", ConverHttprouterToGin(DeleteLeads))
 }
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rocket/metrics"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("rocket_http_requests_total", "Requests served by method, route, status code and the table and action they were validated for.",
+		"method", "route", "status", "table", "action")
+	httpDuration = metrics.NewHistogramVec("rocket_http_request_duration_seconds", "Time to serve requests by method, route, table and action.",
+		metrics.DefaultBuckets, "method", "route", "table", "action")
+)
+
+func configMetricsRouter(router *httprouter.Router) {
+	router.GET("/metrics", GetMetrics)
+}
+
+func configGinMetricsRouter(router gin.IRoutes) {
+	router.GET("/metrics", ConverHttprouterToGin(GetMetrics))
+}
+
+// GetMetrics returns the metrics of the server
+// @Summary Get the server metrics
+// @Tags Metrics
+// @Description GetMetrics returns the request, database and connection pool metrics in the prometheus text format. Callers need to
+// @Description be allowed to read the metrics pseudo table, scrapers without credentials use the --metrics-addr listener instead.
+// @Produce  plain
+// @Success 200 {string} string
+// @Failure 401 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Router /metrics [get]
+// http "https://xinqi.dev:443/metrics" X-Api-User:user123
+func GetMetrics(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if err := ValidateRequest(ctx, r, "metrics", model.RetrieveMany); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	metrics.Default.Handler().ServeHTTP(w, r)
+}
+
+// observeRequest record the table and action of a ValidateRequest call on the info of the request of ctx, the first call sets
+// them and requests touching several tables, ie /graphql, report the table as multiple
+func observeRequest(ctx context.Context, table string, action model.Action) {
+	info := requestInfoFrom(ctx)
+	if info == nil {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	switch {
+	case info.table == "":
+		info.table, info.action = table, action.String()
+	case info.table != table:
+		info.table = "multiple"
+	}
+}
+
+// GinMetrics middleware counting and timing requests by route, status, table and action, it must be used before the routes
+// are added
+func GinMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		info := ginRequestInfo(c)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		info.mu.Lock()
+		table, action := info.table, info.action
+		info.mu.Unlock()
+
+		method := c.Request.Method
+		httpRequests.Inc(method, route, strconv.Itoa(c.Writer.Status()), table, action)
+		httpDuration.Observe(time.Since(start).Seconds(), method, route, table, action)
+	}
+}
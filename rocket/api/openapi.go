@@ -0,0 +1,395 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+var (
+	openapiMu        sync.RWMutex
+	openapiPublicURL string
+	openapiDoc       map[string]interface{}
+)
+
+func configOpenAPIRouter(router *httprouter.Router) {
+	router.GET("/openapi.json", GetOpenAPI)
+}
+
+func configGinOpenAPIRouter(router gin.IRoutes) {
+	router.GET("/openapi.json", ConverHttprouterToGin(GetOpenAPI))
+}
+
+// ConfigureOpenAPI generate the document served at /openapi.json, its server is publicURL or, when empty, the scheme and host
+// the document is requested with
+func ConfigureOpenAPI(publicURL string) {
+	doc := openapiDocument()
+
+	openapiMu.Lock()
+	defer openapiMu.Unlock()
+	openapiPublicURL = strings.TrimSuffix(publicURL, "/")
+	openapiDoc = doc
+}
+
+// GetOpenAPI returns the OpenAPI 3.1 description of the table endpoints
+// @Summary Get the OpenAPI 3.1 document
+// @Tags TableInfo
+// @Description GetOpenAPI returns an OpenAPI 3.1 document of the crud, csv and trash endpoints of every table, generated from the table info
+// @Description at startup. Its server is the configured public url, or the scheme and host of the request.
+// @Produce  json
+// @Success 200 {object} interface{}
+// @Router /openapi.json [get]
+// http "https://xinqi.dev:443/openapi.json"
+func GetOpenAPI(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	openapiMu.RLock()
+	doc, publicURL := openapiDoc, openapiPublicURL
+	openapiMu.RUnlock()
+
+	if doc == nil {
+		ConfigureOpenAPI(publicURL)
+		openapiMu.RLock()
+		doc = openapiDoc
+		openapiMu.RUnlock()
+	}
+
+	if publicURL == "" {
+		publicURL = requestBaseURL(r)
+	}
+
+	served := make(map[string]interface{}, len(doc)+1)
+	for name, value := range doc {
+		served[name] = value
+	}
+	served["servers"] = []*openapiServer{{URL: publicURL}}
+
+	data, _ := json.Marshal(served)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// requestBaseURL the scheme and host a client used to reach the server, as forwarded by a trusted proxy when it sets
+// X-Forwarded-Proto and X-Forwarded-Host, see ConfigureTrustedProxies
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if !trustedProxy(peerIP(r)) {
+		return scheme + "://" + r.Host
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host
+}
+
+type openapiServer struct {
+	URL string `json:"url"`
+}
+
+type openapiSchema struct {
+	Ref             string                    `json:"$ref,omitempty"`
+	Type            interface{}               `json:"type,omitempty"`
+	Format          string                    `json:"format,omitempty"`
+	ContentEncoding string                    `json:"contentEncoding,omitempty"`
+	Description     string                    `json:"description,omitempty"`
+	MaxLength       int64                     `json:"maxLength,omitempty"`
+	Enum            []interface{}             `json:"enum,omitempty"`
+	Default         interface{}               `json:"default,omitempty"`
+	ReadOnly        bool                      `json:"readOnly,omitempty"`
+	Items           *openapiSchema            `json:"items,omitempty"`
+	Properties      map[string]*openapiSchema `json:"properties,omitempty"`
+	Required        []string                  `json:"required,omitempty"`
+}
+
+type openapiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openapiSchema `json:"schema"`
+}
+
+type openapiMediaType struct {
+	Schema *openapiSchema `json:"schema"`
+}
+
+type openapiBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Content     map[string]*openapiMediaType `json:"content"`
+}
+
+type openapiResponse struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Content     map[string]*openapiMediaType `json:"content,omitempty"`
+}
+
+type openapiOperation struct {
+	Tags        []string                    `json:"tags"`
+	Summary     string                      `json:"summary"`
+	OperationID string                      `json:"operationId"`
+	Parameters  []*openapiParameter         `json:"parameters,omitempty"`
+	RequestBody *openapiBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*openapiResponse `json:"responses"`
+}
+
+// openapiDocument the document without its servers, they are added per request
+func openapiDocument() map[string]interface{} {
+	schemas := map[string]*openapiSchema{
+		"HTTPError": {
+			Type:       "object",
+			Properties: map[string]*openapiSchema{"code": {Type: "integer", Format: "int32"}, "message": {Type: "string"}},
+			Required:   []string{"code", "message"},
+		},
+		"ImportError": {
+			Type: "object",
+			Properties: map[string]*openapiSchema{
+				"row":     {Type: "integer", Description: "line of the csv file, the header is line 1"},
+				"column":  {Type: "string"},
+				"message": {Type: "string"},
+			},
+			Required: []string{"row", "message"},
+		},
+		"ImportResult": {
+			Type: "object",
+			Properties: map[string]*openapiSchema{
+				"dry_run":   {Type: "boolean"},
+				"committed": {Type: "boolean"},
+				"created":   {Type: "integer"},
+				"updated":   {Type: "integer"},
+				"failed":    {Type: "integer"},
+				"errors":    {Type: "array", Items: openapiRef("schemas", "ImportError")},
+			},
+			Required: []string{"dry_run", "committed", "created", "updated", "failed", "errors"},
+		},
+	}
+
+	errorContent := map[string]*openapiMediaType{"application/json": {Schema: openapiRef("schemas", "HTTPError")}}
+	responses := map[string]*openapiResponse{
+		"BadRequest":   {Description: "malformed parameters or body, or a record that fails validation", Content: errorContent},
+		"Unauthorized": {Description: "missing, invalid or expired access token", Content: errorContent},
+		"Forbidden":    {Description: "the access policy does not allow the caller to perform the action", Content: errorContent},
+	}
+
+	paths := make(map[string]map[string]*openapiOperation)
+	for _, crud := range crudEndpoints {
+		if crud.TableInfo == nil {
+			continue
+		}
+		addOpenAPITable(paths, schemas, crud)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "Sample CRUD api for rocket_development db",
+			"description": "Crud, csv and trash endpoints of the rocket_development tables, generated from their table info",
+			"version":     "1.0",
+			"license":     map[string]string{"name": "Apache 2.0", "identifier": "Apache-2.0"},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":   schemas,
+			"responses": responses,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]string{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []map[string][]string{{"bearerAuth": {}}},
+	}
+}
+
+// addOpenAPITable add the schemas of the records of a table and the operations of its endpoints
+func addOpenAPITable(paths map[string]map[string]*openapiOperation, schemas map[string]*openapiSchema, crud *CrudAPI) {
+	table := crud.Name
+	name := model.TypeName(table)
+	columns := crud.TableInfo.Redacted().Columns
+	tags := []string{name}
+
+	record := &openapiSchema{Type: "object", Properties: make(map[string]*openapiSchema)}
+	input := &openapiSchema{Type: "object", Properties: make(map[string]*openapiSchema)}
+	var key *model.ColumnInfo
+	for _, col := range columns {
+		schema := openapiColumnSchema(col)
+		record.Properties[col.JSONFieldName] = schema
+		record.Required = append(record.Required, col.JSONFieldName)
+		if col.IsPrimaryKey && key == nil {
+			key = col
+		}
+		if !col.IsAutoIncrement {
+			input.Properties[col.JSONFieldName] = schema
+		}
+	}
+	schemas[name] = record
+	schemas[name+"Input"] = input
+	schemas[name+"Page"] = &openapiSchema{
+		Type: "object",
+		Properties: map[string]*openapiSchema{
+			"page":          {Type: "integer"},
+			"page_size":     {Type: "integer"},
+			"total_records": {Type: "integer"},
+			"data":          {Type: "array", Items: openapiRef("schemas", name)},
+		},
+		Required: []string{"page", "page_size", "total_records", "data"},
+	}
+
+	jsonOf := func(schema *openapiSchema) map[string]*openapiMediaType {
+		return map[string]*openapiMediaType{"application/json": {Schema: schema}}
+	}
+	ok := func(description string, schema *openapiSchema) map[string]*openapiResponse {
+		return map[string]*openapiResponse{
+			"200": {Description: description, Content: jsonOf(schema)},
+			"400": {Ref: "#/components/responses/BadRequest"},
+			"401": {Ref: "#/components/responses/Unauthorized"},
+			"403": {Ref: "#/components/responses/Forbidden"},
+		}
+	}
+	body := &openapiBody{Required: true, Content: jsonOf(openapiRef("schemas", name+"Input"))}
+	paging := []*openapiParameter{
+		{Name: "page", In: "query", Description: "page requested, starting at 1, 0 reads the first page", Schema: &openapiSchema{Type: "integer", Default: 0}},
+		{Name: "pagesize", In: "query", Description: "number of records in a page", Schema: &openapiSchema{Type: "integer", Default: 20}},
+	}
+	order := &openapiParameter{Name: "order", In: "query", Description: "sort order, comma separated columns each optionally followed by asc or desc, ie id desc",
+		Schema: &openapiSchema{Type: "string"}}
+
+	collection := crud.RetrieveManyURL
+	paths[collection] = map[string]*openapiOperation{
+		"get": {Tags: tags, Summary: "List " + table, OperationID: "list" + name, Parameters: append(paging, order),
+			Responses: ok("a page of records", openapiRef("schemas", name+"Page"))},
+		"post": {Tags: tags, Summary: "Create a record of " + table, OperationID: "create" + name, RequestBody: body,
+			Responses: ok("the created record", openapiRef("schemas", name))},
+	}
+
+	if key != nil {
+		keyParam := &openapiParameter{Name: openapiKeyParam(key), In: "path", Required: true, Description: key.JSONFieldName + " of the record",
+			Schema: openapiColumnSchema(key)}
+		keyParam.Schema.ReadOnly = false
+		notFound := func(responses map[string]*openapiResponse) map[string]*openapiResponse {
+			responses["404"] = &openapiResponse{Description: "no record with this " + key.JSONFieldName,
+				Content: map[string]*openapiMediaType{"application/json": {Schema: openapiRef("schemas", "HTTPError")}}}
+			return responses
+		}
+
+		item := crud.RetrieveOneURL + "/{" + keyParam.Name + "}"
+		paths[item] = map[string]*openapiOperation{
+			"get": {Tags: tags, Summary: "Get a record of " + table, OperationID: "get" + name, Parameters: []*openapiParameter{keyParam},
+				Responses: notFound(ok("the record", openapiRef("schemas", name)))},
+			"put": {Tags: tags, Summary: "Update a record of " + table, OperationID: "update" + name, Parameters: []*openapiParameter{keyParam},
+				RequestBody: body, Responses: notFound(ok("the updated record", openapiRef("schemas", name)))},
+			"delete": {Tags: tags, Summary: "Delete a record of " + table, OperationID: "delete" + name, Parameters: []*openapiParameter{keyParam},
+				Responses: notFound(ok("rows affected", &openapiSchema{Type: "integer"}))},
+		}
+
+		if _, soft := dao.SoftDeleteTables[table]; soft {
+			paths[crud.RetrieveOneURL+"/trash"] = map[string]*openapiOperation{
+				"get": {Tags: tags, Summary: "List soft deleted records of " + table, OperationID: "listTrashed" + name, Parameters: paging,
+					Responses: ok("a page of deleted records, most recently deleted first", openapiRef("schemas", name+"Page"))},
+			}
+			paths[item+"/restore"] = map[string]*openapiOperation{
+				"post": {Tags: tags, Summary: "Restore a soft deleted record of " + table, OperationID: "restore" + name, Parameters: []*openapiParameter{keyParam},
+					Responses: notFound(ok("the restored record", openapiRef("schemas", name)))},
+			}
+		}
+	}
+
+	filters := []*openapiParameter{order}
+	for _, col := range columns {
+		filters = append(filters, &openapiParameter{Name: col.JSONFieldName, In: "query", Description: "only export records whose " + col.JSONFieldName + " equals this value",
+			Schema: openapiColumnSchema(col)})
+	}
+	csvContent := map[string]*openapiMediaType{"text/csv": {Schema: &openapiSchema{Type: "string"}}}
+	paths[crud.RetrieveManyURL+".csv"] = map[string]*openapiOperation{
+		"get": {Tags: tags, Summary: "Export " + table + " as csv", OperationID: "export" + name, Parameters: filters,
+			Responses: map[string]*openapiResponse{
+				"200": {Description: "the records, the header row holds the json field names", Content: csvContent},
+				"400": {Ref: "#/components/responses/BadRequest"},
+				"401": {Ref: "#/components/responses/Unauthorized"},
+				"403": {Ref: "#/components/responses/Forbidden"},
+			}},
+	}
+	paths[crud.CreateURL+"/import"] = map[string]*openapiOperation{
+		"post": {Tags: tags, Summary: "Import " + table + " from csv", OperationID: "import" + name,
+			Parameters:  []*openapiParameter{{Name: "dry_run", In: "query", Description: "validate the file without writing it", Schema: &openapiSchema{Type: "boolean"}}},
+			RequestBody: &openapiBody{Required: true, Description: "rows whose primary key matches a record update it, other rows are created", Content: csvContent},
+			Responses:   ok("the import report, nothing is written when a row failed", openapiRef("schemas", "ImportResult"))},
+	}
+}
+
+// openapiKeyParam the name of the path parameter of the primary key, as the record routes name it
+func openapiKeyParam(key *model.ColumnInfo) string {
+	if graphqlScalar(key) == "ID" {
+		return "argID"
+	}
+	return "arg" + key.GoFieldName
+}
+
+// openapiColumnSchema the json schema of the values of a column, nullable columns also accept null
+func openapiColumnSchema(col *model.ColumnInfo) *openapiSchema {
+	schema := &openapiSchema{Description: col.Comment, ReadOnly: col.IsAutoIncrement}
+	switch col.ProtobufType {
+	case "int32", "int64":
+		schema.Type, schema.Format = "integer", col.ProtobufType
+	case "float", "double":
+		schema.Type, schema.Format = "number", col.ProtobufType
+	case "bool":
+		schema.Type = "boolean"
+	case "bytes":
+		schema.Type, schema.ContentEncoding = "string", "base64"
+	case "google.protobuf.Timestamp":
+		schema.Type, schema.Format = "string", "date-time"
+	default:
+		schema.Type = "string"
+		if col.ColumnLength > 0 {
+			schema.MaxLength = col.ColumnLength
+		}
+		schema.Enum = columnEnum(col)
+	}
+
+	if col.GoFieldType == "json.RawMessage" {
+		schema.Type, schema.MaxLength = nil, 0
+		return schema
+	}
+
+	if col.Nullable {
+		schema.Type = []interface{}{schema.Type, "null"}
+		if schema.Enum != nil {
+			schema.Enum = append(schema.Enum, nil)
+		}
+	}
+	return schema
+}
+
+// columnEnum the values of a mysql enum column, nil for other columns
+func columnEnum(col *model.ColumnInfo) []interface{} {
+	definition := strings.TrimSpace(col.ColumnType)
+	if !strings.HasPrefix(strings.ToLower(definition), "enum(") || !strings.HasSuffix(definition, ")") {
+		return nil
+	}
+
+	var values []interface{}
+	for _, value := range strings.Split(definition[len("enum("):len(definition)-1], ",") {
+		values = append(values, strings.Trim(strings.TrimSpace(value), "'"))
+	}
+	return values
+}
+
+func openapiRef(kind, name string) *openapiSchema {
+	return &openapiSchema{Ref: "#/components/" + kind + "/" + name}
+}
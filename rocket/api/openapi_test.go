@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestBaseURL(t *testing.T) {
+	if err := ConfigureTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { trustedProxies = nil })
+
+	tests := []struct {
+		remoteAddr, proto, host, want string
+	}{
+		{"192.0.2.7:4000", "", "", "http://api.rocket.io"},
+		{"192.0.2.7:4000", "https", "evil.example.com", "http://api.rocket.io"},
+		{"10.0.0.1:4000", "https", "public.rocket.io", "https://public.rocket.io"},
+		{"10.0.0.1:4000", "", "public.rocket.io, api.rocket.io", "http://public.rocket.io"},
+		{"10.0.0.1:4000", "gopher", "", "http://api.rocket.io"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://api.rocket.io/openapi.json", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		if tt.host != "" {
+			r.Header.Set("X-Forwarded-Host", tt.host)
+		}
+
+		if got := requestBaseURL(r); got != tt.want {
+			t.Errorf("requestBaseURL(from %s, proto %q, host %q) = %s, want %s", tt.remoteAddr, tt.proto, tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"rocket/dao"
+	"rocket/logging"
+	"rocket/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// passwordCost bcrypt cost of new passwords, matches the devise default stretches
+	passwordCost = 11
+
+	// minPasswordLength and maxPasswordLength devise default password length bounds
+	minPasswordLength = 6
+	maxPasswordLength = 128
+)
+
+var (
+	// ErrInvalidResetToken error when a password reset token is unknown, already used or expired
+	ErrInvalidResetToken = errors.New("reset password token is invalid or has expired")
+
+	// ErrInvalidPassword error when a new password is too short, too long or does not match its confirmation
+	ErrInvalidPassword = fmt.Errorf("password must be %d to %d characters and match its confirmation", minPasswordLength, maxPasswordLength)
+)
+
+// PasswordResetRequest body posted to /auth/password/reset, Account selects the table ("admin" or "user"), empty tries admin_users then users
+type PasswordResetRequest struct {
+	Email   string `json:"email" example:"admin@example.com"`
+	Account string `json:"account" example:"admin"`
+}
+
+// PasswordResetConfirmation body posted to /auth/password/reset/confirm
+type PasswordResetConfirmation struct {
+	Token                string `json:"reset_password_token"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+// PasswordResetResponse result of a password reset request or confirmation
+type PasswordResetResponse struct {
+	Message string `json:"message"`
+}
+
+func configPasswordResetRouter(router *httprouter.Router) {
+	router.POST("/auth/password/reset", RequestPasswordReset)
+	router.POST("/auth/password/reset/confirm", ConfirmPasswordReset)
+}
+
+func configGinPasswordResetRouter(router gin.IRoutes) {
+	router.POST("/auth/password/reset", ConverHttprouterToGin(RequestPasswordReset))
+	router.POST("/auth/password/reset/confirm", ConverHttprouterToGin(ConfirmPasswordReset))
+}
+
+// RequestPasswordReset is a function to email a single use password reset link to an admin_users or users account
+// @Summary Request a password reset link
+// @Tags Auth
+// @Description RequestPasswordReset stores the digest of a new reset token in reset_password_token and mails the link, the response is the same whether or not the email exists
+// @Accept  json
+// @Produce  json
+// @Param PasswordResetRequest body api.PasswordResetRequest true "account email"
+// @Success 200 {object} api.PasswordResetResponse
+// @Failure 400 {object} api.HTTPError
+// @Router /auth/password/reset [post]
+// echo '{"email": "admin@example.com"}' | http POST "https://xinqi.dev:443/auth/password/reset"
+func RequestPasswordReset(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if Auth == nil || Auth.Mailer == nil {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	request := &PasswordResetRequest{}
+	if err := readJSON(r, request); err != nil || request.Email == "" {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if principal, _, err := findAccount(ctx, request.Account, request.Email); err == nil {
+		if err := sendPasswordReset(ctx, principal); err != nil {
+			// not reported to the caller, that would reveal which emails exist
+			logging.Error(ctx, "password reset failed", logging.Fields{"user_type": principal.Type, "user_id": principal.ID, "error": err})
+		}
+	}
+
+	writeJSON(ctx, w, &PasswordResetResponse{Message: "if the account exists, reset instructions have been sent"})
+}
+
+// ConfirmPasswordReset is a function to set a new password with a token issued by RequestPasswordReset
+// @Summary Set a new password with a reset token
+// @Tags Auth
+// @Description ConfirmPasswordReset verifies the reset token has not expired or been used, stores the bcrypt hash of the new password and clears the token, tokens issued before the change stop working
+// @Accept  json
+// @Produce  json
+// @Param PasswordResetConfirmation body api.PasswordResetConfirmation true "token and new password"
+// @Success 200 {object} api.PasswordResetResponse
+// @Failure 400 {object} api.HTTPError
+// @Router /auth/password/reset/confirm [post]
+// echo '{"reset_password_token": "abc", "password": "new password", "password_confirmation": "new password"}' | http POST "https://xinqi.dev:443/auth/password/reset/confirm"
+func ConfirmPasswordReset(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if Auth == nil {
+		returnError(ctx, w, r, ErrUnauthorized)
+		return
+	}
+
+	confirm := &PasswordResetConfirmation{}
+	if err := readJSON(r, confirm); err != nil || confirm.Token == "" {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if len(confirm.Password) < minPasswordLength || len(confirm.Password) > maxPasswordLength || confirm.Password != confirm.PasswordConfirmation {
+		returnError(ctx, w, r, ErrInvalidPassword)
+		return
+	}
+
+	digest := resetTokenDigest(confirm.Token)
+	principalType, id, sentAt, err := findResetToken(ctx, digest)
+	if err != nil || !sentAt.Valid || time.Since(sentAt.Time) > Auth.ResetTTL {
+		returnError(ctx, w, r, ErrInvalidResetToken)
+		return
+	}
+
+	encrypted, err := bcrypt.GenerateFromPassword([]byte(confirm.Password+Auth.Pepper), passwordCost)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	switch principalType {
+	case PrincipalAdminUser:
+		err = dao.ResetAdminUsersPassword(ctx, id, digest, string(encrypted))
+	default:
+		err = dao.ResetUsersPassword(ctx, id, digest, string(encrypted))
+	}
+
+	if err == dao.ErrNotFound {
+		// consumed by a concurrent confirmation
+		err = ErrInvalidResetToken
+	}
+
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, &PasswordResetResponse{Message: "your password has been changed, please log in"})
+}
+
+// sendPasswordReset issue a new reset token for principal and mail the reset link
+func sendPasswordReset(ctx context.Context, principal *Principal) error {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	now := time.Now()
+
+	var err error
+	switch principal.Type {
+	case PrincipalAdminUser:
+		err = dao.SetAdminUsersResetPasswordToken(ctx, principal.ID, resetTokenDigest(token), now)
+	default:
+		err = dao.SetUsersResetPasswordToken(ctx, principal.ID, resetTokenDigest(token), now)
+	}
+	if err != nil {
+		return err
+	}
+
+	return Auth.Mailer.Notify(ctx, &notify.Message{
+		To:      []string{principal.Email},
+		Subject: "Reset password instructions",
+		Body: fmt.Sprintf("Hello %s!\n\nSomeone has requested a link to change your password. You can do this through the link below.\n\n%s\n\n"+
+			"The link expires in %s. If you didn't request this, please ignore this email. Your password won't change until you access the link above and create a new one.\n",
+			principal.Email, resetLink(token), Auth.ResetTTL),
+	})
+}
+
+// findResetToken look up the account holding a reset token digest in admin_users then users
+func findResetToken(ctx context.Context, digest string) (principalType string, id int64, sentAt null.Time, err error) {
+	if record, err := dao.GetAdminUsersByResetPasswordToken(ctx, digest); err == nil {
+		return PrincipalAdminUser, record.ID, record.ResetPasswordSentAt, nil
+	}
+
+	record, err := dao.GetUsersByResetPasswordToken(ctx, digest)
+	if err != nil {
+		return "", 0, sentAt, err
+	}
+	return PrincipalUser, record.ID, record.ResetPasswordSentAt, nil
+}
+
+// resetTokenDigest the value stored in reset_password_token, only the digest is persisted so a database leak does not expose usable tokens
+func resetTokenDigest(token string) string {
+	mac := hmac.New(sha256.New, Auth.Secret)
+	mac.Write([]byte("reset_password_token:" + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// resetLink the url mailed to the account, the token is passed as the reset_password_token query parameter like devise does
+func resetLink(token string) string {
+	link, err := url.Parse(Auth.ResetURL)
+	if err != nil || Auth.ResetURL == "" {
+		return token
+	}
+
+	query := link.Query()
+	query.Set("reset_password_token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
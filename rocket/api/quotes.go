@@ -18,7 +18,7 @@ var (
 func configQuotesRouter(router *httprouter.Router) {
 	router.GET("/quotes", GetAllQuotes)
 	router.POST("/quotes", AddQuotes)
-	router.GET("/quotes/:argID", GetQuotes)
+	router.GET("/quotes/:argID", withTrash("quotes", GetQuotes))
 	router.PUT("/quotes/:argID", UpdateQuotes)
 	router.DELETE("/quotes/:argID", DeleteQuotes)
 }
@@ -26,7 +26,7 @@ func configQuotesRouter(router *httprouter.Router) {
 func configGinQuotesRouter(router gin.IRoutes) {
 	router.GET("/quotes", ConverHttprouterToGin(GetAllQuotes))
 	router.POST("/quotes", ConverHttprouterToGin(AddQuotes))
-	router.GET("/quotes/:argID", ConverHttprouterToGin(GetQuotes))
+	router.GET("/quotes/:argID", ConverHttprouterToGin(withTrash("quotes", GetQuotes)))
 	router.PUT("/quotes/:argID", ConverHttprouterToGin(UpdateQuotes))
 	router.DELETE("/quotes/:argID", ConverHttprouterToGin(DeleteQuotes))
 }
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocket/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrRateLimited error when a client made more requests than its rate limit allows
+var ErrRateLimited = errors.New("rate limit exceeded, retry later")
+
+// RateLimitConfig the limits applied by GinRateLimit and the grpc services
+type RateLimitConfig struct {
+	// Default limit of every route without an override, shared by those routes and the grpc methods
+	Default ratelimit.Limit
+
+	// Routes overrides by "METHOD /route" as the route is registered, ie "GET /leads", or by the path of a grpc method, ie
+	// "POST /rocket.v1.LeadService/List", each with its own buckets
+	Routes map[string]ratelimit.Limit
+}
+
+var (
+	rateLimits  *RateLimitConfig
+	rateLimiter = ratelimit.New()
+
+	// unlimitedRoutes routes that are never rate limited, probes share their address with the other traffic of the node and a
+	// refused liveness probe would restart a healthy server
+	unlimitedRoutes = map[string]bool{"GET /healthz": true, "GET /readyz": true, "GET /version": true}
+)
+
+// ConfigureRateLimit install the limits of GinRateLimit and the grpc services, nil disables rate limiting
+func ConfigureRateLimit(config *RateLimitConfig) {
+	rateLimits = config
+}
+
+// ParseRouteLimit parse a route override written as METHOD /route=requests/period, ie "GET /leads=60/1m"
+func ParseRouteLimit(s string) (route string, limit ratelimit.Limit, err error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return "", limit, fmt.Errorf("route rate limit %q is not METHOD /route=requests/period", s)
+	}
+
+	fields := strings.Fields(s[:i])
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+		return "", limit, fmt.Errorf("route rate limit %q is not METHOD /route=requests/period", s)
+	}
+
+	route = strings.ToUpper(fields[0]) + " " + fields[1]
+	if unlimitedRoutes[route] {
+		return "", limit, fmt.Errorf("route %s is never rate limited", route)
+	}
+
+	limit, err = ratelimit.ParseLimit(s[i+1:])
+	return route, limit, err
+}
+
+// rateLimitKey the client a request is counted against, the authenticated user or api key or else the ip address, forwarding
+// headers only count from trusted proxies so a client cannot get a fresh bucket by sending another X-Forwarded-For
+func rateLimitKey(ctx context.Context, r *http.Request) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return "user:" + principal.Type + ":" + strconv.FormatInt(principal.ID, 10)
+	}
+	return "ip:" + ClientIP(r)
+}
+
+// GinRateLimit middleware limiting requests per client with token buckets, the route override or the default limit of
+// ConfigureRateLimit applies. Limited responses carry RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers, requests over the limit are answered with 429 and Retry-After.
+func GinRateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, result, limit := allowRequest(c.Request, c.Request.Method+" "+c.FullPath())
+		c.Request = r
+		if result == nil {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", seconds(result.Reset))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Period)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", seconds(result.RetryAfter))
+			returnError(c.Request.Context(), c.Writer, c.Request, ErrRateLimited)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowRequest count r against the limit of route, written "METHOD /route", with the client key of rateLimitKey. The request
+// is returned authenticated so the handlers reuse the principal, result is nil when route is not limited.
+func allowRequest(r *http.Request, route string) (*http.Request, *ratelimit.Result, ratelimit.Limit) {
+	config := rateLimits
+	if config == nil || unlimitedRoutes[route] {
+		return r, nil, ratelimit.Limit{}
+	}
+
+	scope, limit := "*", config.Default
+	if override, ok := config.Routes[route]; ok {
+		scope, limit = route, override
+	}
+	if limit.Unlimited() {
+		return r, nil, limit
+	}
+
+	if ContextInitializer != nil {
+		r = r.WithContext(ContextInitializer(r))
+	}
+
+	result := rateLimiter.Allow(scope+"|"+rateLimitKey(r.Context(), r), limit)
+	return r, &result, limit
+}
+
+// seconds d in whole seconds rounded up, as the RateLimit and Retry-After headers expect
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
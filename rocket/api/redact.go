@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"rocket/dao"
+	"rocket/model"
+)
+
+var modelType = reflect.TypeOf((*model.Model)(nil)).Elem()
+
+// redact strip sensitive columns from records, paged results and slices of records before they are written to a client
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case model.Model:
+		return model.Redact(val)
+	case *PagedResults:
+		paged := *val
+		paged.Data = redact(val.Data)
+		return &paged
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.IsNil() || !rv.Type().Elem().Implements(modelType) {
+		return v
+	}
+
+	records := make([]interface{}, rv.Len())
+	for i := range records {
+		records[i] = model.Redact(rv.Index(i).Interface().(model.Model))
+	}
+	return records
+}
+
+// rejectSensitive refuse a json payload that sets a sensitive column of record. Keys are compared ignoring case as
+// json.Unmarshal matches them to the struct fields.
+func rejectSensitive(buf []byte, record model.Model) error {
+	sensitive := record.TableInfo().SensitiveColumns()
+	if len(sensitive) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		// malformed payloads are reported by the caller's unmarshal
+		return nil
+	}
+
+	for key := range fields {
+		for _, col := range sensitive {
+			if strings.EqualFold(key, col.JSONFieldName) {
+				return fmt.Errorf("column %s can not be written", col.JSONFieldName)
+			}
+		}
+	}
+	return nil
+}
+
+// ddlFor the crud api description a caller may see, sensitive columns are only described to admin users
+func ddlFor(ctx context.Context, crud *CrudAPI) *CrudAPI {
+	if principal := PrincipalFromContext(ctx); (principal != nil && principal.IsAdmin()) || crud.TableInfo == nil {
+		return crud
+	}
+
+	redacted := *crud
+	redacted.TableInfo = crud.TableInfo.Redacted()
+	return &redacted
+}
+
+// redactStatementResult strip the result columns named like a sensitive column of any table from the result of a raw sql
+// statement, statements may select any column so the sensitive column names of every table are dropped
+func redactStatementResult(result *dao.StatementResult) *dao.StatementResult {
+	sensitive := model.SensitiveColumnNames()
+
+	var keep []int
+	for i, name := range result.Columns {
+		if !sensitive[strings.ToLower(name)] {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(result.Columns) {
+		return result
+	}
+
+	redacted := &dao.StatementResult{Columns: make([]string, 0, len(keep)), Rows: make([][]interface{}, 0, len(result.Rows)), DurationMS: result.DurationMS}
+	for _, i := range keep {
+		redacted.Columns = append(redacted.Columns, result.Columns[i])
+	}
+	for _, row := range result.Rows {
+		values := make([]interface{}, 0, len(keep))
+		for _, i := range keep {
+			values = append(values, row[i])
+		}
+		redacted.Rows = append(redacted.Rows, values)
+	}
+	return redacted
+}
+
+// driftFor the schema drift a caller may see, drift of sensitive columns is only reported to admin users
+func driftFor(ctx context.Context, drift *dao.SchemaDrift) *dao.SchemaDrift {
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.IsAdmin() {
+		return drift
+	}
+
+	redacted := *drift
+	redacted.Tables = make([]*dao.TableDrift, 0, len(drift.Tables))
+	for _, table := range drift.Tables {
+		info, ok := model.GetTableInfo(table.Table)
+		if !ok || len(info.SensitiveColumns()) == 0 {
+			redacted.Tables = append(redacted.Tables, table)
+			continue
+		}
+
+		sensitive := make(map[string]bool)
+		for _, col := range info.SensitiveColumns() {
+			sensitive[col.Name] = true
+		}
+
+		shown := &dao.TableDrift{Table: table.Table, MissingTable: table.MissingTable, ExtraColumns: table.ExtraColumns}
+		for _, name := range table.MissingColumns {
+			if !sensitive[name] {
+				shown.MissingColumns = append(shown.MissingColumns, name)
+			}
+		}
+		for _, col := range table.TypeMismatches {
+			if !sensitive[col.Column] {
+				shown.TypeMismatches = append(shown.TypeMismatches, col)
+			}
+		}
+		for _, col := range table.NullableMismatches {
+			if !sensitive[col.Column] {
+				shown.NullableMismatches = append(shown.NullableMismatches, col)
+			}
+		}
+		for _, index := range table.MissingIndexes {
+			covers := false
+			for _, name := range index.Columns {
+				covers = covers || sensitive[name]
+			}
+			if !covers {
+				shown.MissingIndexes = append(shown.MissingIndexes, index)
+			}
+		}
+
+		if shown.MissingTable || len(shown.MissingColumns) > 0 || len(shown.ExtraColumns) > 0 || len(shown.TypeMismatches) > 0 ||
+			len(shown.NullableMismatches) > 0 || len(shown.MissingIndexes) > 0 {
+			redacted.Tables = append(redacted.Tables, shown)
+		}
+	}
+	return &redacted
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+
+	"rocket/model"
+)
+
+func TestRejectSensitive(t *testing.T) {
+	tests := []struct {
+		body   string
+		reject bool
+	}{
+		{`{"email":"jane@rocket.io"}`, false},
+		{`{"Email":"jane@rocket.io","created_at":"2021-03-04T10:00:00Z"}`, false},
+		{`{"encrypted_password":"x"}`, true},
+		{`{"ENCRYPTED_PASSWORD":"x"}`, true},
+		{`{"email":"jane@rocket.io","Reset_Password_Token":"x"}`, true},
+		{`{"reſet_password_token":"x"}`, true},
+		{`not json`, false},
+	}
+
+	for _, tt := range tests {
+		err := rejectSensitive([]byte(tt.body), &model.Users_{})
+		if (err != nil) != tt.reject {
+			t.Errorf("rejectSensitive(%s) = %v, want rejected %v", tt.body, err, tt.reject)
+		}
+	}
+
+	if err := rejectSensitive([]byte(`{"ENCRYPTED_PASSWORD":"x"}`), &model.Customers{}); err != nil {
+		t.Errorf("rejectSensitive of a table without sensitive columns = %v", err)
+	}
+}
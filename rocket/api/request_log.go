@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"rocket/dao"
+	"rocket/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader header carrying the request id, a valid id sent by the client is kept and every response carries it
+const RequestIDHeader = "X-Request-Id"
+
+type requestInfoKey struct{}
+
+// requestInfo what the handlers learned about a request, read by the request log and the metrics once it was served
+type requestInfo struct {
+	mu     sync.Mutex
+	table  string
+	action string
+	actor  *dao.Actor
+}
+
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+// withRequestInfo the info of the request of ctx, a new one is attached when it has none
+func withRequestInfo(ctx context.Context) (context.Context, *requestInfo) {
+	if info := requestInfoFrom(ctx); info != nil {
+		return ctx, info
+	}
+	info := &requestInfo{}
+	return context.WithValue(ctx, requestInfoKey{}, info), info
+}
+
+// ginRequestInfo the info of the request of c, shared by GinRequestLog and GinMetrics in whichever order they are used
+func ginRequestInfo(c *gin.Context) *requestInfo {
+	ctx, info := withRequestInfo(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+	return info
+}
+
+// observeActor record the caller the request of ctx was authenticated as
+func observeActor(ctx context.Context, actor *dao.Actor) {
+	if info := requestInfoFrom(ctx); info != nil {
+		info.mu.Lock()
+		info.actor = actor
+		info.mu.Unlock()
+	}
+}
+
+// requestID the id of r taken from RequestIDHeader when it is valid, a new one otherwise
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); logging.ValidRequestID(id) {
+		return id
+	}
+	return logging.NewRequestID()
+}
+
+// logRequest write the log line of a served request
+func logRequest(ctx context.Context, r *http.Request, route string, status, size int, elapsed time.Duration, info *requestInfo, extra logging.Fields) {
+	fields := logging.Fields{
+		"method":      r.Method,
+		"route":       route,
+		"path":        r.URL.Path,
+		"status":      status,
+		"bytes":       size,
+		"duration_ms": float64(elapsed.Microseconds()) / 1000,
+		"ip":          ClientIP(r),
+	}
+
+	info.mu.Lock()
+	if info.table != "" {
+		fields["table"], fields["action"] = info.table, info.action
+	}
+	if actor := info.actor; actor != nil && actor.Type != "" {
+		fields["user"], fields["user_type"], fields["user_id"] = actor.Email, actor.Type, actor.ID
+	}
+	info.mu.Unlock()
+
+	for name, value := range extra {
+		fields[name] = value
+	}
+
+	level := logging.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = logging.LevelError
+	}
+	logging.Default.Log(ctx, level, "request", fields)
+}
+
+// GinRequestLog middleware giving every request an id, echoed in RequestIDHeader and carried by its context, and logging the
+// request with its route, user, status and duration once it was served. It must be the first middleware so the others log
+// with the id.
+func GinRequestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := requestID(c.Request)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		info := ginRequestInfo(c)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		logRequest(c.Request.Context(), c.Request, route, c.Writer.Status(), c.Writer.Size(), time.Since(start), info, nil)
+	}
+}
+
+// LogRequests handler giving every request served by h an id and logging it like GinRequestLog, the route is the path and
+// the grpc status is added when h set one, ie for GRPCServer
+func LogRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := requestID(r)
+		w.Header().Set(RequestIDHeader, id)
+		ctx, info := withRequestInfo(logging.WithRequestID(r.Context(), id))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		h.ServeHTTP(sw, r.WithContext(ctx))
+
+		var extra logging.Fields
+		if status := w.Header().Get(http.TrailerPrefix + "Grpc-Status"); status != "" {
+			extra = logging.Fields{"grpc_status": status}
+		}
+		logRequest(ctx, r, r.URL.Path, sw.status, sw.size, time.Since(start), info, extra)
+	})
+}
+
+// statusWriter remembers the status and size of a response, it flushes like the writer it wraps so streaming keeps working
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	written bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status, w.written = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.written = true
+	n, err := w.ResponseWriter.Write(p)
+	w.size += n
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	w.written = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
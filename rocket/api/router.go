@@ -63,6 +63,7 @@ func ConfigRouter() http.Handler {
 	configBlazerChecksRouter(router)
 	configBlazerDashboardQueriesRouter(router)
 	configBlazerDashboardsRouter(router)
+	configBlazerDashboardsFullRouter(router)
 	configBlazerQueriesRouter(router)
 	configBuildingDetailsRouter(router)
 	configBuildingsRouter(router)
@@ -76,6 +77,20 @@ func ConfigRouter() http.Handler {
 	configQuotesRouter(router)
 	configSchemaMigrationsRouter(router)
 	configUsers_Router(router)
+	configAuthRouter(router)
+	configPasswordResetRouter(router)
+	configAuditRouter(router)
+	configCommentsRouter(router)
+	configTrashRouter(router)
+	configWebhooksRouter(router)
+	configAPIKeysRouter(router)
+	configEventsRouter(router)
+	configCSVRouter(router)
+	configSearchRouter(router)
+	configGraphQLRouter(router)
+	configOpenAPIRouter(router)
+	configMetricsRouter(router)
+	configHealthRouter(router)
 
 	router.GET("/ddl/:argID", GetDdl)
 	router.GET("/ddl", GetDdlEndpoints)
@@ -95,6 +110,7 @@ func ConfigGinRouter(router gin.IRoutes) {
 	configGinBlazerChecksRouter(router)
 	configGinBlazerDashboardQueriesRouter(router)
 	configGinBlazerDashboardsRouter(router)
+	configGinBlazerDashboardsFullRouter(router)
 	configGinBlazerQueriesRouter(router)
 	configGinBuildingDetailsRouter(router)
 	configGinBuildingsRouter(router)
@@ -108,6 +124,20 @@ func ConfigGinRouter(router gin.IRoutes) {
 	configGinQuotesRouter(router)
 	configGinSchemaMigrationsRouter(router)
 	configGinUsers_Router(router)
+	configGinAuthRouter(router)
+	configGinPasswordResetRouter(router)
+	configGinAuditRouter(router)
+	configGinCommentsRouter(router)
+	configGinTrashRouter(router)
+	configGinWebhooksRouter(router)
+	configGinAPIKeysRouter(router)
+	configGinEventsRouter(router)
+	configGinCSVRouter(router)
+	configGinSearchRouter(router)
+	configGinGraphQLRouter(router)
+	configGinOpenAPIRouter(router)
+	configGinMetricsRouter(router)
+	configGinHealthRouter(router)
 
 	router.GET("/ddl/:argID", ConverHttprouterToGin(GetDdl))
 	router.GET("/ddl", ConverHttprouterToGin(GetDdlEndpoints))
@@ -135,10 +165,15 @@ func initializeContext(r *http.Request) (ctx context.Context) {
 	} else {
 		ctx = r.Context()
 	}
-	return ctx
+	actor := requestActor(ctx, r)
+	observeActor(ctx, actor)
+	return dao.WithActor(ctx, actor)
 }
 
 func ValidateRequest(ctx context.Context, r *http.Request, table string, action model.Action) error {
+	if r != nil {
+		observeRequest(r.Context(), table, action)
+	}
 	if RequestValidator != nil {
 		return RequestValidator(ctx, r, table, action)
 	}
@@ -164,7 +199,7 @@ func readInt(r *http.Request, param string, v int64) (int64, error) {
 }
 
 func writeJSON(ctx context.Context, w http.ResponseWriter, v interface{}) {
-	data, _ := json.Marshal(v)
+	data, _ := json.Marshal(redact(v))
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Write(data)
@@ -183,7 +218,21 @@ func readJSON(r *http.Request, v interface{}) error {
 		return err
 	}
 
-	return json.Unmarshal(buf, v)
+	record, ok := v.(model.Model)
+	if ok {
+		if err := rejectSensitive(buf, record); err != nil {
+			return err
+		}
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return err
+	}
+
+	if ok {
+		dao.Untrash(record)
+	}
+	return nil
 }
 
 func returnError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
@@ -201,6 +250,15 @@ func returnError(ctx context.Context, w http.ResponseWriter, r *http.Request, er
 		status = http.StatusBadRequest
 	case dao.ErrBadParams:
 		status = http.StatusBadRequest
+	case ErrUnauthorized, ErrInvalidToken, ErrInvalidCredentials, ErrInvalidAPIKey:
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Bearer realm="rocket"`)
+	case ErrForbidden:
+		status = http.StatusForbidden
+	case ErrEventsDisabled:
+		status = http.StatusServiceUnavailable
+	case ErrRateLimited:
+		status = http.StatusTooManyRequests
 	default:
 		status = http.StatusBadRequest
 	}
@@ -316,6 +374,12 @@ func parseUUID(ps httprouter.Params, key string) (string, error) {
 // @Router /ddl/{argID} [get]
 // http "https://xinqi.dev:443/ddl/xyz" X-Api-User:user123
 func GetDdl(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	// httprouter cannot route /ddl/drift besides /ddl/:argID
+	if ps.ByName("argID") == "drift" {
+		GetDdlDrift(w, r, ps)
+		return
+	}
+
 	ctx := initializeContext(r)
 
 	argID := ps.ByName("argID")
@@ -331,7 +395,35 @@ func GetDdl(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 		return
 	}
 
-	writeJSON(ctx, w, record)
+	writeJSON(ctx, w, ddlFor(ctx, record))
+}
+
+// GetDdlDrift is a function to compare the live schema of the rocket_development database with the table info of every table
+// @Summary Compare the live schema of the rocket_development database with the table info
+// @Tags TableInfo
+// @Description GetDdlDrift reads the live schema, INFORMATION_SCHEMA on mysql and pragmas on sqlite, and reports the tables with
+// @Description missing or extra columns, type or nullability mismatches and missing indexes compared to their table info
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dao.SchemaDrift
+// @Failure 400 {object} api.HTTPError
+// @Router /ddl/drift [get]
+// http "https://xinqi.dev:443/ddl/drift" X-Api-User:user123
+func GetDdlDrift(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	if err := ValidateRequest(ctx, r, "ddl", model.FetchDDL); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	drift, err := dao.DetectSchemaDrift(ctx, model.TableNames())
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, driftFor(ctx, drift))
 }
 
 // GetDdlEndpoints is a function to get a list of ddl endpoints available for tables in the rocket_development database
@@ -351,7 +443,12 @@ func GetDdlEndpoints(w http.ResponseWriter, r *http.Request, ps httprouter.Param
 		return
 	}
 
-	writeJSON(ctx, w, crudEndpoints)
+	endpoints := make(map[string]*CrudAPI, len(crudEndpoints))
+	for name, crud := range crudEndpoints {
+		endpoints[name] = ddlFor(ctx, crud)
+	}
+
+	writeJSON(ctx, w, endpoints)
 }
 
 func init() {
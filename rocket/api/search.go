@@ -0,0 +1,229 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// maxSearchTerms words of a query that are searched, the rest is ignored
+	maxSearchTerms = 8
+
+	// maxSearchLimit largest number of hits returned by /search
+	maxSearchLimit = 100
+)
+
+// searchTarget a table searched by /search, columns are named by json field name as several tables keep rails camel case column names
+type searchTarget struct {
+	Table    string
+	Title    string
+	Subtitle string
+	Fields   []dao.SearchField
+}
+
+// searchTargets tables and columns searched by /search, names of people and companies weigh more than emails and projects
+var searchTargets = []*searchTarget{
+	{
+		Table:    "customers",
+		Title:    "company_name",
+		Subtitle: "full_name_of_company_contact",
+		Fields: []dao.SearchField{
+			{Column: "company_name", Weight: 3},
+			{Column: "full_name_of_company_contact", Weight: 3},
+		},
+	},
+	{
+		Table:    "leads",
+		Title:    "bussiness_name",
+		Subtitle: "full_name_of_the_contact",
+		Fields: []dao.SearchField{
+			{Column: "bussiness_name", Weight: 3},
+			{Column: "full_name_of_the_contact", Weight: 3},
+		},
+	},
+	{
+		Table:    "buildings",
+		Title:    "full_name_of_building_admin",
+		Subtitle: "email_of_admin_of_building",
+		Fields: []dao.SearchField{
+			{Column: "full_name_of_building_admin", Weight: 2},
+			{Column: "email_of_admin_of_building", Weight: 1},
+			{Column: "full_name_of_tech_contact_for_building", Weight: 2},
+			{Column: "tech_contact_email_for_building", Weight: 1},
+		},
+	},
+	{
+		Table:    "quotes",
+		Title:    "company_name",
+		Subtitle: "project_name",
+		Fields: []dao.SearchField{
+			{Column: "company_name", Weight: 3},
+			{Column: "project_name", Weight: 2},
+		},
+	},
+}
+
+// SearchHit a record found by /search with the url of its crud endpoint
+type SearchHit struct {
+	Type     string      `json:"type" example:"customers"`
+	ID       string      `json:"id" example:"12"`
+	Title    string      `json:"title" example:"Smith Elevators"`
+	Subtitle string      `json:"subtitle" example:"John Smith"`
+	URL      string      `json:"url" example:"/customers/12"`
+	Score    float64     `json:"score" example:"18"`
+	Matches  []string    `json:"matches"`
+	Record   interface{} `json:"record"`
+}
+
+// SearchResults hits of a /search query, best ranked first
+type SearchResults struct {
+	Query string       `json:"query" example:"smith"`
+	Hits  []*SearchHit `json:"hits"`
+}
+
+func configSearchRouter(router *httprouter.Router) {
+	router.GET("/search", Search)
+}
+
+func configGinSearchRouter(router gin.IRoutes) {
+	router.GET("/search", ConverHttprouterToGin(Search))
+}
+
+// Search finds customers, leads, buildings and quotes by company, contact and project names
+// @Summary Search customers, leads, buildings and quotes
+// @Tags Search
+// @Description Search returns the records where every word of q appears in a searched column, ranked by how closely and in which column
+// @Description they match. Tables the caller may not list are skipped, each hit links to the crud url of the record.
+// @Accept  json
+// @Produce  json
+// @Param   q     query    string  true   "words to find, ie smith"
+// @Param   types query    string  false  "comma separated tables to search (defaults to customers,leads,buildings,quotes)"
+// @Param   limit query    int     false  "maximum number of hits (defaults to 20)"
+// @Success 200 {object} api.SearchResults
+// @Failure 400 {object} api.HTTPError
+// @Failure 403 {object} api.HTTPError
+// @Router /search [get]
+// http "https://xinqi.dev:443/search?q=smith&types=customers,leads" X-Api-User:user123
+func Search(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	query := strings.TrimSpace(r.FormValue("q"))
+	terms := strings.Fields(strings.ToLower(query))
+	if len(query) < 2 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+
+	limit, err := readInt(r, "limit", 20)
+	if err != nil || limit <= 0 || limit > maxSearchLimit {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	targets, err := searchTargetsOf(r.FormValue("types"))
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	var (
+		hits    []*dao.SearchHit
+		allowed int
+	)
+	for _, target := range targets {
+		if err := ValidateRequest(ctx, r, target.Table, model.RetrieveMany); err != nil {
+			continue
+		}
+		allowed++
+
+		found, err := dao.Search(ctx, target.Table, target.Fields, terms, int(limit))
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+		hits = append(hits, found...)
+	}
+
+	if allowed == 0 {
+		returnError(ctx, w, r, ErrForbidden)
+		return
+	}
+
+	dao.SortSearchHits(hits)
+	if len(hits) > int(limit) {
+		hits = hits[:limit]
+	}
+
+	result := &SearchResults{Query: query, Hits: make([]*SearchHit, 0, len(hits))}
+	for _, hit := range hits {
+		result.Hits = append(result.Hits, newSearchHit(hit))
+	}
+	writeJSON(ctx, w, result)
+}
+
+// searchTargetsOf the targets named in a comma separated types parameter, all of them when it is empty
+func searchTargetsOf(types string) ([]*searchTarget, error) {
+	if types == "" {
+		return searchTargets, nil
+	}
+
+	var targets []*searchTarget
+	for _, name := range strings.Split(types, ",") {
+		var found *searchTarget
+		for _, target := range searchTargets {
+			if target.Table == strings.TrimSpace(name) {
+				found = target
+			}
+		}
+
+		if found == nil {
+			return nil, dao.ErrBadParams
+		}
+		targets = append(targets, found)
+	}
+	return targets, nil
+}
+
+func newSearchHit(hit *dao.SearchHit) *SearchHit {
+	info := hit.Record.TableInfo()
+	id := dao.PrimaryKey(hit.Table, hit.Record)
+	result := &SearchHit{
+		Type:    hit.Table,
+		ID:      id,
+		Score:   hit.Score,
+		Matches: hit.Matches,
+		Record:  model.Redact(hit.Record),
+	}
+
+	if crud, ok := crudEndpoints[hit.Table]; ok {
+		result.URL = crud.RetrieveOneURL + "/" + id
+	}
+
+	for _, target := range searchTargets {
+		if target.Table != hit.Table {
+			continue
+		}
+
+		for _, col := range info.Columns {
+			value, _ := model.ColumnValue(hit.Record, col)
+			text, _ := value.(string)
+			switch col.JSONFieldName {
+			case target.Title:
+				result.Title = text
+			case target.Subtitle:
+				result.Subtitle = text
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenAccess kind of a short lived token sent with every request
+	TokenAccess = "access"
+
+	// TokenRefresh kind of a long lived token only accepted by /auth/refresh
+	TokenRefresh = "refresh"
+)
+
+var (
+	errTokenMalformed = errors.New("token malformed")
+	errTokenSignature = errors.New("token signature invalid")
+	errTokenExpired   = errors.New("token expired")
+
+	tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+)
+
+// TokenClaims the payload of a signed access or refresh token
+type TokenClaims struct {
+	Subject   int64  `json:"sub"`
+	Type      string `json:"typ"`
+	Email     string `json:"email"`
+	Kind      string `json:"knd"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+
+	EmployeeID int64    `json:"emp,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+
+	// PasswordFingerprint ties the token to the password hash, changing the password invalidates outstanding access and
+	// refresh tokens as both are checked against the current hash
+	PasswordFingerprint string `json:"pwd,omitempty"`
+}
+
+// SignToken encode and sign claims as a compact HS256 JWT
+func SignToken(secret []byte, claims *TokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + tokenSignature(secret, unsigned), nil
+}
+
+// ParseToken verify the signature and expiry of a token created by SignToken and return its claims
+func ParseToken(secret []byte, token string, now time.Time) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, errTokenMalformed
+	}
+
+	expected := tokenSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errTokenSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errTokenMalformed
+	}
+
+	claims := &TokenClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, errTokenMalformed
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, errTokenExpired
+	}
+
+	return claims, nil
+}
+
+func tokenSignature(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// passwordFingerprint short digest of a password hash embedded in tokens
+func passwordFingerprint(secret []byte, encryptedPassword string) string {
+	return tokenSignature(secret, encryptedPassword)[:12]
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+
+	"rocket/dao"
+	"rocket/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+)
+
+func configTrashRouter(router *httprouter.Router) {
+	for table := range dao.SoftDeleteTables {
+		if crud, ok := crudEndpoints[table]; ok {
+			router.POST(crud.RetrieveOneURL+"/:argID/restore", RestoreRecord(table))
+		}
+	}
+}
+
+func configGinTrashRouter(router gin.IRoutes) {
+	for table := range dao.SoftDeleteTables {
+		if crud, ok := crudEndpoints[table]; ok {
+			router.POST(crud.RetrieveOneURL+"/:argID/restore", ConverHttprouterToGin(RestoreRecord(table)))
+		}
+	}
+}
+
+// withTrash serves GET /<table>/trash from the /<table>/:argID route, the routers do not allow a static segment next to :argID
+func withTrash(table string, next httprouter.Handle) httprouter.Handle {
+	trash := GetAllTrash(table)
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName("argID") == "trash" {
+			trash(w, r, ps)
+			return
+		}
+		next(w, r, ps)
+	}
+}
+
+// GetAllTrash returns a handler listing the soft deleted records of table
+// @Summary Get list of soft deleted records
+// @Tags Trash
+// @Description GetAllTrash lists the records of a soft delete table that were deleted and not yet purged, most recently deleted first
+// @Accept  json
+// @Produce  json
+// @Param   resource path     string  true         "resource url, ie customers"
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Success 200 {object} api.PagedResults
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /{resource}/trash [get]
+// http "https://xinqi.dev:443/customers/trash?page=0&pagesize=20" X-Api-User:user123
+func GetAllTrash(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+		page, err := readInt(r, "page", 0)
+		if err != nil || page < 0 {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+
+		pagesize, err := readInt(r, "pagesize", 20)
+		if err != nil || pagesize <= 0 {
+			returnError(ctx, w, r, dao.ErrBadParams)
+			return
+		}
+
+		if err := ValidateRequest(ctx, r, table, model.RetrieveMany); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		records, totalRows, err := dao.GetAllTrash(ctx, table, page, pagesize)
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+		writeJSON(ctx, w, result)
+	}
+}
+
+// RestoreRecord returns a handler undoing the soft delete of a record of table
+// @Summary Restore a soft deleted record
+// @Tags Trash
+// @Description RestoreRecord clears deleted_at of a record so it is visible again, it requires the permission to delete the record
+// @Accept  json
+// @Produce  json
+// @Param  resource path string true "resource url, ie customers"
+// @Param  argID    path int64  true "id"
+// @Success 200 {object} interface{}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, no deleted record for id"
+// @Router /{resource}/{argID}/restore [post]
+// http POST "https://xinqi.dev:443/customers/1/restore" X-Api-User:user123
+func RestoreRecord(table string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx := initializeContext(r)
+
+		argID, err := parseInt64(ps, "argID")
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		if err := ValidateRequest(ctx, r, table, model.Delete); err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		record, err := dao.RestoreRecord(ctx, table, argID)
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+
+		writeJSON(ctx, w, record)
+	}
+}
@@ -0,0 +1,487 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Webhooks dispatcher installed by ConfigureWebhooks, woken when a delivery is replayed
+var Webhooks *webhook.Dispatcher
+
+// WebhookSubscriptionRequest body posted to /webhooks/subscriptions, on update empty fields keep their current value
+type WebhookSubscriptionRequest struct {
+	// Table table whose changes are sent, * for every table
+	Table string `json:"table_name" example:"interventions"`
+
+	// Actions comma separated actions sent, Create, Update, Delete or * for all of them
+	Actions string `json:"actions" example:"Create,Update"`
+
+	TargetURL string `json:"target_url" example:"https://tickets.example.com/hooks/rocket"`
+
+	// Secret key of the X-Rocket-Signature HMAC, generated when empty on create
+	Secret string `json:"secret"`
+
+	Active      *bool   `json:"active"`
+	Description *string `json:"description" example:"ticketing"`
+}
+
+// WebhookSubscriptionCreated response to a new subscription, the only response carrying the secret
+type WebhookSubscriptionCreated struct {
+	Subscription interface{} `json:"subscription"`
+	Secret       string      `json:"secret"`
+}
+
+// ConfigureWebhooks install the dispatcher sending webhook deliveries
+func ConfigureWebhooks(dispatcher *webhook.Dispatcher) {
+	Webhooks = dispatcher
+}
+
+func configWebhooksRouter(router *httprouter.Router) {
+	router.GET("/webhooks/subscriptions", GetAllWebhookSubscriptions)
+	router.POST("/webhooks/subscriptions", AddWebhookSubscriptions)
+	router.GET("/webhooks/subscriptions/:argID", GetWebhookSubscriptions)
+	router.PUT("/webhooks/subscriptions/:argID", UpdateWebhookSubscriptions)
+	router.DELETE("/webhooks/subscriptions/:argID", DeleteWebhookSubscriptions)
+	router.GET("/webhooks/deliveries", GetAllWebhookDeliveries)
+	router.GET("/webhooks/deliveries/:argID", GetWebhookDeliveries)
+	router.POST("/webhooks/deliveries/:argID/replay", ReplayWebhookDelivery)
+}
+
+func configGinWebhooksRouter(router gin.IRoutes) {
+	router.GET("/webhooks/subscriptions", ConverHttprouterToGin(GetAllWebhookSubscriptions))
+	router.POST("/webhooks/subscriptions", ConverHttprouterToGin(AddWebhookSubscriptions))
+	router.GET("/webhooks/subscriptions/:argID", ConverHttprouterToGin(GetWebhookSubscriptions))
+	router.PUT("/webhooks/subscriptions/:argID", ConverHttprouterToGin(UpdateWebhookSubscriptions))
+	router.DELETE("/webhooks/subscriptions/:argID", ConverHttprouterToGin(DeleteWebhookSubscriptions))
+	router.GET("/webhooks/deliveries", ConverHttprouterToGin(GetAllWebhookDeliveries))
+	router.GET("/webhooks/deliveries/:argID", ConverHttprouterToGin(GetWebhookDeliveries))
+	router.POST("/webhooks/deliveries/:argID/replay", ConverHttprouterToGin(ReplayWebhookDelivery))
+}
+
+// GetAllWebhookSubscriptions is a function to get a slice of webhook subscriptions, secrets are never returned
+// @Summary Get list of webhook subscriptions
+// @Tags Webhooks
+// @Description GetAllWebhookSubscriptions is a handler to list the endpoints notified when records change
+// @Accept  json
+// @Produce  json
+// @Param   page     query    int     false        "page requested (defaults to 0)"
+// @Param   pagesize query    int     false        "number of records in a page  (defaults to 20)"
+// @Param   order    query    string  false        "db sort order column"
+// @Success 200 {object} api.PagedResults{data=[]model.WebhookSubscriptions}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks/subscriptions [get]
+// http "https://xinqi.dev:443/webhooks/subscriptions?page=0&pagesize=20" X-Api-User:user123
+func GetAllWebhookSubscriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	order := r.FormValue("order")
+
+	if err := ValidateRequest(ctx, r, "webhook_subscriptions", model.RetrieveMany); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	records, totalRows, err := dao.GetAllWebhookSubscriptions(ctx, page, pagesize, order)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(ctx, w, result)
+}
+
+// GetWebhookSubscriptions is a function to get a single webhook subscription, its secret is never returned
+// @Summary Get a webhook subscription by argID
+// @Tags Webhooks
+// @Description GetWebhookSubscriptions is a handler to get a single webhook subscription
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Success 200 {object} model.WebhookSubscriptions
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /webhooks/subscriptions/{argID} [get]
+// http "https://xinqi.dev:443/webhooks/subscriptions/1" X-Api-User:user123
+func GetWebhookSubscriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_subscriptions", model.RetrieveOne); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetWebhookSubscriptions(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// AddWebhookSubscriptions add a webhook subscription, a secret is generated when none is given and returned only in this response
+// @Summary Add a webhook subscription
+// @Tags Webhooks
+// @Description AddWebhookSubscriptions registers an endpoint receiving a signed POST for every matching create, update or delete
+// @Accept  json
+// @Produce  json
+// @Param  WebhookSubscriptionRequest body api.WebhookSubscriptionRequest true "subscription"
+// @Success 200 {object} api.WebhookSubscriptionCreated
+// @Failure 400 {object} api.HTTPError
+// @Router /webhooks/subscriptions [post]
+// echo '{"table_name": "interventions", "actions": "Create,Update", "target_url": "https://tickets.example.com/hooks/rocket"}' | http POST "https://xinqi.dev:443/webhooks/subscriptions" X-Api-User:user123
+func AddWebhookSubscriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	request := &WebhookSubscriptionRequest{}
+	if err := readJSON(r, request); err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	record := &model.WebhookSubscriptions{Actions: "*", Active: true}
+	request.apply(record)
+
+	if record.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			returnError(ctx, w, r, err)
+			return
+		}
+		record.Secret = secret
+	}
+
+	if err := validateWebhookSubscription(record); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_subscriptions", model.Create); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, _, err := dao.AddWebhookSubscriptions(ctx, record)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, &WebhookSubscriptionCreated{Subscription: model.Redact(record), Secret: record.Secret})
+}
+
+// UpdateWebhookSubscriptions update a webhook subscription, fields left out of the request keep their value
+// @Summary Update a webhook subscription
+// @Tags Webhooks
+// @Description UpdateWebhookSubscriptions changes the table, actions, url, secret or state of a subscription, set active to false to pause deliveries
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Param  WebhookSubscriptionRequest body api.WebhookSubscriptionRequest true "changed fields"
+// @Success 200 {object} model.WebhookSubscriptions
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks/subscriptions/{argID} [put]
+// echo '{"active": false}' | http PUT "https://xinqi.dev:443/webhooks/subscriptions/1" X-Api-User:user123
+func UpdateWebhookSubscriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	request := &WebhookSubscriptionRequest{}
+	if err := readJSON(r, request); err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_subscriptions", model.Update); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetWebhookSubscriptions(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	request.apply(record)
+	if err := validateWebhookSubscription(record); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, _, err = dao.SaveWebhookSubscriptions(ctx, record)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// DeleteWebhookSubscriptions delete a webhook subscription, its pending deliveries fail on their next attempt
+// @Summary Delete a webhook subscription
+// @Tags Webhooks
+// @Description DeleteWebhookSubscriptions removes a subscription, the delivery log is kept
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Success 204 {object} model.WebhookSubscriptions
+// @Failure 400 {object} api.HTTPError
+// @Failure 500 {object} api.HTTPError
+// @Router /webhooks/subscriptions/{argID} [delete]
+// http DELETE "https://xinqi.dev:443/webhooks/subscriptions/1" X-Api-User:user123
+func DeleteWebhookSubscriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_subscriptions", model.Delete); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	rowsAffected, err := dao.DeleteWebhookSubscriptions(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeRowsAffected(w, rowsAffected)
+}
+
+// GetAllWebhookDeliveries is a function to get the delivery log, newest first
+// @Summary Get list of webhook deliveries
+// @Tags Webhooks
+// @Description GetAllWebhookDeliveries is a handler to query the deliveries queued for subscriptions with the outcome of their last attempt
+// @Accept  json
+// @Produce  json
+// @Param   subscription_id query int    false "id of the subscription"
+// @Param   status          query string false "pending, delivered or failed"
+// @Param   table           query string false "table name, ie interventions"
+// @Param   id              query string false "primary key of the changed record"
+// @Param   page            query int    false "page requested (defaults to 0)"
+// @Param   pagesize        query int    false "number of records in a page  (defaults to 20)"
+// @Success 200 {object} api.PagedResults{data=[]model.WebhookDeliveries}
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks/deliveries [get]
+// http "https://xinqi.dev:443/webhooks/deliveries?status=failed" X-Api-User:user123
+func GetAllWebhookDeliveries(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+	page, err := readInt(r, "page", 0)
+	if err != nil || page < 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	pagesize, err := readInt(r, "pagesize", 20)
+	if err != nil || pagesize <= 0 {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	subscriptionID, err := readInt(r, "subscription_id", 0)
+	if err != nil {
+		returnError(ctx, w, r, dao.ErrBadParams)
+		return
+	}
+
+	filter := &dao.WebhookDeliveryFilter{
+		SubscriptionID: subscriptionID,
+		Status:         r.FormValue("status"),
+		Table:          r.FormValue("table"),
+		RecordID:       r.FormValue("id"),
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_deliveries", model.RetrieveMany); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	records, totalRows, err := dao.GetAllWebhookDeliveries(ctx, filter, page, pagesize)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	result := &PagedResults{Page: page, PageSize: pagesize, Data: records, TotalRecords: totalRows}
+	writeJSON(ctx, w, result)
+}
+
+// GetWebhookDeliveries is a function to get a single delivery with its payload
+// @Summary Get a webhook delivery by argID
+// @Tags Webhooks
+// @Description GetWebhookDeliveries is a handler to get a single delivery with the payload sent and the outcome of its last attempt
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id"
+// @Success 200 {object} model.WebhookDeliveries
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError "ErrNotFound, db record for id not found - returns NotFound HTTP 404 not found error"
+// @Router /webhooks/deliveries/{argID} [get]
+// http "https://xinqi.dev:443/webhooks/deliveries/1" X-Api-User:user123
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_deliveries", model.RetrieveOne); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.GetWebhookDeliveries(ctx, argID)
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// ReplayWebhookDelivery queue a new delivery of the payload of an earlier delivery, ie after the receiver fixed an outage
+// @Summary Replay a webhook delivery
+// @Tags Webhooks
+// @Description ReplayWebhookDelivery queues the payload of a delivery again for its subscription, the new delivery references the original in replay_of
+// @Accept  json
+// @Produce  json
+// @Param  argID path int64 true "id of the delivery to replay"
+// @Success 200 {object} model.WebhookDeliveries
+// @Failure 400 {object} api.HTTPError
+// @Failure 404 {object} api.HTTPError
+// @Router /webhooks/deliveries/{argID}/replay [post]
+// http POST "https://xinqi.dev:443/webhooks/deliveries/1/replay" X-Api-User:user123
+func ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx := initializeContext(r)
+
+	argID, err := parseInt64(ps, "argID")
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if err := ValidateRequest(ctx, r, "webhook_deliveries", model.Create); err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	record, err := dao.ReplayWebhookDelivery(ctx, argID, time.Now())
+	if err != nil {
+		returnError(ctx, w, r, err)
+		return
+	}
+
+	if Webhooks != nil {
+		Webhooks.Wake()
+	}
+
+	writeJSON(ctx, w, record)
+}
+
+// apply copy the fields set in the request to record
+func (s *WebhookSubscriptionRequest) apply(record *model.WebhookSubscriptions) {
+	if s.Table != "" {
+		record.TableName_ = s.Table
+	}
+
+	if s.Actions != "" {
+		record.Actions = s.Actions
+	}
+
+	if s.TargetURL != "" {
+		record.TargetURL = s.TargetURL
+	}
+
+	if s.Secret != "" {
+		record.Secret = s.Secret
+	}
+
+	if s.Active != nil {
+		record.Active = *s.Active
+	}
+
+	if s.Description != nil {
+		record.Description = null.NewString(*s.Description, *s.Description != "")
+	}
+}
+
+// validateWebhookSubscription require a known table or *, known actions and an absolute http(s) url
+func validateWebhookSubscription(record *model.WebhookSubscriptions) error {
+	if record.TableName_ != "*" {
+		if _, ok := model.GetTableInfo(record.TableName_); !ok || strings.HasPrefix(record.TableName_, "webhook_") || record.TableName_ == "audit_logs" {
+			return dao.ErrBadParams
+		}
+	}
+
+	for _, name := range strings.Split(record.Actions, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "*", "create", "update", "delete":
+		default:
+			return dao.ErrBadParams
+		}
+	}
+
+	target, err := url.Parse(record.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return dao.ErrBadParams
+	}
+
+	if record.Secret == "" {
+		return dao.ErrBadParams
+	}
+	return nil
+}
+
+// newWebhookSecret random hex secret of a subscription
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
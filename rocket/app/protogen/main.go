@@ -0,0 +1,30 @@
+// protogen writes the .proto definitions of the grpc services, generated from the column metadata of the models
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/droundy/goopt"
+
+	"rocket/api"
+	"rocket/rpc"
+)
+
+var out = goopt.String([]string{"-o", "--out"}, "", "file the definitions are written to, stdout when empty")
+
+func main() {
+	goopt.Description = func() string { return "Generate the .proto definitions of the grpc services" }
+	goopt.Parse(nil)
+
+	proto := rpc.ProtoFile(api.GRPCTables())
+	if *out == "" {
+		os.Stdout.WriteString(proto)
+		return
+	}
+
+	if err := ioutil.WriteFile(*out, []byte(proto), 0644); err != nil {
+		log.Fatalf("Error writing %s, the error is '%v'", *out, err)
+	}
+}
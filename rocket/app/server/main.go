@@ -2,11 +2,20 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	_ "github.com/jinzhu/gorm/dialects/mssql"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
@@ -18,11 +27,23 @@ import (
 	"github.com/jinzhu/gorm"
 	"github.com/swaggo/files"       // swagger embed files
 	"github.com/swaggo/gin-swagger" // gin-swagger middleware
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"rocket/api"
+	"rocket/blazer"
 	"rocket/dao"
 	_ "rocket/docs"
+	"rocket/logging"
+	"rocket/metrics"
+	"rocket/migrate"
 	"rocket/model"
+	"rocket/notify"
+	"rocket/policy"
+	"rocket/ratelimit"
+	"rocket/stream"
+	"rocket/tlsconfig"
+	"rocket/webhook"
 )
 
 var (
@@ -46,22 +67,612 @@ var (
 
 	// OsSignal signal used to shutdown
 	OsSignal chan os.Signal
+
+	blazerChecks      = goopt.Flag([]string{"--blazer-checks"}, []string{"--no-blazer-checks"}, "run scheduled blazer checks", "do not run scheduled blazer checks")
+	checkScanInterval = goopt.String([]string{"--check-scan-interval"}, "1m", "how often blazer_checks is scanned for due checks")
+	checkQueryTimeout = goopt.String([]string{"--check-query-timeout"}, "1m", "maximum run time of a single blazer check query")
+	checkWebhookURL   = goopt.String([]string{"--check-webhook-url"}, "", "url receiving blazer check state changes as json, ie a slack relay")
+	smtpAddr          = goopt.String([]string{"--smtp-addr"}, "", "host:port of the smtp server used for email notifications")
+	smtpFrom          = goopt.String([]string{"--smtp-from"}, "rocket@localhost", "from address of email notifications")
+	smtpUser          = goopt.String([]string{"--smtp-user"}, "", "smtp username, empty for unauthenticated relays")
+	smtpPassword      = goopt.String([]string{"--smtp-password"}, "", "smtp password")
+
+	disableAuth     = goopt.Flag([]string{"--no-auth"}, nil, "disable authentication, every table is world writable (development only)", "")
+	tokenSecret     = goopt.String([]string{"--token-secret"}, "", "secret used to sign access and refresh tokens, a random secret is generated when empty")
+	accessTokenTTL  = goopt.String([]string{"--access-token-ttl"}, "15m", "lifetime of access tokens")
+	refreshTokenTTL = goopt.String([]string{"--refresh-token-ttl"}, "720h", "lifetime of refresh tokens")
+	devisePepper    = goopt.String([]string{"--devise-pepper"}, "", "devise pepper of the rails app, empty unless config.pepper is set")
+	softDelete      = goopt.String([]string{"--soft-delete"}, "", "comma separated tables whose deletes only set deleted_at, * for every table with the column, empty deletes for good")
+	trashRetention  = goopt.String([]string{"--trash-retention"}, "720h", "how long soft deleted records are kept before they are purged, 0 keeps them forever")
+	trashPurgeEvery = goopt.String([]string{"--trash-purge-interval"}, "1h", "how often soft deleted records past --trash-retention are purged")
+	disableAudit    = goopt.Flag([]string{"--no-audit"}, nil, "do not record creates, updates and deletes in audit_logs", "")
+	disableWebhooks = goopt.Flag([]string{"--no-webhooks"}, nil, "do not send webhook deliveries for creates, updates and deletes", "")
+	eventBuffer     = goopt.Int([]string{"--event-buffer"}, 1000, "number of changes kept for /events clients resuming with Last-Event-ID, 0 disables /events")
+	webhookInterval = goopt.String([]string{"--webhook-scan-interval"}, "5s", "how often webhook_deliveries is scanned for retries that are due")
+	policyFile      = goopt.String([]string{"--policy-file"}, "", "yaml file mapping roles to allowed tables and actions, the built in policy is used when empty")
+	resetTokenTTL   = goopt.String([]string{"--reset-token-ttl"}, "6h", "how long password reset links stay valid")
+	resetURL        = goopt.String([]string{"--reset-url"}, "", "front end page receiving the reset_password_token parameter of password reset links")
+	mailFile        = goopt.String([]string{"--mail-file"}, "", "append outgoing mail to this file instead of logging it when --smtp-addr is not set")
+	publicURL       = goopt.String([]string{"--public-url"}, "", "scheme and host clients reach the api at, ie https://api.example.com, the request host is used when empty, as forwarded by the --trusted-proxies")
+	grpcAddr        = goopt.String([]string{"--grpc-addr"}, "", "address the grpc services listen on, ie :9090, over https with the certificate of --tls-cert or cleartext http/2 without it, empty disables grpc")
+	metricsAddr     = goopt.String([]string{"--metrics-addr"}, "", "address serving /metrics without authentication for scrapers, ie 127.0.0.1:9100, empty only serves it on the api to admins")
+	slowQuery       = goopt.String([]string{"--slow-query-threshold"}, "200ms", "sql statements taking longer are logged as warnings flagged slow, 0 disables the flag")
+	logSQL          = goopt.Flag([]string{"--log-sql"}, nil, "log every sql statement, otherwise only slow and failed ones are logged", "")
+	trustedProxy    = goopt.String([]string{"--trusted-proxies"}, "", "comma separated ip addresses or cidrs of the reverse proxies whose X-Forwarded-* and X-Real-Ip headers give the client address and the request host, ie 10.0.0.0/8")
+	rateLimit       = goopt.String([]string{"--rate-limit"}, "600/1m", "requests a user, or an ip address without authentication, may make per period, ie 10/s, none disables rate limiting")
+	routeRateLimits = goopt.Strings([]string{"--route-rate-limit"}, "METHOD /route=LIMIT", "rate limit of a route or grpc method overriding --rate-limit, ie 'GET /leads=60/1m' or 'POST /rocket.v1.LeadService/List=60/1m', may be repeated")
+	httpAddr        = goopt.String([]string{"--http-addr"}, ":8080", "address the rest api listens on")
+	readTimeout     = goopt.String([]string{"--read-timeout"}, "1m", "maximum time to read a request, its body included, 0 disables it")
+	writeTimeout    = goopt.String([]string{"--write-timeout"}, "0", "maximum time to write a response once its request was read, 0 disables it, /events streams are cut after it")
+	idleTimeout     = goopt.String([]string{"--idle-timeout"}, "2m", "how long idle keep-alive connections are kept open")
+	shutdownTimeout = goopt.String([]string{"--shutdown-timeout"}, "30s", "how long in-flight requests are drained on SIGINT or SIGTERM before their connections are closed")
+	jobsTimeout     = goopt.String([]string{"--jobs-shutdown-timeout"}, "15s", "how long background jobs, ie a running blazer check or webhook delivery, get to stop once the requests were drained")
+	tlsCert         = goopt.String([]string{"--tls-cert"}, "", "pem certificate chain, with --tls-key the rest api is served over https")
+	tlsKey          = goopt.String([]string{"--tls-key"}, "", "pem private key of --tls-cert")
+	tlsClientCA     = goopt.String([]string{"--tls-client-ca"}, "", "pem authorities client certificates are verified with, presented certificates must be valid")
+	tlsRequireCert  = goopt.Flag([]string{"--tls-require-client-cert"}, nil, "reject clients without a certificate signed by --tls-client-ca, ie for internal clients only", "")
+	tlsReload       = goopt.String([]string{"--tls-reload-interval"}, "30s", "how often the tls files are checked for changes and reloaded, 0 disables reloading")
+	redirectAddr    = goopt.String([]string{"--http-redirect-addr"}, "", "address of a listener redirecting plain http requests to https, ie :80, needs --tls-cert")
+	readyTimeout    = goopt.String([]string{"--ready-timeout"}, "2s", "how long /readyz waits for the database ping and the migrations check")
+	requiredMigrate = goopt.String([]string{"--required-migration"}, "", "schema_migrations version of the rails app /readyz requires to be applied, ie 20210304101112")
+	migrationsDir   = goopt.String([]string{"--migrations-dir"}, "", "directory of the VERSION_name.up.sql and VERSION_name.down.sql migrations run by the migrate command, the migrations directory next to the binary or else in the working directory when empty")
+	migrateFake     = goopt.Flag([]string{"--migrate-fake"}, nil, "record migrations as applied or reverted without running their sql, ie for a schema created by --automigrate", "")
+	autoMigrate     = goopt.Flag([]string{"--automigrate"}, nil, "create missing tables, columns and indexes of every model at startup, this alters the schema outside of the migrations", "")
+	noDriftCheck    = goopt.Flag([]string{"--no-drift-check"}, nil, "skip comparing the live schema with the table info of the models at startup", "")
 )
 
-// GinServer launch gin server
-func GinServer() (err error) {
+// ConfigureRateLimit install the rate limits from the command line options
+func ConfigureRateLimit() {
+	limit, err := ratelimit.ParseLimit(*rateLimit)
+	if err != nil {
+		log.Fatalf("Invalid --rate-limit '%s', the error is '%v'", *rateLimit, err)
+	}
+
+	config := &api.RateLimitConfig{Default: limit, Routes: make(map[string]ratelimit.Limit)}
+	for _, s := range *routeRateLimits {
+		route, limit, err := api.ParseRouteLimit(s)
+		if err != nil {
+			log.Fatalf("Invalid --route-rate-limit '%s', the error is '%v'", s, err)
+		}
+		config.Routes[route] = limit
+	}
+	api.ConfigureRateLimit(config)
+}
+
+// ConfigureTrustedProxies honor the forwarding headers of the --trusted-proxies, the client address of other requests is the peer
+// address
+func ConfigureTrustedProxies() {
+	var proxies []string
+	for _, proxy := range strings.Split(*trustedProxy, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	if err := api.ConfigureTrustedProxies(proxies); err != nil {
+		log.Fatalf("Invalid --trusted-proxies '%s', the error is '%v'", *trustedProxy, err)
+	}
+}
+
+// ConfigureReadiness install the /readyz checks, schemaErr is the error of preparing the schema at startup
+func ConfigureReadiness(migrator *migrate.Migrator, schemaErr error) {
+	timeout, err := time.ParseDuration(*readyTimeout)
+	if err != nil {
+		log.Fatalf("Invalid --ready-timeout '%s', the error is '%v'", *readyTimeout, err)
+	}
+
+	api.ConfigureReadiness(&api.ReadinessConfig{
+		Timeout: timeout,
+		Checks: map[string]api.HealthCheckFunc{
+			"migrations": func(ctx context.Context) error {
+				if schemaErr != nil {
+					return schemaErr
+				}
+
+				pending, err := migrator.Pending()
+				if err != nil {
+					return err
+				}
+				if len(pending) > 0 {
+					return fmt.Errorf("%d migrations are pending, the first is %s_%s", len(pending), pending[0].Version, pending[0].Name)
+				}
+				return dao.CheckMigration(ctx, *requiredMigrate)
+			},
+		},
+	})
+}
+
+// CheckSchemaDrift log the tables whose live schema drifted from the table info of their model, ie a column the rails app
+// added or a migration that was not applied, the server is started anyway
+func CheckSchemaDrift() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	drift, err := dao.DetectSchemaDrift(ctx, model.TableNames())
+	if err != nil {
+		log.Printf("Checking the schema for drift failed, the error is '%v'", err)
+		return
+	}
+
+	for _, table := range drift.Tables {
+		missingIndexes := make([]string, 0, len(table.MissingIndexes))
+		for _, index := range table.MissingIndexes {
+			missingIndexes = append(missingIndexes, index.Name)
+		}
+
+		logging.Warn(ctx, "schema drift", logging.Fields{
+			"table":               table.Table,
+			"missing_table":       table.MissingTable,
+			"missing_columns":     table.MissingColumns,
+			"extra_columns":       table.ExtraColumns,
+			"type_mismatches":     table.TypeMismatches,
+			"nullable_mismatches": table.NullableMismatches,
+			"missing_indexes":     missingIndexes,
+		})
+	}
+	if drift.InSync {
+		log.Printf("The schema matches the table info of %d tables", len(model.TableNames()))
+	}
+}
+
+// MigrationsDir the directory of the migrations, --migrations-dir or the migrations directory installed next to the binary.
+// The migrations directory of the working directory is used when the binary has none, ie under go run.
+func MigrationsDir() string {
+	if *migrationsDir != "" {
+		return *migrationsDir
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		if executable, err = filepath.EvalSymlinks(executable); err == nil {
+			dir := filepath.Join(filepath.Dir(executable), "migrations")
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return dir
+			}
+		}
+	}
+	return "migrations"
+}
+
+// Migrator build the migrator of the MigrationsDir migrations, an empty migrator when they cannot be loaded
+// error - the migrations could not be loaded
+func Migrator(db *gorm.DB) (*migrate.Migrator, error) {
+	dir := MigrationsDir()
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		err = fmt.Errorf("loading the migrations of %s failed: %v", dir, err)
+	}
+
+	migrator := migrate.New(db, migrations)
+	migrator.Fake = *migrateFake
+	migrator.Log = func(m *migrate.Migration, up bool) {
+		direction := "Applying"
+		if !up {
+			direction = "Reverting"
+		}
+		if migrator.Fake {
+			direction += " (fake)"
+		}
+		log.Printf("%s migration %s_%s", direction, m.Version, m.Name)
+	}
+	return migrator, err
+}
+
+// RunMigrate run the migrate command, up, down [steps], status or to VERSION
+func RunMigrate(db *gorm.DB, args []string) error {
+	migrator, err := Migrator(db)
+	if err != nil {
+		return err
+	}
+
+	command := "status"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch {
+	case command == "up" && len(args) == 0:
+		_, err = migrator.Up()
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps '%s'", args[0])
+			}
+		}
+		_, err = migrator.Down(steps)
+	case command == "to" && len(args) == 1:
+		_, err = migrator.To(args[0])
+	case command == "status" && len(args) == 0:
+		var statuses []*migrate.Status
+		if statuses, err = migrator.Status(); err == nil {
+			for _, status := range statuses {
+				state := "down"
+				if status.Applied {
+					state = "up"
+				}
+				fmt.Printf("%-6s %s_%s\n", state, status.Version, status.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("usage: migrate up | down [steps] | status | to VERSION")
+	}
+	return err
+}
+
+// AutoMigrate create the missing tables, columns and indexes of every model
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&model.ActiveAdminComments{},
+		&model.ActiveStorageAttachments{},
+		&model.ActiveStorageBlobs{},
+		&model.Addresses{},
+		&model.AdminUsers{},
+		&model.APIKeys{},
+		&model.ArInternalMetadata{},
+		&model.AuditLogs{},
+		&model.Batteries{},
+		&model.BlazerAudits{},
+		&model.BlazerChecks{},
+		&model.BlazerDashboardQueries{},
+		&model.BlazerDashboards{},
+		&model.BlazerQueries{},
+		&model.BuildingDetails{},
+		&model.Buildings{},
+		&model.Columns{},
+		&model.Customers{},
+		&model.Elevators{},
+		&model.Employees{},
+		&model.Interventions{},
+		&model.Leads{},
+		&model.Maps{},
+		&model.Quotes{},
+		&model.SchemaMigrations{},
+		&model.Users_{},
+		&model.WebhookDeliveries{},
+		&model.WebhookSubscriptions{},
+	).Error
+}
+
+// ConfigureAuth install token authentication from the command line options
+func ConfigureAuth() {
+	if *disableAuth {
+		log.Printf("WARNING authentication is disabled, every table is world writable")
+		return
+	}
+
+	config := &api.AuthConfig{Secret: []byte(*tokenSecret), Pepper: *devisePepper, Mailer: mailer(), ResetURL: *resetURL}
+	if len(config.Secret) == 0 {
+		log.Printf("WARNING no --token-secret given, tokens will not survive a restart")
+		config.Secret = make([]byte, 32)
+		if _, err := rand.Read(config.Secret); err != nil {
+			log.Fatalf("Unable to generate token secret, the error is '%v'", err)
+		}
+	}
+
+	var err error
+	if config.AccessTTL, err = time.ParseDuration(*accessTokenTTL); err != nil {
+		log.Fatalf("Invalid --access-token-ttl '%s', the error is '%v'", *accessTokenTTL, err)
+	}
+
+	if config.RefreshTTL, err = time.ParseDuration(*refreshTokenTTL); err != nil {
+		log.Fatalf("Invalid --refresh-token-ttl '%s', the error is '%v'", *refreshTokenTTL, err)
+	}
+
+	if config.ResetTTL, err = time.ParseDuration(*resetTokenTTL); err != nil {
+		log.Fatalf("Invalid --reset-token-ttl '%s', the error is '%v'", *resetTokenTTL, err)
+	}
+
+	api.ConfigureAuth(config)
+
+	accessPolicy, err := loadPolicy()
+	if err != nil {
+		log.Fatalf("Unable to load access policy, the error is '%v'", err)
+	}
+	api.ConfigurePolicy(accessPolicy)
+}
+
+// loadPolicy read the access policy from --policy-file or fall back to the built in policy
+func loadPolicy() (*policy.Policy, error) {
+	if *policyFile == "" {
+		return policy.Parse([]byte(policy.DefaultPolicy))
+	}
+	return policy.Load(*policyFile)
+}
+
+// mailer build the notifier used for account emails, without --smtp-addr mail is written to --mail-file or the log
+func mailer() notify.Notifier {
+	switch {
+	case *smtpAddr != "":
+		return notify.NewSMTPNotifier(*smtpAddr, *smtpFrom, *smtpUser, *smtpPassword)
+	case *mailFile != "":
+		return notify.NewFileNotifier(*mailFile)
+	default:
+		return &notify.LogNotifier{}
+	}
+}
+
+// checkNotifier build the notifier used for blazer check state changes from the command line options
+func checkNotifier() notify.Notifier {
+	var notifiers notify.Multi
+	if *smtpAddr != "" {
+		notifiers = append(notifiers, notify.NewSMTPNotifier(*smtpAddr, *smtpFrom, *smtpUser, *smtpPassword))
+	}
+
+	if *checkWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(*checkWebhookURL))
+	}
+
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
+
+// BlazerCheckScheduler launch the blazer check scheduler, it stops when ctx is cancelled
+func BlazerCheckScheduler(ctx context.Context) {
+	scheduler := blazer.NewScheduler(checkNotifier())
+
+	var err error
+	if scheduler.Interval, err = time.ParseDuration(*checkScanInterval); err != nil {
+		log.Fatalf("Invalid --check-scan-interval '%s', the error is '%v'", *checkScanInterval, err)
+	}
+
+	if scheduler.Timeout, err = time.ParseDuration(*checkQueryTimeout); err != nil {
+		log.Fatalf("Invalid --check-query-timeout '%s', the error is '%v'", *checkQueryTimeout, err)
+	}
+
+	scheduler.Run(ctx)
+}
+
+// ConfigureSoftDelete enable soft delete for the --soft-delete tables, tables whose deleted_at column was not migrated yet
+// keep deleting for good
+func ConfigureSoftDelete(db *gorm.DB) {
+	var tables []string
+	for _, table := range strings.Split(*softDelete, ",") {
+		if table = strings.TrimSpace(table); table == "*" {
+			tables = append(tables, dao.SoftDeletable()...)
+		} else if table != "" {
+			tables = append(tables, table)
+		}
+	}
+
+	enabled, err := dao.ConfigureSoftDelete(db, tables)
+	if err != nil {
+		log.Fatalf("Invalid --soft-delete '%s', the error is '%v'", *softDelete, err)
+	}
+
+	if len(enabled) < len(tables) {
+		log.Printf("Soft delete is only enabled for %v, the other --soft-delete tables have no deleted_at column", enabled)
+	}
+}
+
+// TrashPurger permanently delete soft deleted records older than --trash-retention, it stops when ctx is cancelled
+func TrashPurger(ctx context.Context) {
+	retention, err := time.ParseDuration(*trashRetention)
+	if err != nil {
+		log.Fatalf("Invalid --trash-retention '%s', the error is '%v'", *trashRetention, err)
+	}
+
+	interval, err := time.ParseDuration(*trashPurgeEvery)
+	if err != nil || interval <= 0 {
+		log.Fatalf("Invalid --trash-purge-interval '%s', the error is '%v'", *trashPurgeEvery, err)
+	}
+
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := dao.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Purging soft deleted records failed, the error is '%v'", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d soft deleted records", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// webhookDispatcher build the dispatcher sending webhook deliveries from the command line options, nil with --no-webhooks
+func webhookDispatcher() *webhook.Dispatcher {
+	if *disableWebhooks {
+		return nil
+	}
+
+	dispatcher := webhook.NewDispatcher()
+
+	var err error
+	if dispatcher.Interval, err = time.ParseDuration(*webhookInterval); err != nil || dispatcher.Interval <= 0 {
+		log.Fatalf("Invalid --webhook-scan-interval '%s', the error is '%v'", *webhookInterval, err)
+	}
+	return dispatcher
+}
+
+// GinServer build the server of the rest api from the command line options, it is started by Serve
+func GinServer() *http.Server {
 	url := ginSwagger.URL("https://xinqi.dev:443/swagger/doc.json") // The url pointing to API definition
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(api.GinRequestLog(), gin.Recovery(), api.GinMetrics())
+	router.Use(api.GinRateLimit())
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 
 	api.ConfigGinRouter(router)
-	router.Run(":8080")
+
+	return &http.Server{
+		Addr:         *httpAddr,
+		Handler:      router,
+		ReadTimeout:  parseTimeout("--read-timeout", *readTimeout),
+		WriteTimeout: parseTimeout("--write-timeout", *writeTimeout),
+		IdleTimeout:  parseTimeout("--idle-timeout", *idleTimeout),
+	}
+}
+
+// GRPCServer build the server of the grpc services of the tables, it is started by Serve. With tlsConfig the services are
+// served over https with the certificate and client authorities of the rest api, otherwise over cleartext http/2.
+// The h2c connections are hijacked, a read or write deadline would outlive the request that set it, only reading the
+// headers of the upgrade is limited and idle connections are closed by http/2.
+func GRPCServer(addr string, tlsConfig *tls.Config) *http.Server {
+	handler := api.LogRequests(api.GRPCServer())
+	if tlsConfig != nil {
+		return &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: parseTimeout("--read-timeout", *readTimeout),
+			IdleTimeout:       parseTimeout("--idle-timeout", *idleTimeout),
+		}
+	}
+
+	h2s := &http2.Server{IdleTimeout: parseTimeout("--idle-timeout", *idleTimeout)}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           h2c.NewHandler(handler, h2s),
+		ReadHeaderTimeout: parseTimeout("--read-timeout", *readTimeout),
+	}
+}
+
+// parseTimeout parse the duration of a timeout option, exiting when it is invalid
+func parseTimeout(option, value string) time.Duration {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Fatalf("Invalid %s '%s', the error is '%v'", option, value, err)
+	}
+	return timeout
+}
+
+// ConfigureTLS serve server over https with the certificate of the command line options, the reloader is nil without
+// --tls-cert and --tls-key
+func ConfigureTLS(server *http.Server) *tlsconfig.Reloader {
+	if *tlsCert == "" && *tlsKey == "" {
+		if *tlsClientCA != "" || *redirectAddr != "" {
+			log.Fatalf("--tls-client-ca and --http-redirect-addr need --tls-cert and --tls-key")
+		}
+		return nil
+	}
+
+	reloader, err := tlsconfig.New(tlsconfig.Config{
+		CertFile:          *tlsCert,
+		KeyFile:           *tlsKey,
+		ClientCAFile:      *tlsClientCA,
+		RequireClientCert: *tlsRequireCert,
+	})
+	if err != nil {
+		log.Fatalf("Unable to load the tls certificate, the error is '%v'", err)
+	}
+
+	server.TLSConfig = reloader.TLSConfig()
+	return reloader
+}
+
+// RedirectServer build the server redirecting plain http requests to the https address of server, nil without
+// --http-redirect-addr
+func RedirectServer(server *http.Server) *http.Server {
+	if *redirectAddr == "" {
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(server.Addr)
+	if err != nil {
+		log.Fatalf("Invalid --http-addr '%s', the error is '%v'", server.Addr, err)
+	}
+
+	return &http.Server{
+		Addr:         *redirectAddr,
+		Handler:      tlsconfig.RedirectHandler(port),
+		ReadTimeout:  parseTimeout("--read-timeout", *readTimeout),
+		WriteTimeout: parseTimeout("--write-timeout", *writeTimeout),
+		IdleTimeout:  parseTimeout("--idle-timeout", *idleTimeout),
+	}
+}
+
+// MetricsServer build the server of /metrics for scrapers, nil without --metrics-addr. It does not authenticate, the address
+// should only be reachable from the monitoring network.
+func MetricsServer() *http.Server {
+	if *metricsAddr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+	return &http.Server{
+		Addr:         *metricsAddr,
+		Handler:      mux,
+		ReadTimeout:  parseTimeout("--read-timeout", *readTimeout),
+		WriteTimeout: parseTimeout("--write-timeout", *writeTimeout),
+		IdleTimeout:  parseTimeout("--idle-timeout", *idleTimeout),
+	}
+}
+
+// Serve bind the address of server, exiting when it cannot be bound, and serve it in the background.
+// The error ending Serve is sent on errs unless the server was shut down.
+func Serve(name string, server *http.Server, errs chan<- error) {
+	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
-		log.Fatalf("Error starting server, the error is '%v'", err)
+		log.Fatalf("Unable to listen on %s for the %s server, the error is '%v'", server.Addr, name, err)
+	}
+	log.Printf("Serving %s on %s", name, listener.Addr())
+
+	go func() {
+		serve := server.Serve
+		if server.TLSConfig != nil {
+			// the certificate comes from TLSConfig
+			serve = func(listener net.Listener) error { return server.ServeTLS(listener, "", "") }
+		}
+
+		if err := serve(listener); err != nil && err != http.ErrServerClosed {
+			errs <- fmt.Errorf("%s server failed: %v", name, err)
+		}
+	}()
+}
+
+// Shutdown stop accepting connections and drain the in-flight requests of servers until ctx is done, the connections still
+// busy are closed then
+func Shutdown(ctx context.Context, servers ...*http.Server) {
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("Draining requests on %s did not complete, the error is '%v'", server.Addr, err)
+				server.Close()
+			}
+		}(server)
 	}
+	wg.Wait()
+}
 
-	return
+// RunJob run job in the background until ctx is cancelled, jobs is done once it returned
+func RunJob(ctx context.Context, jobs *sync.WaitGroup, job func(ctx context.Context)) {
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		job(ctx)
+	}()
+}
+
+// waitJobs wait for the background jobs to return, at most until ctx is done
+func waitJobs(ctx context.Context, jobs *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("Background jobs did not stop in time")
+	}
 }
 
 // @title Sample CRUD api for rocket_development db
@@ -92,56 +703,172 @@ func main() {
 `, BuildDate, BuildNumber, LatestCommit, RuntimeVer, BuiltOnOs)
 	goopt.Parse(nil)
 
+	log.SetFlags(0)
+	log.SetOutput(logging.Default.Writer(logging.LevelInfo))
+	gin.DefaultWriter = logging.Default.Writer(logging.LevelDebug)
+	gin.DefaultErrorWriter = logging.Default.Writer(logging.LevelError)
+
 	db, err := gorm.Open("mysql", "doadmin:AVNS_nQrjtn8ilVHqYs6xIim@tcp(dbaas-db-7154856-do-user-13260059-0.b.db.ondigitalocean.com:25060)/rocket_development?parseTime=true")
 	if err != nil {
 		log.Fatalf("Got error when connect database, the error is '%v'", err)
 	}
 
-	db.LogMode(true)
+	db.SetLogger(dao.GormLogger{})
 	dao.DB = db
+	dao.InstrumentQueries(db)
+	slowThreshold, err := time.ParseDuration(*slowQuery)
+	if err != nil {
+		log.Fatalf("Invalid --slow-query-threshold '%s', the error is '%v'", *slowQuery, err)
+	}
+	dao.Logger = dao.LogQueries(slowThreshold, *logSQL)
 
-	db.AutoMigrate(
-		&model.ActiveAdminComments{},
-		&model.ActiveStorageAttachments{},
-		&model.ActiveStorageBlobs{},
-		&model.Addresses{},
-		&model.AdminUsers{},
-		&model.ArInternalMetadata{},
-		&model.Batteries{},
-		&model.BlazerAudits{},
-		&model.BlazerChecks{},
-		&model.BlazerDashboardQueries{},
-		&model.BlazerDashboards{},
-		&model.BlazerQueries{},
-		&model.BuildingDetails{},
-		&model.Buildings{},
-		&model.Columns{},
-		&model.Customers{},
-		&model.Elevators{},
-		&model.Employees{},
-		&model.Interventions{},
-		&model.Leads{},
-		&model.Maps{},
-		&model.Quotes{},
-		&model.SchemaMigrations{},
-		&model.Users_{},
-	)
+	dao.AppBuildInfo = &dao.BuildInfo{
+		BuildDate:    BuildDate,
+		LatestCommit: LatestCommit,
+		BuildNumber:  BuildNumber,
+		BuiltOnIP:    BuiltOnIP,
+		BuiltOnOs:    BuiltOnOs,
+		RuntimeVer:   RuntimeVer,
+	}
+
+	if len(goopt.Args) > 0 && goopt.Args[0] == "migrate" {
+		err := RunMigrate(db, goopt.Args[1:])
+		db.Close()
+		if err != nil {
+			log.Fatalf("Migrating failed, the error is '%v'", err)
+		}
+		return
+	}
+
+	var schemaErr error
+	if *autoMigrate {
+		if err := AutoMigrate(db); err != nil {
+			log.Printf("Migrating the schema failed, the error is '%v'", err)
+			schemaErr = fmt.Errorf("automigrate failed: %v", err)
+		}
+	}
+
+	// the models carry the columns of every migration, the server starts anyway but is not ready until they are applied
+	migrator, err := Migrator(db)
+	if err != nil {
+		log.Printf("%v, /readyz fails until the migrations are found", err)
+		schemaErr = err
+	} else if pending, err := migrator.Pending(); err == nil && len(pending) > 0 {
+		log.Printf("%d migrations are pending, /readyz fails until they are applied with '%s migrate up'", len(pending), os.Args[0])
+	}
+	ConfigureSoftDelete(db)
+
+	var recorders []dao.ChangeRecorderFunc
+	if !*disableAudit {
+		recorders = append(recorders, dao.AuditChange)
+	}
+
+	dispatcher := webhookDispatcher()
+	if dispatcher != nil {
+		recorders = append(recorders, dispatcher.RecordChange)
+		api.ConfigureWebhooks(dispatcher)
+	}
+
+	var broker *stream.Broker
+	if *eventBuffer > 0 {
+		broker = stream.NewBroker(*eventBuffer)
+		recorders = append(recorders, broker.RecordChange)
+		api.ConfigureEvents(broker)
+	}
+
+	if len(recorders) > 0 {
+		dao.ChangeRecorder = dao.ChangeRecorders(recorders...)
+	}
+
+	ConfigureAuth()
+	api.ConfigureOpenAPI(*publicURL)
+	ConfigureTrustedProxies()
+	ConfigureRateLimit()
+	ConfigureReadiness(migrator, schemaErr)
+	if !*noDriftCheck {
+		CheckSchemaDrift()
+	}
+
+	drain := parseTimeout("--shutdown-timeout", *shutdownTimeout)
+	jobsDrain := parseTimeout("--jobs-shutdown-timeout", *jobsTimeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	if *blazerChecks {
+		RunJob(ctx, &jobs, BlazerCheckScheduler)
+	}
+	RunJob(ctx, &jobs, TrashPurger)
+	if dispatcher != nil {
+		RunJob(ctx, &jobs, dispatcher.Run)
+	}
 
-	dao.Logger = func(ctx context.Context, sql string) {
-		fmt.Printf("SQL: %s\n", sql)
+	errs := make(chan error, 4)
+	server := GinServer()
+	if broker != nil {
+		// event streams never finish on their own, they are ended so the drain does not wait for them
+		server.RegisterOnShutdown(broker.Close)
 	}
+	servers := []*http.Server{server}
+	if reloader := ConfigureTLS(server); reloader != nil {
+		interval := parseTimeout("--tls-reload-interval", *tlsReload)
+		RunJob(ctx, &jobs, func(ctx context.Context) { reloader.Run(ctx, interval) })
+		Serve("https", server, errs)
+	} else {
+		Serve("http", server, errs)
+	}
+
+	if redirect := RedirectServer(server); redirect != nil {
+		servers = append(servers, redirect)
+		Serve("redirect", redirect, errs)
+	}
+
+	if *grpcAddr != "" {
+		if server.TLSConfig == nil {
+			log.Printf("Serving grpc over cleartext http/2, credentials are sent unencrypted without --tls-cert")
+		}
+		grpcServer := GRPCServer(*grpcAddr, server.TLSConfig.Clone())
+		servers = append(servers, grpcServer)
+		Serve("grpc", grpcServer, errs)
+	}
+
+	if metricsServer := MetricsServer(); metricsServer != nil {
+		servers = append(servers, metricsServer)
+		Serve("metrics", metricsServer, errs)
+	}
+
+	failed := LoopForever(errs)
 
-	go GinServer()
-	LoopForever()
+	shutdownCtx, stop := context.WithTimeout(context.Background(), drain)
+	Shutdown(shutdownCtx, servers...)
+	stop()
+
+	// the jobs get a deadline of their own, a drain using up --shutdown-timeout must not cut them short
+	cancel()
+	jobsCtx, stopJobs := context.WithTimeout(context.Background(), jobsDrain)
+	waitJobs(jobsCtx, &jobs)
+	stopJobs()
+
+	if err := db.Close(); err != nil {
+		log.Printf("Closing the database failed, the error is '%v'", err)
+	}
+	log.Printf("Shutdown complete")
+
+	if failed != nil {
+		os.Exit(1)
+	}
 }
 
-// LoopForever on signal processing
-func LoopForever() {
-	fmt.Printf("Entering infinite loop\n")
+// LoopForever wait for a signal or for a server to fail, err is the error of the failed server
+func LoopForever(errs <-chan error) (err error) {
+	log.Printf("Entering infinite loop")
 
 	signal.Notify(OsSignal, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
-	_ = <-OsSignal
-
-	fmt.Printf("Exiting infinite loop received OsSignal\n")
+	select {
+	case sig := <-OsSignal:
+		log.Printf("Exiting infinite loop received OsSignal %v", sig)
+	case err = <-errs:
+		log.Printf("Exiting infinite loop, %v", err)
+	}
 
+	return err
 }
@@ -0,0 +1,95 @@
+package blazer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StateNew state of a check that has never been run
+	StateNew = "new"
+
+	// StatePassing state of a check whose last run found no problem
+	StatePassing = "passing"
+
+	// StateFailing state of a check whose last run found bad or missing data
+	StateFailing = "failing"
+
+	// StateError state of a check whose query failed to execute
+	StateError = "error"
+
+	// StateTimedOut state of a check whose query exceeded the run timeout
+	StateTimedOut = "timed out"
+
+	// CheckTypeBadData check fails when the query returns any rows
+	CheckTypeBadData = "bad_data"
+
+	// CheckTypeMissingData check fails when the query returns no rows
+	CheckTypeMissingData = "missing_data"
+)
+
+// Evaluate returns the state of a check from the number of rows its query returned.
+// An empty check type is treated as bad_data, matching checks created before blazer added missing_data.
+func Evaluate(checkType string, rowCount int) (string, error) {
+	switch checkType {
+	case "", CheckTypeBadData:
+		if rowCount > 0 {
+			return StateFailing, nil
+		}
+		return StatePassing, nil
+	case CheckTypeMissingData:
+		if rowCount == 0 {
+			return StateFailing, nil
+		}
+		return StatePassing, nil
+	default:
+		return "", fmt.Errorf("unknown check type: %s", checkType)
+	}
+}
+
+// ShouldNotify reports if a state transition is worth notifying about.
+// A brand new check that passes on its first run is not reported, every other change of state is.
+func ShouldNotify(previous, current string) bool {
+	if previous == "" {
+		previous = StateNew
+	}
+
+	if previous == current {
+		return false
+	}
+
+	return previous != StateNew || current != StatePassing
+}
+
+// ParseSchedule converts a blazer schedule such as "5 minutes", "1 hour" or "1 day" into an interval
+func ParseSchedule(schedule string) (time.Duration, error) {
+	fields := strings.Fields(strings.ToLower(schedule))
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("invalid schedule: %q", schedule)
+	}
+
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid schedule: %q", schedule)
+	}
+
+	var unit time.Duration
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "second":
+		unit = time.Second
+	case "minute":
+		unit = time.Minute
+	case "hour":
+		unit = time.Hour
+	case "day":
+		unit = 24 * time.Hour
+	case "week":
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid schedule unit: %q", schedule)
+	}
+
+	return time.Duration(n) * unit, nil
+}
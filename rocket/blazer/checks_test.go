@@ -0,0 +1,91 @@
+package blazer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		checkType string
+		rows      int
+		want      string
+	}{
+		{"", 0, StatePassing},
+		{"", 3, StateFailing},
+		{CheckTypeBadData, 0, StatePassing},
+		{CheckTypeBadData, 1, StateFailing},
+		{CheckTypeMissingData, 0, StateFailing},
+		{CheckTypeMissingData, 2, StatePassing},
+	}
+
+	for _, tt := range tests {
+		got, err := Evaluate(tt.checkType, tt.rows)
+		if err != nil {
+			t.Errorf("Evaluate(%q, %d) returned error %v", tt.checkType, tt.rows, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%q, %d) = %q, want %q", tt.checkType, tt.rows, got, tt.want)
+		}
+	}
+
+	if _, err := Evaluate("anomaly", 1); err == nil {
+		t.Error("Evaluate of an unknown check type returned no error")
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	tests := []struct {
+		previous, current string
+		want              bool
+	}{
+		{"", StatePassing, false},
+		{StateNew, StatePassing, false},
+		{"", StateFailing, true},
+		{StateNew, StateError, true},
+		{StatePassing, StatePassing, false},
+		{StateFailing, StateFailing, false},
+		{StatePassing, StateFailing, true},
+		{StateFailing, StatePassing, true},
+		{StateError, StateTimedOut, true},
+	}
+
+	for _, tt := range tests {
+		if got := ShouldNotify(tt.previous, tt.current); got != tt.want {
+			t.Errorf("ShouldNotify(%q, %q) = %v, want %v", tt.previous, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     time.Duration
+	}{
+		{"30 seconds", 30 * time.Second},
+		{"1 minute", time.Minute},
+		{"5 minutes", 5 * time.Minute},
+		{"1 Hour", time.Hour},
+		{" 2  hours ", 2 * time.Hour},
+		{"1 day", 24 * time.Hour},
+		{"2 weeks", 14 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) returned error %v", tt.schedule, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSchedule(%q) = %v, want %v", tt.schedule, got, tt.want)
+		}
+	}
+
+	for _, schedule := range []string{"", "5", "minutes", "0 minutes", "-1 hour", "x hours", "5 fortnights", "1 hour daily"} {
+		if _, err := ParseSchedule(schedule); err == nil {
+			t.Errorf("ParseSchedule(%q) returned no error", schedule)
+		}
+	}
+}
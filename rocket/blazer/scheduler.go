@@ -0,0 +1,194 @@
+package blazer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+	"rocket/notify"
+)
+
+// Scheduler runs the queries behind blazer_checks on their schedule and notifies recipients when a check changes state
+type Scheduler struct {
+	// Notifier receives a message for every state change, nil disables notifications
+	Notifier notify.Notifier
+
+	// Interval how often the blazer_checks table is scanned for due checks
+	Interval time.Duration
+
+	// Timeout maximum time a single check query may run before it is marked timed out
+	Timeout time.Duration
+
+	// Now returns the current time, replaceable for deterministic runs
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewScheduler create a Scheduler with a one minute scan interval and a one minute query timeout
+func NewScheduler(notifier notify.Notifier) *Scheduler {
+	return &Scheduler{
+		Notifier: notifier,
+		Interval: time.Minute,
+		Timeout:  time.Minute,
+		Now:      time.Now,
+	}
+}
+
+// Run scans for due checks every Interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(ctx); err != nil {
+			log.Printf("blazer checks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every scheduled check whose interval has elapsed since its last run
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	// a slow scan must not overlap with the next tick
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checks, err := dao.GetScheduledBlazerChecks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.Now()
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		interval, err := ParseSchedule(check.Schedule.String)
+		if err != nil {
+			log.Printf("blazer check %d: %v", check.ID, err)
+			continue
+		}
+
+		if check.LastRunAt.Valid && check.LastRunAt.Time.Add(interval).After(now) {
+			continue
+		}
+
+		if err := s.RunCheck(ctx, check); err != nil {
+			log.Printf("blazer check %d: %v", check.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunCheck executes the query of a check, stores the resulting state and sends a notification on state change
+func (s *Scheduler) RunCheck(ctx context.Context, check *model.BlazerChecks) error {
+	if !check.QueryID.Valid {
+		return fmt.Errorf("check has no query")
+	}
+
+	query, err := dao.GetBlazerQueries(ctx, check.QueryID.Int64)
+	if err != nil {
+		return fmt.Errorf("query %d: %v", check.QueryID.Int64, err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	var (
+		state   string
+		message string
+		rows    int
+	)
+
+	result, err := dao.ExecuteStatement(runCtx, query.Statement.String, 0)
+	switch {
+	case err == context.DeadlineExceeded:
+		state, message = StateTimedOut, fmt.Sprintf("query exceeded timeout of %s", s.Timeout)
+	case err != nil:
+		state, message = StateError, err.Error()
+	default:
+		rows = len(result.Rows)
+		if state, err = Evaluate(check.CheckType.String, rows); err != nil {
+			state, message = StateError, err.Error()
+		}
+	}
+
+	ranAt := s.Now()
+	if err := dao.RecordBlazerCheckRun(ctx, check.ID, state, message, ranAt); err != nil {
+		return err
+	}
+
+	previous := check.State.String
+	check.State.SetValid(state)
+	check.LastRunAt.SetValid(ranAt)
+
+	if s.Notifier == nil || !ShouldNotify(previous, state) {
+		return nil
+	}
+
+	msg := stateChangeMessage(check, query, previous, state, message, rows)
+	if len(msg.To) == 0 && len(msg.Channels) == 0 {
+		return nil
+	}
+
+	return s.Notifier.Notify(ctx, msg)
+}
+
+func stateChangeMessage(check *model.BlazerChecks, query *model.BlazerQueries, previous, state, message string, rows int) *notify.Message {
+	name := query.Name.String
+	if name == "" {
+		name = fmt.Sprintf("query %d", query.ID)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Check %d on %s is now %s (was %s).\n", check.ID, name, state, previousOrNew(previous))
+	fmt.Fprintf(&body, "Check type: %s\n", checkTypeOrDefault(check.CheckType.String))
+	fmt.Fprintf(&body, "Rows returned: %d\n", rows)
+	if message != "" {
+		fmt.Fprintf(&body, "Message: %s\n", message)
+	}
+
+	return &notify.Message{
+		To:       notify.SplitRecipients(check.Emails.String),
+		Channels: notify.SplitRecipients(check.SlackChannels.String),
+		Subject:  fmt.Sprintf("Check %s: %s", strings.ToUpper(state[:1])+state[1:], name),
+		Body:     body.String(),
+		Fields: map[string]interface{}{
+			"check_id":       check.ID,
+			"query_id":       query.ID,
+			"query_name":     name,
+			"check_type":     checkTypeOrDefault(check.CheckType.String),
+			"state":          state,
+			"previous_state": previousOrNew(previous),
+			"message":        message,
+			"rows":           rows,
+		},
+	}
+}
+
+func previousOrNew(state string) string {
+	if state == "" {
+		return StateNew
+	}
+	return state
+}
+
+func checkTypeOrDefault(checkType string) string {
+	if checkType == "" {
+		return CheckTypeBadData
+	}
+	return checkType
+}
@@ -0,0 +1,242 @@
+package blazer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rocket/dao"
+	"rocket/dao/daotest"
+	"rocket/model"
+	"rocket/notify"
+
+	"github.com/guregu/null"
+)
+
+// openTestDB point dao.DB at a sqlite database holding the blazer tables and a widgets table the checks query
+func openTestDB(t *testing.T) {
+	db := daotest.Open(t, "CREATE TABLE widgets (id integer primary key, broken integer)")
+	if err := db.AutoMigrate(&model.BlazerChecks{}, &model.BlazerQueries{}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// smtpStandIn a local smtp server accepting every message, the DATA of each message is sent to the returned channel
+func smtpStandIn(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			messages <- data.String()
+			reply("250 queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// webhookStandIn a local http server decoding every posted message to the returned channel
+func webhookStandIn(t *testing.T) (string, <-chan *notify.Message) {
+	messages := make(chan *notify.Message, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &notify.Message{}
+		if err := json.NewDecoder(r.Body).Decode(msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		messages <- msg
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, messages
+}
+
+func TestSchedulerNotifiesStateChanges(t *testing.T) {
+	openTestDB(t)
+	ctx := context.Background()
+
+	smtpAddr, mails := smtpStandIn(t)
+	webhookURL, posts := webhookStandIn(t)
+
+	dao.DB.Create(&model.BlazerQueries{ID: 1, Name: null.StringFrom("Broken widgets"), Statement: null.StringFrom("SELECT id FROM widgets WHERE broken = 1")})
+	dao.DB.Create(&model.BlazerChecks{ID: 1, QueryID: null.IntFrom(1), Schedule: null.StringFrom("5 minutes"), CheckType: null.StringFrom(CheckTypeBadData),
+		Emails: null.StringFrom("ops@example.com, dev@example.com"), SlackChannels: null.StringFrom("#alerts")})
+
+	now := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	s := NewScheduler(notify.Multi{notify.NewSMTPNotifier(smtpAddr, "rocket@example.com", "", ""), notify.NewWebhookNotifier(webhookURL)})
+	s.Now = func() time.Time { return now }
+
+	run := func(wantState string) {
+		t.Helper()
+		if err := s.RunDue(ctx); err != nil {
+			t.Fatalf("RunDue returned %v", err)
+		}
+
+		check := &model.BlazerChecks{}
+		dao.DB.First(check, 1)
+		if check.State.String != wantState {
+			t.Fatalf("check state = %q, want %q", check.State.String, wantState)
+		}
+	}
+
+	noNotification := func() {
+		t.Helper()
+		select {
+		case mail := <-mails:
+			t.Fatalf("unexpected mail %q", mail)
+		case post := <-posts:
+			t.Fatalf("unexpected webhook post %+v", post)
+		default:
+		}
+	}
+
+	// a new check passing on its first run is not reported
+	run(StatePassing)
+	noNotification()
+
+	// not due before its schedule elapsed
+	dao.DB.Exec("INSERT INTO widgets (id, broken) VALUES (1, 1)")
+	now = now.Add(time.Minute)
+	run(StatePassing)
+
+	now = now.Add(5 * time.Minute)
+	run(StateFailing)
+
+	select {
+	case mail := <-mails:
+		for _, want := range []string{"To: ops@example.com, dev@example.com", "Subject: Check Failing: Broken widgets", "is now failing (was passing)", "Rows returned: 1"} {
+			if !strings.Contains(mail, want) {
+				t.Errorf("mail %q does not contain %q", mail, want)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail was sent when the check started failing")
+	}
+
+	select {
+	case post := <-posts:
+		if post.Fields["state"] != StateFailing || post.Fields["previous_state"] != StatePassing {
+			t.Errorf("webhook fields = %v, want failing after passing", post.Fields)
+		}
+		if len(post.Channels) != 1 || post.Channels[0] != "#alerts" {
+			t.Errorf("webhook channels = %v, want [#alerts]", post.Channels)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook was posted when the check started failing")
+	}
+
+	// still failing, nothing changed
+	now = now.Add(5 * time.Minute)
+	run(StateFailing)
+	noNotification()
+
+	dao.DB.Exec("UPDATE widgets SET broken = 0")
+	now = now.Add(5 * time.Minute)
+	run(StatePassing)
+
+	select {
+	case post := <-posts:
+		if post.Fields["state"] != StatePassing || post.Fields["previous_state"] != StateFailing {
+			t.Errorf("webhook fields = %v, want passing after failing", post.Fields)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook was posted when the check recovered")
+	}
+	<-mails
+}
+
+func TestSchedulerMissingDataAndErrors(t *testing.T) {
+	openTestDB(t)
+	ctx := context.Background()
+
+	var sent []*notify.Message
+	s := NewScheduler(notify.NotifierFunc(func(ctx context.Context, msg *notify.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}))
+
+	dao.DB.Create(&model.BlazerQueries{ID: 1, Statement: null.StringFrom("SELECT id FROM widgets")})
+	dao.DB.Create(&model.BlazerQueries{ID: 2, Statement: null.StringFrom("SELECT id FROM gadgets")})
+	missing := &model.BlazerChecks{ID: 1, QueryID: null.IntFrom(1), CheckType: null.StringFrom(CheckTypeMissingData), Emails: null.StringFrom("ops@example.com")}
+	broken := &model.BlazerChecks{ID: 2, QueryID: null.IntFrom(2), Emails: null.StringFrom("ops@example.com")}
+
+	if err := s.RunCheck(ctx, missing); err != nil {
+		t.Fatal(err)
+	}
+	if missing.State.String != StateFailing {
+		t.Errorf("missing_data check on an empty table is %q, want %q", missing.State.String, StateFailing)
+	}
+
+	dao.DB.Exec("INSERT INTO widgets (id, broken) VALUES (1, 0)")
+	if err := s.RunCheck(ctx, missing); err != nil {
+		t.Fatal(err)
+	}
+	if missing.State.String != StatePassing {
+		t.Errorf("missing_data check with rows is %q, want %q", missing.State.String, StatePassing)
+	}
+
+	if err := s.RunCheck(ctx, broken); err != nil {
+		t.Fatal(err)
+	}
+	if broken.State.String != StateError {
+		t.Errorf("check of a failing query is %q, want %q", broken.State.String, StateError)
+	}
+
+	if len(sent) != 3 {
+		t.Fatalf("%d notifications were sent, want 3", len(sent))
+	}
+	if sent[2].Fields["message"] == "" {
+		t.Error("the error notification does not carry the query error")
+	}
+}
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"rocket/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetUsersByEmail is a function to get a single record from the users table in the rocket_development database by email, emails are matched case insensitive as devise stores them downcased
+// error - ErrNotFound, db Find error
+func GetUsersByEmail(ctx context.Context, email string) (record *model.Users_, err error) {
+	record = &model.Users_{}
+	if err = contextDB(ctx).Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// GetAdminUsersByEmail is a function to get a single record from the admin_users table in the rocket_development database by email, emails are matched case insensitive as devise stores them downcased
+// error - ErrNotFound, db Find error
+func GetAdminUsersByEmail(ctx context.Context, email string) (record *model.AdminUsers, err error) {
+	record = &model.AdminUsers{}
+	if err = contextDB(ctx).Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// GetEmployeesByUserID is a function to get the employees record linked to a users account
+// error - ErrNotFound, db Find error
+func GetEmployeesByUserID(ctx context.Context, userID int64) (record *model.Employees, err error) {
+	record = &model.Employees{}
+	if err = contextDB(ctx).Where("user_id = ?", userID).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// GetUsersByResetPasswordToken is a function to get the users account a password reset token digest was issued to
+// error - ErrNotFound, db Find error
+func GetUsersByResetPasswordToken(ctx context.Context, digest string) (record *model.Users_, err error) {
+	record = &model.Users_{}
+	if err = contextDB(ctx).Where("reset_password_token = ?", digest).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// GetAdminUsersByResetPasswordToken is a function to get the admin_users account a password reset token digest was issued to
+// error - ErrNotFound, db Find error
+func GetAdminUsersByResetPasswordToken(ctx context.Context, digest string) (record *model.AdminUsers, err error) {
+	record = &model.AdminUsers{}
+	if err = contextDB(ctx).Where("reset_password_token = ?", digest).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// SetUsersResetPasswordToken is a function to store the digest of a password reset token and the time it was sent on a users account, replacing any earlier token
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db update failed
+func SetUsersResetPasswordToken(ctx context.Context, argID int64, digest string, sentAt time.Time) (err error) {
+	return setResetPasswordToken(ctx, &model.Users_{}, argID, digest, sentAt)
+}
+
+// SetAdminUsersResetPasswordToken is a function to store the digest of a password reset token and the time it was sent on an admin_users account, replacing any earlier token
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db update failed
+func SetAdminUsersResetPasswordToken(ctx context.Context, argID int64, digest string, sentAt time.Time) (err error) {
+	return setResetPasswordToken(ctx, &model.AdminUsers{}, argID, digest, sentAt)
+}
+
+// ResetUsersPassword is a function to replace the encrypted_password of a users account and consume its reset token.
+// The update only applies while the account still holds digest so a token can not be used twice.
+// error - ErrNotFound, the token was already used or replaced
+// error - ErrUpdateFailed, db update failed
+func ResetUsersPassword(ctx context.Context, argID int64, digest, encryptedPassword string) (err error) {
+	return resetPassword(ctx, &model.Users_{}, argID, digest, encryptedPassword)
+}
+
+// ResetAdminUsersPassword is a function to replace the encrypted_password of an admin_users account and consume its reset token.
+// The update only applies while the account still holds digest so a token can not be used twice.
+// error - ErrNotFound, the token was already used or replaced
+// error - ErrUpdateFailed, db update failed
+func ResetAdminUsersPassword(ctx context.Context, argID int64, digest, encryptedPassword string) (err error) {
+	return resetPassword(ctx, &model.AdminUsers{}, argID, digest, encryptedPassword)
+}
+
+func setResetPasswordToken(ctx context.Context, record model.Model, argID int64, digest string, sentAt time.Time) error {
+	return updateAccount(ctx, record, argID, contextDB(ctx), map[string]interface{}{
+		"reset_password_token":   digest,
+		"reset_password_sent_at": sentAt,
+	})
+}
+
+func resetPassword(ctx context.Context, record model.Model, argID int64, digest, encryptedPassword string) error {
+	return updateAccount(ctx, record, argID, contextDB(ctx).Where("reset_password_token = ?", digest), map[string]interface{}{
+		"encrypted_password":     encryptedPassword,
+		"reset_password_token":   gorm.Expr("NULL"),
+		"reset_password_sent_at": gorm.Expr("NULL"),
+	})
+}
+
+// updateAccount apply columns to the account argID matching scope and record the change
+// error - ErrNotFound, no account matches argID and scope
+// error - ErrUpdateFailed, db update failed
+func updateAccount(ctx context.Context, record model.Model, argID int64, scope *gorm.DB, columns map[string]interface{}) error {
+	if err := scope.First(record, argID).Error; err != nil {
+		return ErrNotFound
+	}
+	before := cloneRecord(record)
+
+	db := scope.Model(record).Updates(columns)
+	if db.Error != nil {
+		return ErrUpdateFailed
+	}
+
+	if db.RowsAffected != 1 {
+		// changed by a concurrent request after it was read
+		return ErrNotFound
+	}
+
+	if err := contextDB(ctx).First(record, argID).Error; err == nil {
+		recordChange(ctx, record.TableName(), model.Update, before, record)
+	}
+	return nil
+}
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllActiveAdminComments(ctx context.Context, page, pagesize int64, order string) (results []*model.ActiveAdminComments, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.ActiveAdminComments{})
+	resultOrm := scopeQuery(ctx, "active_admin_comments", model.RetrieveMany, contextDB(ctx).Model(&model.ActiveAdminComments{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllActiveAdminComments(ctx context.Context, page, pagesize int64, order
 // error - ErrNotFound, db Find error
 func GetActiveAdminComments(ctx context.Context, argID int64) (record *model.ActiveAdminComments, err error) {
 	record = &model.ActiveAdminComments{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "active_admin_comments", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddActiveAdminComments is a function to add a single record to active_admin_comments table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddActiveAdminComments(ctx context.Context, record *model.ActiveAdminComments) (result *model.ActiveAdminComments, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "active_admin_comments", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "active_admin_comments", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddActiveAdminComments(ctx context.Context, record *model.ActiveAdminCommen
 func UpdateActiveAdminComments(ctx context.Context, argID int64, updated *model.ActiveAdminComments) (result *model.ActiveAdminComments, RowsAffected int64, err error) {
 
 	result = &model.ActiveAdminComments{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "active_admin_comments", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "active_admin_comments", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "active_admin_comments", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateActiveAdminComments(ctx context.Context, argID int64, updated *model.
 func DeleteActiveAdminComments(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.ActiveAdminComments{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "active_admin_comments", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "active_admin_comments", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllActiveStorageAttachments(ctx context.Context, page, pagesize int64, order string) (results []*model.ActiveStorageAttachments, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.ActiveStorageAttachments{})
+	resultOrm := scopeQuery(ctx, "active_storage_attachments", model.RetrieveMany, contextDB(ctx).Model(&model.ActiveStorageAttachments{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllActiveStorageAttachments(ctx context.Context, page, pagesize int64, o
 // error - ErrNotFound, db Find error
 func GetActiveStorageAttachments(ctx context.Context, argID int64) (record *model.ActiveStorageAttachments, err error) {
 	record = &model.ActiveStorageAttachments{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_attachments", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddActiveStorageAttachments is a function to add a single record to active_storage_attachments table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddActiveStorageAttachments(ctx context.Context, record *model.ActiveStorageAttachments) (result *model.ActiveStorageAttachments, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "active_storage_attachments", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "active_storage_attachments", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddActiveStorageAttachments(ctx context.Context, record *model.ActiveStorag
 func UpdateActiveStorageAttachments(ctx context.Context, argID int64, updated *model.ActiveStorageAttachments) (result *model.ActiveStorageAttachments, RowsAffected int64, err error) {
 
 	result = &model.ActiveStorageAttachments{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "active_storage_attachments", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_attachments", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "active_storage_attachments", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateActiveStorageAttachments(ctx context.Context, argID int64, updated *m
 func DeleteActiveStorageAttachments(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.ActiveStorageAttachments{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_attachments", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "active_storage_attachments", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
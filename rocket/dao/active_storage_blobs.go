@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllActiveStorageBlobs(ctx context.Context, page, pagesize int64, order string) (results []*model.ActiveStorageBlobs, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.ActiveStorageBlobs{})
+	resultOrm := scopeQuery(ctx, "active_storage_blobs", model.RetrieveMany, contextDB(ctx).Model(&model.ActiveStorageBlobs{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllActiveStorageBlobs(ctx context.Context, page, pagesize int64, order s
 // error - ErrNotFound, db Find error
 func GetActiveStorageBlobs(ctx context.Context, argID int64) (record *model.ActiveStorageBlobs, err error) {
 	record = &model.ActiveStorageBlobs{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_blobs", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddActiveStorageBlobs is a function to add a single record to active_storage_blobs table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddActiveStorageBlobs(ctx context.Context, record *model.ActiveStorageBlobs) (result *model.ActiveStorageBlobs, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "active_storage_blobs", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "active_storage_blobs", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddActiveStorageBlobs(ctx context.Context, record *model.ActiveStorageBlobs
 func UpdateActiveStorageBlobs(ctx context.Context, argID int64, updated *model.ActiveStorageBlobs) (result *model.ActiveStorageBlobs, RowsAffected int64, err error) {
 
 	result = &model.ActiveStorageBlobs{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "active_storage_blobs", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_blobs", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "active_storage_blobs", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateActiveStorageBlobs(ctx context.Context, argID int64, updated *model.A
 func DeleteActiveStorageBlobs(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.ActiveStorageBlobs{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "active_storage_blobs", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "active_storage_blobs", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
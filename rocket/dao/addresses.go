@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllAddresses(ctx context.Context, page, pagesize int64, order string) (results []*model.Addresses, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Addresses{})
+	resultOrm := scopeQuery(ctx, "addresses", model.RetrieveMany, contextDB(ctx).Model(&model.Addresses{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllAddresses(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetAddresses(ctx context.Context, argID int64) (record *model.Addresses, err error) {
 	record = &model.Addresses{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "addresses", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddAddresses is a function to add a single record to addresses table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddAddresses(ctx context.Context, record *model.Addresses) (result *model.Addresses, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "addresses", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "addresses", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddAddresses(ctx context.Context, record *model.Addresses) (result *model.A
 func UpdateAddresses(ctx context.Context, argID int64, updated *model.Addresses) (result *model.Addresses, RowsAffected int64, err error) {
 
 	result = &model.Addresses{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "addresses", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "addresses", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "addresses", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateAddresses(ctx context.Context, argID int64, updated *model.Addresses)
 func DeleteAddresses(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Addresses{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "addresses", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "addresses", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllAdminUsers(ctx context.Context, page, pagesize int64, order string) (results []*model.AdminUsers, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.AdminUsers{})
+	resultOrm := scopeQuery(ctx, "admin_users", model.RetrieveMany, contextDB(ctx).Model(&model.AdminUsers{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllAdminUsers(ctx context.Context, page, pagesize int64, order string) (
 // error - ErrNotFound, db Find error
 func GetAdminUsers(ctx context.Context, argID int64) (record *model.AdminUsers, err error) {
 	record = &model.AdminUsers{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "admin_users", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddAdminUsers is a function to add a single record to admin_users table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddAdminUsers(ctx context.Context, record *model.AdminUsers) (result *model.AdminUsers, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "admin_users", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "admin_users", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddAdminUsers(ctx context.Context, record *model.AdminUsers) (result *model
 func UpdateAdminUsers(ctx context.Context, argID int64, updated *model.AdminUsers) (result *model.AdminUsers, RowsAffected int64, err error) {
 
 	result = &model.AdminUsers{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "admin_users", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "admin_users", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "admin_users", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateAdminUsers(ctx context.Context, argID int64, updated *model.AdminUser
 func DeleteAdminUsers(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.AdminUsers{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "admin_users", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "admin_users", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
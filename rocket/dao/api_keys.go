@@ -0,0 +1,181 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+// GetAllAPIKeys is a function to get a slice of record(s) from api_keys table in the rocket_development database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func GetAllAPIKeys(ctx context.Context, page, pagesize int64, order string) (results []*model.APIKeys, totalRows int, err error) {
+
+	resultOrm := scopeQuery(ctx, "api_keys", model.RetrieveMany, contextDB(ctx).Model(&model.APIKeys{}))
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	if order != "" {
+		resultOrm = resultOrm.Order(order)
+	}
+
+	if err = resultOrm.Find(&results).Error; err != nil {
+		err = ErrNotFound
+		return nil, -1, err
+	}
+
+	return results, totalRows, nil
+}
+
+// GetAPIKeys is a function to get a single record from the api_keys table in the rocket_development database
+// error - ErrNotFound, db Find error
+func GetAPIKeys(ctx context.Context, argID int64) (record *model.APIKeys, err error) {
+	record = &model.APIKeys{}
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
+		err = ErrNotFound
+		return record, err
+	}
+
+	if err = authorizeRecord(ctx, "api_keys", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// AddAPIKeys is a function to add a single record to api_keys table in the rocket_development database
+// error - ErrInsertFailed, db save call failed
+func AddAPIKeys(ctx context.Context, record *model.APIKeys) (result *model.APIKeys, RowsAffected int64, err error) {
+	if err = authorizeRecord(ctx, "api_keys", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrInsertFailed
+	}
+
+	recordChange(ctx, "api_keys", model.Create, nil, record)
+	return record, db.RowsAffected, nil
+}
+
+// UpdateAPIKeys is a function to update a single record from api_keys table in the rocket_development database
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func UpdateAPIKeys(ctx context.Context, argID int64, updated *model.APIKeys) (result *model.APIKeys, RowsAffected int64, err error) {
+
+	result = &model.APIKeys{}
+	db := contextDB(ctx).First(result, argID)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrNotFound
+	}
+
+	before := *result
+	if err = authorizeRecord(ctx, "api_keys", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
+	if err = Copy(result, updated); err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	if err = authorizeRecord(ctx, "api_keys", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	recordChange(ctx, "api_keys", model.Update, &before, result)
+	return result, db.RowsAffected, nil
+}
+
+// DeleteAPIKeys is a function to delete a single record from api_keys table in the rocket_development database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func DeleteAPIKeys(ctx context.Context, argID int64) (rowsAffected int64, err error) {
+
+	record := &model.APIKeys{}
+	db := contextDB(ctx).First(record, argID)
+	if db.Error != nil {
+		return -1, ErrNotFound
+	}
+
+	if err = authorizeRecord(ctx, "api_keys", model.Delete, record); err != nil {
+		return -1, err
+	}
+
+	db = db.Delete(record)
+	if err = db.Error; err != nil {
+		return -1, ErrDeleteFailed
+	}
+
+	recordChange(ctx, "api_keys", model.Delete, record, nil)
+	return db.RowsAffected, nil
+}
+
+// GetAPIKeysByPrefix is a function to get the api_keys record of the public prefix of a key, used to authenticate requests
+// error - ErrNotFound, db Find error
+func GetAPIKeysByPrefix(ctx context.Context, prefix string) (record *model.APIKeys, err error) {
+	record = &model.APIKeys{}
+	if err = contextDB(ctx).Where("prefix = ?", prefix).First(record).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
+
+// SaveAPIKeys is a function to write every column of an api_keys record, unlike UpdateAPIKeys false and empty values are
+// stored so a key can be deactivated or lose its expiry
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db.Save call failed
+func SaveAPIKeys(ctx context.Context, record *model.APIKeys) (result *model.APIKeys, RowsAffected int64, err error) {
+	before := &model.APIKeys{}
+	if err = contextDB(ctx).First(before, record.ID).Error; err != nil {
+		return nil, -1, ErrNotFound
+	}
+
+	if err = authorizeRecord(ctx, "api_keys", model.Update, before); err != nil {
+		return nil, -1, err
+	}
+
+	record.CreatedAt = before.CreatedAt
+	db := contextDB(ctx).Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	recordChange(ctx, "api_keys", model.Update, before, record)
+	return record, db.RowsAffected, nil
+}
+
+// TouchAPIKeys is a function to record when and from where a key was last used, the change is bookkeeping and not recorded
+// error - ErrUpdateFailed, db update failed
+func TouchAPIKeys(ctx context.Context, argID int64, usedAt time.Time, ip string) (err error) {
+	columns := map[string]interface{}{"last_used_at": usedAt, "last_used_ip": null.NewString(ip, ip != "")}
+	if err = contextDB(ctx).Model(&model.APIKeys{}).Where("id = ?", argID).UpdateColumns(columns).Error; err != nil {
+		return ErrUpdateFailed
+	}
+
+	return nil
+}
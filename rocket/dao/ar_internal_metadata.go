@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllArInternalMetadata(ctx context.Context, page, pagesize int64, order string) (results []*model.ArInternalMetadata, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.ArInternalMetadata{})
+	resultOrm := scopeQuery(ctx, "ar_internal_metadata", model.RetrieveMany, contextDB(ctx).Model(&model.ArInternalMetadata{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllArInternalMetadata(ctx context.Context, page, pagesize int64, order s
 // error - ErrNotFound, db Find error
 func GetArInternalMetadata(ctx context.Context, argKey string) (record *model.ArInternalMetadata, err error) {
 	record = &model.ArInternalMetadata{}
-	if err = DB.First(record, argKey).Error; err != nil {
+	if err = contextDB(ctx).First(record, argKey).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "ar_internal_metadata", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddArInternalMetadata is a function to add a single record to ar_internal_metadata table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddArInternalMetadata(ctx context.Context, record *model.ArInternalMetadata) (result *model.ArInternalMetadata, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "ar_internal_metadata", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "ar_internal_metadata", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddArInternalMetadata(ctx context.Context, record *model.ArInternalMetadata
 func UpdateArInternalMetadata(ctx context.Context, argKey string, updated *model.ArInternalMetadata) (result *model.ArInternalMetadata, RowsAffected int64, err error) {
 
 	result = &model.ArInternalMetadata{}
-	db := DB.First(result, argKey)
+	db := contextDB(ctx).First(result, argKey)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "ar_internal_metadata", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "ar_internal_metadata", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "ar_internal_metadata", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateArInternalMetadata(ctx context.Context, argKey string, updated *model
 func DeleteArInternalMetadata(ctx context.Context, argKey string) (rowsAffected int64, err error) {
 
 	record := &model.ArInternalMetadata{}
-	db := DB.First(record, argKey)
+	db := contextDB(ctx).First(record, argKey)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "ar_internal_metadata", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "ar_internal_metadata", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -0,0 +1,241 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocket/logging"
+	"rocket/model"
+
+	"github.com/guregu/null"
+)
+
+// filteredValue placeholder recorded in audit_logs for changes to sensitive columns
+const filteredValue = "[FILTERED]"
+
+// Actor who performed a change, recorded with every audit_logs entry
+type Actor struct {
+	Type      string `json:"type,omitempty"`
+	ID        int64  `json:"id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+// FieldChange old and new value of a column in an audit_logs entry, Old is nil for creates and New is nil for deletes
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditFilter restricts GetAllAuditLogs results, empty fields match every entry
+type AuditFilter struct {
+	Table    string
+	RecordID string
+
+	// Actor email or id of the account that made the change
+	Actor string
+
+	Since time.Time
+}
+
+type actorContextKey struct{}
+
+// WithActor return a copy of ctx carrying the actor changes made with it are attributed to
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext return the actor of ctx, nil for changes made by the service itself
+func ActorFromContext(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(*Actor)
+	return actor
+}
+
+// AuditChange ChangeRecorderFunc writing an audit_logs entry with the actor of ctx and a field level diff of before and after.
+// Updates that change nothing are not recorded, failures are logged and do not undo the change.
+func AuditChange(ctx context.Context, table string, action model.Action, before, after interface{}) {
+	entry, err := NewAuditLog(ctx, table, action, before, after)
+	if err != nil {
+		logging.Error(ctx, "audit failed", logging.Fields{"action": action.String(), "table": table, "error": err})
+		return
+	}
+
+	if entry == nil {
+		return
+	}
+
+	if err = contextDB(ctx).Create(entry).Error; err != nil {
+		logging.Error(ctx, "audit failed", logging.Fields{"action": action.String(), "table": table, "record_id": entry.RecordID, "error": err})
+	}
+}
+
+// NewAuditLog build the audit_logs entry of a change, nil when an update changed nothing but updated_at
+func NewAuditLog(ctx context.Context, table string, action model.Action, before, after interface{}) (*model.AuditLogs, error) {
+	info, ok := model.GetTableInfo(table)
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+
+	record := after
+	if record == nil {
+		record = before
+	}
+
+	changes := diffRecords(info, before, after)
+	if _, touched := changes["updated_at"]; action == model.Update && (len(changes) == 0 || (touched && len(changes) == 1)) {
+		// saving a record bumps updated_at even when no column changed
+		return nil, nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.AuditLogs{
+		TableName_: table,
+		RecordID:   primaryKey(info, record),
+		Action:     action.String(),
+		Changes:    data,
+		CreatedAt:  time.Now(),
+	}
+
+	if actor := ActorFromContext(ctx); actor != nil {
+		entry.ActorType = null.NewString(actor.Type, actor.Type != "")
+		entry.ActorID = null.NewInt(actor.ID, actor.ID != 0)
+		entry.ActorEmail = null.NewString(actor.Email, actor.Email != "")
+		entry.IPAddress = null.NewString(actor.IPAddress, actor.IPAddress != "")
+	}
+
+	return entry, nil
+}
+
+// GetAllAuditLogs is a function to get a slice of audit_logs entries matching filter, newest first
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// error - ErrNotFound, db Find error
+func GetAllAuditLogs(ctx context.Context, filter *AuditFilter, page, pagesize int64) (results []*model.AuditLogs, totalRows int, err error) {
+
+	resultOrm := contextDB(ctx).Model(&model.AuditLogs{})
+	if filter.Table != "" {
+		resultOrm = resultOrm.Where("table_name = ?", filter.Table)
+	}
+
+	if filter.RecordID != "" {
+		resultOrm = resultOrm.Where("record_id = ?", filter.RecordID)
+	}
+
+	if filter.Actor != "" {
+		if id, err := strconv.ParseInt(filter.Actor, 10, 64); err == nil {
+			resultOrm = resultOrm.Where("actor_id = ?", id)
+		} else {
+			resultOrm = resultOrm.Where("actor_email = ?", strings.ToLower(filter.Actor))
+		}
+	}
+
+	if !filter.Since.IsZero() {
+		resultOrm = resultOrm.Where("created_at >= ?", filter.Since)
+	}
+
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	if err = resultOrm.Order("created_at DESC, id DESC").Find(&results).Error; err != nil {
+		err = ErrNotFound
+		return nil, -1, err
+	}
+
+	return results, totalRows, nil
+}
+
+// RecordChanges field level diff of a change to a record of table, see FieldChange
+func RecordChanges(table string, before, after interface{}) map[string]*FieldChange {
+	info, ok := model.GetTableInfo(table)
+	if !ok {
+		return nil
+	}
+	return diffRecords(info, before, after)
+}
+
+// PrimaryKey the primary key of a record of table as stored in audit_logs.record_id
+func PrimaryKey(table string, record interface{}) string {
+	info, ok := model.GetTableInfo(table)
+	if !ok {
+		return ""
+	}
+	return primaryKey(info, record)
+}
+
+// diffRecords the columns whose value differs between before and after keyed by column name, values of sensitive columns are masked
+func diffRecords(info *model.TableInfo, before, after interface{}) map[string]*FieldChange {
+	changes := make(map[string]*FieldChange)
+	for _, col := range info.Columns {
+		var oldValue, newValue interface{}
+		if before != nil {
+			oldValue, _ = model.ColumnValue(before, col)
+		}
+		if after != nil {
+			newValue, _ = model.ColumnValue(after, col)
+		}
+
+		if sameValue(oldValue, newValue) {
+			continue
+		}
+
+		if col.IsSensitive {
+			oldValue, newValue = maskValue(oldValue), maskValue(newValue)
+		}
+		changes[col.Name] = &FieldChange{Old: oldValue, New: newValue}
+	}
+	return changes
+}
+
+func sameValue(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func maskValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return filteredValue
+}
+
+// primaryKey the primary key of record as stored in audit_logs.record_id, composite keys are comma separated
+func primaryKey(info *model.TableInfo, record interface{}) string {
+	var values []string
+	for _, col := range info.Columns {
+		if col.IsPrimaryKey {
+			value, _ := model.ColumnValue(record, col)
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// cloneRecord shallow copy of a record struct, used to keep the state before an update
+func cloneRecord(record interface{}) interface{} {
+	v := reflect.ValueOf(record)
+	if v.Kind() != reflect.Ptr {
+		return record
+	}
+
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+	return clone.Interface()
+}
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBatteries(ctx context.Context, page, pagesize int64, order string) (results []*model.Batteries, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Batteries{})
+	resultOrm := scopeQuery(ctx, "batteries", model.RetrieveMany, contextDB(ctx).Model(&model.Batteries{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBatteries(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetBatteries(ctx context.Context, argID int64) (record *model.Batteries, err error) {
 	record = &model.Batteries{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "batteries", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBatteries is a function to add a single record to batteries table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBatteries(ctx context.Context, record *model.Batteries) (result *model.Batteries, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "batteries", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "batteries", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBatteries(ctx context.Context, record *model.Batteries) (result *model.B
 func UpdateBatteries(ctx context.Context, argID int64, updated *model.Batteries) (result *model.Batteries, RowsAffected int64, err error) {
 
 	result = &model.Batteries{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "batteries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "batteries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "batteries", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBatteries(ctx context.Context, argID int64, updated *model.Batteries)
 func DeleteBatteries(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Batteries{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "batteries", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "batteries", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
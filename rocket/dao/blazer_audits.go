@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBlazerAudits(ctx context.Context, page, pagesize int64, order string) (results []*model.BlazerAudits, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.BlazerAudits{})
+	resultOrm := scopeQuery(ctx, "blazer_audits", model.RetrieveMany, contextDB(ctx).Model(&model.BlazerAudits{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBlazerAudits(ctx context.Context, page, pagesize int64, order string)
 // error - ErrNotFound, db Find error
 func GetBlazerAudits(ctx context.Context, argID int64) (record *model.BlazerAudits, err error) {
 	record = &model.BlazerAudits{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "blazer_audits", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBlazerAudits is a function to add a single record to blazer_audits table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBlazerAudits(ctx context.Context, record *model.BlazerAudits) (result *model.BlazerAudits, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "blazer_audits", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "blazer_audits", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBlazerAudits(ctx context.Context, record *model.BlazerAudits) (result *m
 func UpdateBlazerAudits(ctx context.Context, argID int64, updated *model.BlazerAudits) (result *model.BlazerAudits, RowsAffected int64, err error) {
 
 	result = &model.BlazerAudits{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "blazer_audits", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "blazer_audits", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "blazer_audits", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBlazerAudits(ctx context.Context, argID int64, updated *model.BlazerA
 func DeleteBlazerAudits(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.BlazerAudits{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "blazer_audits", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "blazer_audits", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"rocket/model"
+)
+
+// GetScheduledBlazerChecks is a function to get every record from the blazer_checks table that has a schedule
+// error - ErrNotFound, db Find error
+func GetScheduledBlazerChecks(ctx context.Context) (results []*model.BlazerChecks, err error) {
+	if err = contextDB(ctx).Where("schedule IS NOT NULL AND schedule <> ''").Order("id").Find(&results).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// RecordBlazerCheckRun is a function to store the outcome of a blazer check run in the blazer_checks table in the rocket_development database.
+// Unlike UpdateBlazerChecks empty values are written, so a passing run clears the previous error message.
+// error - ErrUpdateFailed, db Updates call failed
+func RecordBlazerCheckRun(ctx context.Context, argID int64, state, message string, ranAt time.Time) (err error) {
+	db := contextDB(ctx).Model(&model.BlazerChecks{}).Where("id = ?", argID).Updates(map[string]interface{}{
+		"state":       state,
+		"message":     sql.NullString{String: message, Valid: message != ""},
+		"last_run_at": ranAt,
+		"updated_at":  ranAt,
+	})
+	if err = db.Error; err != nil {
+		return ErrUpdateFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+	"github.com/jinzhu/gorm"
+)
+
+// GetBlazerDashboardLayout is a function to get the blazer_dashboard_queries records of a dashboard the caller may read ordered
+// by position
+// error - ErrNotFound, db Find error
+func GetBlazerDashboardLayout(ctx context.Context, dashboardID int64) (results []*model.BlazerDashboardQueries, err error) {
+	return getBlazerDashboardLayout(scopeLayout(ctx, contextDB(ctx)), dashboardID)
+}
+
+// scopeLayout restrict db to the blazer_dashboard_queries the caller may read, the layout is changed as a whole so the
+// transactions read every record and authorize each one they write
+func scopeLayout(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return scopeQuery(ctx, "blazer_dashboard_queries", model.RetrieveMany, db)
+}
+
+func getBlazerDashboardLayout(db *gorm.DB, dashboardID int64) (results []*model.BlazerDashboardQueries, err error) {
+	if err = db.Where("dashboard_id = ?", dashboardID).Order("position, id").Find(&results).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// GetBlazerQueriesByIDs is a function to get the blazer_queries records the caller may read for a set of ids keyed by id
+// error - ErrNotFound, db Find error
+func GetBlazerQueriesByIDs(ctx context.Context, ids []int64) (results map[int64]*model.BlazerQueries, err error) {
+	results = make(map[int64]*model.BlazerQueries)
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	var records []*model.BlazerQueries
+	resultOrm := scopeQuery(ctx, "blazer_queries", model.RetrieveMany, contextDB(ctx).Model(&model.BlazerQueries{}))
+	if err = resultOrm.Where("id IN (?)", ids).Find(&records).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	for _, record := range records {
+		results[record.ID] = record
+	}
+	return results, nil
+}
+
+// SetBlazerDashboardLayout is a function to replace the queries of a dashboard with queryIDs in the given order.
+// Queries no longer listed are removed, new queries are added and positions are rewritten, all in one transaction.
+// Every written record is authorized like the generated crud functions, change recorders are invoked after the commit.
+// error - ErrNotFound, dashboard or one of the queries not found
+// error - ErrBadParams, a query is listed more than once
+// error - ErrUpdateFailed, db transaction failed
+func SetBlazerDashboardLayout(ctx context.Context, dashboardID int64, queryIDs []int64) (results []*model.BlazerDashboardQueries, err error) {
+	seen := make(map[int64]bool)
+	for _, id := range queryIDs {
+		if seen[id] {
+			return nil, ErrBadParams
+		}
+		seen[id] = true
+	}
+
+	var changes []*importedChange
+	err = contextDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkBlazerDashboardAndQueries(tx, dashboardID, queryIDs...); err != nil {
+			return err
+		}
+
+		current, err := getBlazerDashboardLayout(tx, dashboardID)
+		if err != nil {
+			return err
+		}
+
+		byQuery := make(map[int64]*model.BlazerDashboardQueries)
+		for _, record := range current {
+			if !record.QueryID.Valid || !seen[record.QueryID.Int64] || byQuery[record.QueryID.Int64] != nil {
+				change, err := deleteBlazerDashboardQuery(ctx, tx, record)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, change)
+				continue
+			}
+			byQuery[record.QueryID.Int64] = record
+		}
+
+		now := time.Now()
+		layout := make([]*model.BlazerDashboardQueries, 0, len(queryIDs))
+		for _, queryID := range queryIDs {
+			record, ok := byQuery[queryID]
+			if !ok {
+				record = &model.BlazerDashboardQueries{
+					DashboardID: null.IntFrom(dashboardID),
+					QueryID:     null.IntFrom(queryID),
+					CreatedAt:   now,
+				}
+			}
+			layout = append(layout, record)
+		}
+
+		saved, err := saveBlazerDashboardPositions(ctx, tx, layout, now)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, saved...)
+
+		results, err = getBlazerDashboardLayout(scopeLayout(ctx, tx), dashboardID)
+		return err
+	})
+	if err != nil {
+		if err == ErrDeleteFailed {
+			err = ErrUpdateFailed
+		}
+		return nil, err
+	}
+
+	recordBlazerDashboardChanges(ctx, changes)
+	return results, nil
+}
+
+// AddBlazerDashboardQuery is a function to add a query to a dashboard at position, queries at or after position are moved down.
+// A negative position or one past the end appends the query.
+// error - ErrNotFound, dashboard or query not found
+// error - ErrInsertFailed, db transaction failed
+func AddBlazerDashboardQuery(ctx context.Context, dashboardID, queryID int64, position int64) (results []*model.BlazerDashboardQueries, err error) {
+	var changes []*importedChange
+	err = contextDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkBlazerDashboardAndQueries(tx, dashboardID, queryID); err != nil {
+			return err
+		}
+
+		current, err := getBlazerDashboardLayout(tx, dashboardID)
+		if err != nil {
+			return err
+		}
+
+		if position < 0 || position > int64(len(current)) {
+			position = int64(len(current))
+		}
+
+		now := time.Now()
+		record := &model.BlazerDashboardQueries{
+			DashboardID: null.IntFrom(dashboardID),
+			QueryID:     null.IntFrom(queryID),
+			CreatedAt:   now,
+		}
+
+		layout := make([]*model.BlazerDashboardQueries, 0, len(current)+1)
+		layout = append(layout, current[:position]...)
+		layout = append(layout, record)
+		layout = append(layout, current[position:]...)
+
+		if changes, err = saveBlazerDashboardPositions(ctx, tx, layout, now); err != nil {
+			return err
+		}
+
+		results, err = getBlazerDashboardLayout(scopeLayout(ctx, tx), dashboardID)
+		return err
+	})
+	if err != nil {
+		if err == ErrUpdateFailed {
+			err = ErrInsertFailed
+		}
+		return nil, err
+	}
+
+	recordBlazerDashboardChanges(ctx, changes)
+	return results, nil
+}
+
+// RemoveBlazerDashboardQuery is a function to remove a query from a dashboard and close the gap in positions
+// error - ErrNotFound, the query is not on the dashboard
+// error - ErrDeleteFailed, db transaction failed
+func RemoveBlazerDashboardQuery(ctx context.Context, dashboardID, queryID int64) (results []*model.BlazerDashboardQueries, err error) {
+	var changes []*importedChange
+	err = contextDB(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := getBlazerDashboardLayout(tx, dashboardID)
+		if err != nil {
+			return err
+		}
+
+		layout := make([]*model.BlazerDashboardQueries, 0, len(current))
+		removed := false
+		for _, record := range current {
+			if record.QueryID.Valid && record.QueryID.Int64 == queryID {
+				change, err := deleteBlazerDashboardQuery(ctx, tx, record)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, change)
+				removed = true
+				continue
+			}
+			layout = append(layout, record)
+		}
+
+		if !removed {
+			return ErrNotFound
+		}
+
+		saved, err := saveBlazerDashboardPositions(ctx, tx, layout, time.Now())
+		if err != nil {
+			return err
+		}
+		changes = append(changes, saved...)
+
+		results, err = getBlazerDashboardLayout(scopeLayout(ctx, tx), dashboardID)
+		return err
+	})
+	if err != nil {
+		if err == ErrUpdateFailed {
+			err = ErrDeleteFailed
+		}
+		return nil, err
+	}
+
+	recordBlazerDashboardChanges(ctx, changes)
+	return results, nil
+}
+
+// saveBlazerDashboardPositions number the records of layout in order, new records are created and records whose position
+// changed are updated, each authorized like the generated crud functions
+func saveBlazerDashboardPositions(ctx context.Context, tx *gorm.DB, layout []*model.BlazerDashboardQueries, now time.Time) ([]*importedChange, error) {
+	var changes []*importedChange
+	for position, record := range layout {
+		if record.ID != 0 && record.Position.Valid && record.Position.Int64 == int64(position) {
+			continue
+		}
+
+		action, before := model.Create, (*model.BlazerDashboardQueries)(nil)
+		if record.ID != 0 {
+			previous := *record
+			action, before = model.Update, &previous
+			if err := authorizeRecord(ctx, "blazer_dashboard_queries", model.Update, record); err != nil {
+				return nil, err
+			}
+		}
+
+		record.Position = null.IntFrom(int64(position))
+		record.UpdatedAt = now
+		if err := authorizeRecord(ctx, "blazer_dashboard_queries", action, record); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Save(record).Error; err != nil {
+			return nil, ErrUpdateFailed
+		}
+
+		change := &importedChange{action: action, after: record}
+		if before != nil {
+			change.before = before
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// deleteBlazerDashboardQuery delete record from a dashboard once it is authorized
+func deleteBlazerDashboardQuery(ctx context.Context, tx *gorm.DB, record *model.BlazerDashboardQueries) (*importedChange, error) {
+	if err := authorizeRecord(ctx, "blazer_dashboard_queries", model.Delete, record); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(record).Error; err != nil {
+		return nil, ErrDeleteFailed
+	}
+	return &importedChange{action: model.Delete, before: record}, nil
+}
+
+// recordBlazerDashboardChanges invoke the change recorders for the layout changes of a committed transaction
+func recordBlazerDashboardChanges(ctx context.Context, changes []*importedChange) {
+	for _, change := range changes {
+		recordChange(ctx, "blazer_dashboard_queries", change.action, change.before, change.after)
+	}
+}
+
+func checkBlazerDashboardAndQueries(tx *gorm.DB, dashboardID int64, queryIDs ...int64) error {
+	if err := tx.First(&model.BlazerDashboards{}, dashboardID).Error; err != nil {
+		return ErrNotFound
+	}
+
+	if len(queryIDs) == 0 {
+		return nil
+	}
+
+	count := 0
+	if err := tx.Model(&model.BlazerQueries{}).Where("id IN (?)", queryIDs).Count(&count).Error; err != nil || count != len(queryIDs) {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBlazerDashboardQueries(ctx context.Context, page, pagesize int64, order string) (results []*model.BlazerDashboardQueries, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.BlazerDashboardQueries{})
+	resultOrm := scopeQuery(ctx, "blazer_dashboard_queries", model.RetrieveMany, contextDB(ctx).Model(&model.BlazerDashboardQueries{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBlazerDashboardQueries(ctx context.Context, page, pagesize int64, ord
 // error - ErrNotFound, db Find error
 func GetBlazerDashboardQueries(ctx context.Context, argID int64) (record *model.BlazerDashboardQueries, err error) {
 	record = &model.BlazerDashboardQueries{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboard_queries", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBlazerDashboardQueries is a function to add a single record to blazer_dashboard_queries table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBlazerDashboardQueries(ctx context.Context, record *model.BlazerDashboardQueries) (result *model.BlazerDashboardQueries, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "blazer_dashboard_queries", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "blazer_dashboard_queries", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBlazerDashboardQueries(ctx context.Context, record *model.BlazerDashboar
 func UpdateBlazerDashboardQueries(ctx context.Context, argID int64, updated *model.BlazerDashboardQueries) (result *model.BlazerDashboardQueries, RowsAffected int64, err error) {
 
 	result = &model.BlazerDashboardQueries{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "blazer_dashboard_queries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboard_queries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "blazer_dashboard_queries", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBlazerDashboardQueries(ctx context.Context, argID int64, updated *mod
 func DeleteBlazerDashboardQueries(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.BlazerDashboardQueries{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboard_queries", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "blazer_dashboard_queries", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
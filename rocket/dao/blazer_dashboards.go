@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBlazerDashboards(ctx context.Context, page, pagesize int64, order string) (results []*model.BlazerDashboards, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.BlazerDashboards{})
+	resultOrm := scopeQuery(ctx, "blazer_dashboards", model.RetrieveMany, contextDB(ctx).Model(&model.BlazerDashboards{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBlazerDashboards(ctx context.Context, page, pagesize int64, order str
 // error - ErrNotFound, db Find error
 func GetBlazerDashboards(ctx context.Context, argID int64) (record *model.BlazerDashboards, err error) {
 	record = &model.BlazerDashboards{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboards", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBlazerDashboards is a function to add a single record to blazer_dashboards table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBlazerDashboards(ctx context.Context, record *model.BlazerDashboards) (result *model.BlazerDashboards, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "blazer_dashboards", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "blazer_dashboards", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBlazerDashboards(ctx context.Context, record *model.BlazerDashboards) (r
 func UpdateBlazerDashboards(ctx context.Context, argID int64, updated *model.BlazerDashboards) (result *model.BlazerDashboards, RowsAffected int64, err error) {
 
 	result = &model.BlazerDashboards{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "blazer_dashboards", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboards", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "blazer_dashboards", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBlazerDashboards(ctx context.Context, argID int64, updated *model.Bla
 func DeleteBlazerDashboards(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.BlazerDashboards{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "blazer_dashboards", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "blazer_dashboards", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
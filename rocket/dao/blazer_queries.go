@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBlazerQueries(ctx context.Context, page, pagesize int64, order string) (results []*model.BlazerQueries, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.BlazerQueries{})
+	resultOrm := scopeQuery(ctx, "blazer_queries", model.RetrieveMany, contextDB(ctx).Model(&model.BlazerQueries{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBlazerQueries(ctx context.Context, page, pagesize int64, order string
 // error - ErrNotFound, db Find error
 func GetBlazerQueries(ctx context.Context, argID int64) (record *model.BlazerQueries, err error) {
 	record = &model.BlazerQueries{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "blazer_queries", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBlazerQueries is a function to add a single record to blazer_queries table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBlazerQueries(ctx context.Context, record *model.BlazerQueries) (result *model.BlazerQueries, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "blazer_queries", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "blazer_queries", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBlazerQueries(ctx context.Context, record *model.BlazerQueries) (result
 func UpdateBlazerQueries(ctx context.Context, argID int64, updated *model.BlazerQueries) (result *model.BlazerQueries, RowsAffected int64, err error) {
 
 	result = &model.BlazerQueries{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "blazer_queries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "blazer_queries", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "blazer_queries", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBlazerQueries(ctx context.Context, argID int64, updated *model.Blazer
 func DeleteBlazerQueries(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.BlazerQueries{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "blazer_queries", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "blazer_queries", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBuildingDetails(ctx context.Context, page, pagesize int64, order string) (results []*model.BuildingDetails, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.BuildingDetails{})
+	resultOrm := scopeQuery(ctx, "building_details", model.RetrieveMany, contextDB(ctx).Model(&model.BuildingDetails{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBuildingDetails(ctx context.Context, page, pagesize int64, order stri
 // error - ErrNotFound, db Find error
 func GetBuildingDetails(ctx context.Context, argID int64) (record *model.BuildingDetails, err error) {
 	record = &model.BuildingDetails{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "building_details", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBuildingDetails is a function to add a single record to building_details table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBuildingDetails(ctx context.Context, record *model.BuildingDetails) (result *model.BuildingDetails, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "building_details", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "building_details", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBuildingDetails(ctx context.Context, record *model.BuildingDetails) (res
 func UpdateBuildingDetails(ctx context.Context, argID int64, updated *model.BuildingDetails) (result *model.BuildingDetails, RowsAffected int64, err error) {
 
 	result = &model.BuildingDetails{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "building_details", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "building_details", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "building_details", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBuildingDetails(ctx context.Context, argID int64, updated *model.Buil
 func DeleteBuildingDetails(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.BuildingDetails{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "building_details", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "building_details", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
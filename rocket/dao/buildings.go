@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllBuildings(ctx context.Context, page, pagesize int64, order string) (results []*model.Buildings, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Buildings{})
+	resultOrm := scopeQuery(ctx, "buildings", model.RetrieveMany, contextDB(ctx).Model(&model.Buildings{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllBuildings(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetBuildings(ctx context.Context, argID int64) (record *model.Buildings, err error) {
 	record = &model.Buildings{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "buildings", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddBuildings is a function to add a single record to buildings table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddBuildings(ctx context.Context, record *model.Buildings) (result *model.Buildings, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "buildings", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "buildings", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddBuildings(ctx context.Context, record *model.Buildings) (result *model.B
 func UpdateBuildings(ctx context.Context, argID int64, updated *model.Buildings) (result *model.Buildings, RowsAffected int64, err error) {
 
 	result = &model.Buildings{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "buildings", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "buildings", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "buildings", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateBuildings(ctx context.Context, argID int64, updated *model.Buildings)
 func DeleteBuildings(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Buildings{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "buildings", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "buildings", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllColumns(ctx context.Context, page, pagesize int64, order string) (results []*model.Columns, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Columns{})
+	resultOrm := scopeQuery(ctx, "columns", model.RetrieveMany, contextDB(ctx).Model(&model.Columns{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllColumns(ctx context.Context, page, pagesize int64, order string) (res
 // error - ErrNotFound, db Find error
 func GetColumns(ctx context.Context, argID int64) (record *model.Columns, err error) {
 	record = &model.Columns{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "columns", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddColumns is a function to add a single record to columns table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddColumns(ctx context.Context, record *model.Columns) (result *model.Columns, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "columns", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "columns", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddColumns(ctx context.Context, record *model.Columns) (result *model.Colum
 func UpdateColumns(ctx context.Context, argID int64, updated *model.Columns) (result *model.Columns, RowsAffected int64, err error) {
 
 	result = &model.Columns{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "columns", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "columns", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "columns", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateColumns(ctx context.Context, argID int64, updated *model.Columns) (re
 func DeleteColumns(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Columns{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "columns", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "columns", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
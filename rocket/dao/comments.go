@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"context"
+
+	"rocket/model"
+)
+
+// GetResourceComments is a function to get the active_admin_comments of a resource in a namespace, oldest first like ActiveAdmin lists them
+// error - ErrNotFound, db Find error
+func GetResourceComments(ctx context.Context, resourceType string, resourceID int64, namespace string) (results []*model.ActiveAdminComments, err error) {
+	resultOrm := scopeQuery(ctx, "active_admin_comments", model.RetrieveMany, contextDB(ctx).Model(&model.ActiveAdminComments{}))
+	resultOrm = resultOrm.Where("resource_type = ? AND resource_id = ? AND namespace = ?", resourceType, resourceID, namespace)
+
+	if err = resultOrm.Order("created_at, id").Find(&results).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// AddResourceComment is a function to add a comment posted to a resource by the caller, it is authorized on the comments pseudo
+// table as the author is the caller while creating active_admin_comments directly sets any author
+// error - ErrInsertFailed, db save call failed
+func AddResourceComment(ctx context.Context, record *model.ActiveAdminComments) (result *model.ActiveAdminComments, err error) {
+	if err = authorizeRecord(ctx, "comments", model.Create, record); err != nil {
+		return nil, err
+	}
+
+	if err = contextDB(ctx).Save(record).Error; err != nil {
+		return nil, ErrInsertFailed
+	}
+
+	recordChange(ctx, "active_admin_comments", model.Create, nil, record)
+	return record, nil
+}
+
+// ResourceExists is a function to check a record of table with primary key argID exists and may be read by the caller
+// error - ErrNotFound, no such record or the record is hidden from the caller
+func ResourceExists(ctx context.Context, table string, argID int64) error {
+	info, ok := model.GetTableInfo(table)
+	if !ok {
+		return ErrNotFound
+	}
+
+	for _, col := range info.Columns {
+		if !col.IsPrimaryKey {
+			continue
+		}
+
+		count := 0
+		resultOrm := scopeQuery(ctx, table, model.RetrieveOne, contextDB(ctx).Table(table))
+		if _, soft := SoftDeleteTables[table]; soft {
+			resultOrm = resultOrm.Where("deleted_at IS NULL")
+		}
+
+		if err := resultOrm.Where(DB.Dialect().Quote(col.Name)+" = ?", argID).Count(&count).Error; err != nil || count == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}
+
+	return ErrNotFound
+}
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllCustomers(ctx context.Context, page, pagesize int64, order string) (results []*model.Customers, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Customers{})
+	resultOrm := scopeQuery(ctx, "customers", model.RetrieveMany, contextDB(ctx).Model(&model.Customers{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllCustomers(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetCustomers(ctx context.Context, argID int64) (record *model.Customers, err error) {
 	record = &model.Customers{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "customers", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddCustomers is a function to add a single record to customers table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddCustomers(ctx context.Context, record *model.Customers) (result *model.Customers, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "customers", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "customers", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddCustomers(ctx context.Context, record *model.Customers) (result *model.C
 func UpdateCustomers(ctx context.Context, argID int64, updated *model.Customers) (result *model.Customers, RowsAffected int64, err error) {
 
 	result = &model.Customers{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "customers", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "customers", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "customers", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateCustomers(ctx context.Context, argID int64, updated *model.Customers)
 func DeleteCustomers(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Customers{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "customers", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "customers", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
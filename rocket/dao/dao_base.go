@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
+
+	"rocket/model"
 
 	"github.com/jinzhu/gorm"
 )
@@ -31,7 +34,38 @@ type BuildInfo struct {
 	RuntimeVer string
 }
 
-type LogSql func(ctx context.Context, sql string)
+// Query a sql statement run through the dao, passed to Logger once it completed
+type Query struct {
+	// Table the table of the statement, empty for raw sql
+	Table string
+
+	// Statement the kind of statement, create, update, delete, select, row_query or execute for ExecuteStatement
+	Statement string
+
+	// SQL the sql with placeholders, the bound values are left out as they may hold secrets
+	SQL string
+
+	// Elapsed time the statement took
+	Elapsed time.Duration
+
+	// RowsAffected rows inserted, updated, deleted or read
+	RowsAffected int64
+
+	// Err the error of the statement, nil for a record not found
+	Err error
+}
+
+// LogSql function invoked after a sql statement ran, ctx is the context of the dao function that ran it
+type LogSql func(ctx context.Context, query *Query)
+
+// RecordAuthorizerFunc function invoked with a record before it is returned, inserted, updated or deleted, a non nil error aborts the operation
+type RecordAuthorizerFunc func(ctx context.Context, table string, action model.Action, record interface{}) error
+
+// QueryScoperFunc function invoked to restrict the rows a query may read
+type QueryScoperFunc func(ctx context.Context, table string, action model.Action, db *gorm.DB) *gorm.DB
+
+// ChangeRecorderFunc function invoked after a record was created, updated or deleted, before is nil for creates and after is nil for deletes
+type ChangeRecorderFunc func(ctx context.Context, table string, action model.Action, before, after interface{})
 
 var (
 	// ErrNotFound error when record not found
@@ -58,10 +92,65 @@ var (
 	// AppBuildInfo reference to build info
 	AppBuildInfo *BuildInfo
 
-	// Logger function that will be invoked before executing sql
+	// Logger function that will be invoked after executing sql, it needs InstrumentQueries
 	Logger LogSql
+
+	// RecordAuthorizer function that will be invoked with every record read or written through the dao functions, nil allows everything
+	RecordAuthorizer RecordAuthorizerFunc
+
+	// QueryScoper function that will be invoked to restrict GetAll queries, nil leaves queries unrestricted
+	QueryScoper QueryScoperFunc
+
+	// ChangeRecorder function that will be invoked after every successful Add, Update and Delete, ie AuditChange
+	ChangeRecorder ChangeRecorderFunc
 )
 
+const contextKey = "dao:context"
+
+// contextDB DB carrying ctx to the query callbacks, ie for Logger to tag statements with the request id
+func contextDB(ctx context.Context) *gorm.DB {
+	return DB.Set(contextKey, ctx)
+}
+
+// queryContext the context contextDB attached to the statement of scope, background when there is none
+func queryContext(scope *gorm.Scope) context.Context {
+	if value, ok := scope.Get(contextKey); ok {
+		if ctx, ok := value.(context.Context); ok && ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+func authorizeRecord(ctx context.Context, table string, action model.Action, record interface{}) error {
+	if RecordAuthorizer != nil {
+		return RecordAuthorizer(ctx, table, action, record)
+	}
+	return nil
+}
+
+func scopeQuery(ctx context.Context, table string, action model.Action, db *gorm.DB) *gorm.DB {
+	if QueryScoper != nil {
+		return QueryScoper(ctx, table, action, db)
+	}
+	return db
+}
+
+// ChangeRecorders combine several ChangeRecorderFunc into one, they are invoked in order
+func ChangeRecorders(recorders ...ChangeRecorderFunc) ChangeRecorderFunc {
+	return func(ctx context.Context, table string, action model.Action, before, after interface{}) {
+		for _, recorder := range recorders {
+			recorder(ctx, table, action, before, after)
+		}
+	}
+}
+
+func recordChange(ctx context.Context, table string, action model.Action, before, after interface{}) {
+	if ChangeRecorder != nil {
+		ChangeRecorder(ctx, table, action, before, after)
+	}
+}
+
 // Copy a src struct into a destination struct
 func Copy(dst interface{}, src interface{}) error {
 	dstV := reflect.Indirect(reflect.ValueOf(dst))
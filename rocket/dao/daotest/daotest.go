@@ -0,0 +1,45 @@
+// Package daotest opens throwaway sqlite databases for the tests of the packages reading and writing through dao
+package daotest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rocket/dao"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+// Open point dao.DB at a new sqlite database in a temporary directory and execute statements on it, ie the CREATE TABLE of
+// the tables under test. dao.DB is restored and the database removed when the test ends.
+func Open(t testing.TB, statements ...string) *gorm.DB {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "daotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	previous := dao.DB
+	dao.DB = db
+	t.Cleanup(func() {
+		dao.DB = previous
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
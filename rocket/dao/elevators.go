@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllElevators(ctx context.Context, page, pagesize int64, order string) (results []*model.Elevators, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Elevators{})
+	resultOrm := scopeQuery(ctx, "elevators", model.RetrieveMany, contextDB(ctx).Model(&model.Elevators{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllElevators(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetElevators(ctx context.Context, argID int64) (record *model.Elevators, err error) {
 	record = &model.Elevators{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "elevators", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddElevators is a function to add a single record to elevators table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddElevators(ctx context.Context, record *model.Elevators) (result *model.Elevators, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "elevators", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "elevators", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddElevators(ctx context.Context, record *model.Elevators) (result *model.E
 func UpdateElevators(ctx context.Context, argID int64, updated *model.Elevators) (result *model.Elevators, RowsAffected int64, err error) {
 
 	result = &model.Elevators{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "elevators", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "elevators", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "elevators", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateElevators(ctx context.Context, argID int64, updated *model.Elevators)
 func DeleteElevators(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Elevators{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "elevators", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "elevators", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllEmployees(ctx context.Context, page, pagesize int64, order string) (results []*model.Employees, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Employees{})
+	resultOrm := scopeQuery(ctx, "employees", model.RetrieveMany, contextDB(ctx).Model(&model.Employees{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllEmployees(ctx context.Context, page, pagesize int64, order string) (r
 // error - ErrNotFound, db Find error
 func GetEmployees(ctx context.Context, argID int64) (record *model.Employees, err error) {
 	record = &model.Employees{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "employees", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddEmployees is a function to add a single record to employees table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddEmployees(ctx context.Context, record *model.Employees) (result *model.Employees, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "employees", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "employees", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddEmployees(ctx context.Context, record *model.Employees) (result *model.E
 func UpdateEmployees(ctx context.Context, argID int64, updated *model.Employees) (result *model.Employees, RowsAffected int64, err error) {
 
 	result = &model.Employees{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "employees", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "employees", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "employees", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateEmployees(ctx context.Context, argID int64, updated *model.Employees)
 func DeleteEmployees(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Employees{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "employees", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "employees", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
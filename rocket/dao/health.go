@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"rocket/model"
+)
+
+// ErrNoDatabase error when DB was not opened yet
+var ErrNoDatabase = errors.New("database not configured")
+
+// Ping check that the database answers, the check is abandoned when ctx is done
+func Ping(ctx context.Context) error {
+	if DB == nil || DB.DB() == nil {
+		return ErrNoDatabase
+	}
+	return DB.DB().PingContext(ctx)
+}
+
+// CheckMigration check that schema_migrations can be read and, unless version is empty, that it holds version
+func CheckMigration(ctx context.Context, version string) error {
+	if DB == nil {
+		return ErrNoDatabase
+	}
+
+	var count int
+	db := contextDB(ctx).Model(&model.SchemaMigrations{})
+	if version != "" {
+		db = db.Where("version = ?", version)
+	}
+	if err := db.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if version != "" && count == 0 {
+		return fmt.Errorf("migration %s is not applied", version)
+	}
+	return nil
+}
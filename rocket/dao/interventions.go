@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllInterventions(ctx context.Context, page, pagesize int64, order string) (results []*model.Interventions, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Interventions{})
+	resultOrm := scopeQuery(ctx, "interventions", model.RetrieveMany, contextDB(ctx).Model(&model.Interventions{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllInterventions(ctx context.Context, page, pagesize int64, order string
 // error - ErrNotFound, db Find error
 func GetInterventions(ctx context.Context, argID int64) (record *model.Interventions, err error) {
 	record = &model.Interventions{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "interventions", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddInterventions is a function to add a single record to interventions table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddInterventions(ctx context.Context, record *model.Interventions) (result *model.Interventions, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "interventions", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "interventions", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddInterventions(ctx context.Context, record *model.Interventions) (result
 func UpdateInterventions(ctx context.Context, argID int64, updated *model.Interventions) (result *model.Interventions, RowsAffected int64, err error) {
 
 	result = &model.Interventions{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "interventions", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "interventions", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "interventions", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateInterventions(ctx context.Context, argID int64, updated *model.Interv
 func DeleteInterventions(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Interventions{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "interventions", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "interventions", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllLeads(ctx context.Context, page, pagesize int64, order string) (results []*model.Leads, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Leads{})
+	resultOrm := scopeQuery(ctx, "leads", model.RetrieveMany, contextDB(ctx).Model(&model.Leads{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllLeads(ctx context.Context, page, pagesize int64, order string) (resul
 // error - ErrNotFound, db Find error
 func GetLeads(ctx context.Context, argID int64) (record *model.Leads, err error) {
 	record = &model.Leads{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "leads", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddLeads is a function to add a single record to leads table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddLeads(ctx context.Context, record *model.Leads) (result *model.Leads, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "leads", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "leads", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddLeads(ctx context.Context, record *model.Leads) (result *model.Leads, Ro
 func UpdateLeads(ctx context.Context, argID int64, updated *model.Leads) (result *model.Leads, RowsAffected int64, err error) {
 
 	result = &model.Leads{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "leads", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "leads", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "leads", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateLeads(ctx context.Context, argID int64, updated *model.Leads) (result
 func DeleteLeads(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Leads{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "leads", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "leads", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
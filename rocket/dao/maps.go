@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllMaps(ctx context.Context, page, pagesize int64, order string) (results []*model.Maps, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Maps{})
+	resultOrm := scopeQuery(ctx, "maps", model.RetrieveMany, contextDB(ctx).Model(&model.Maps{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllMaps(ctx context.Context, page, pagesize int64, order string) (result
 // error - ErrNotFound, db Find error
 func GetMaps(ctx context.Context, argID int64) (record *model.Maps, err error) {
 	record = &model.Maps{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "maps", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddMaps is a function to add a single record to maps table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddMaps(ctx context.Context, record *model.Maps) (result *model.Maps, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "maps", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "maps", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddMaps(ctx context.Context, record *model.Maps) (result *model.Maps, RowsA
 func UpdateMaps(ctx context.Context, argID int64, updated *model.Maps) (result *model.Maps, RowsAffected int64, err error) {
 
 	result = &model.Maps{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "maps", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "maps", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "maps", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateMaps(ctx context.Context, argID int64, updated *model.Maps) (result *
 func DeleteMaps(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Maps{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "maps", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "maps", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
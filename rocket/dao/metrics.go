@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"rocket/metrics"
+)
+
+const queryStartKey = "metrics:query_start"
+
+var (
+	queryDuration = metrics.NewHistogramVec("rocket_db_query_duration_seconds", "Duration of the sql statements run by the dao by table and statement.",
+		metrics.DefaultBuckets, "table", "statement")
+	queryErrors = metrics.NewCounterVec("rocket_db_query_errors_total", "Sql statements run by the dao that failed by table and statement, a record not found is not an error.",
+		"table", "statement")
+)
+
+func init() {
+	poolStat := func(fn func(stats *sql.DBStats) float64) func() float64 {
+		return func() float64 {
+			if DB == nil || DB.DB() == nil {
+				return 0
+			}
+			stats := DB.DB().Stats()
+			return fn(&stats)
+		}
+	}
+
+	metrics.NewGaugeFunc("rocket_db_max_open_connections", "Maximum number of open connections to the database.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	metrics.NewGaugeFunc("rocket_db_open_connections", "Established connections to the database, in use and idle.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	metrics.NewGaugeFunc("rocket_db_in_use_connections", "Connections to the database currently in use.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.InUse) }))
+	metrics.NewGaugeFunc("rocket_db_idle_connections", "Idle connections to the database.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.Idle) }))
+	metrics.NewCounterFunc("rocket_db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.WaitCount) }))
+	metrics.NewCounterFunc("rocket_db_wait_duration_seconds_total", "Time spent waiting for a connection of the pool.",
+		poolStat(func(s *sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	metrics.NewCounterFunc("rocket_db_max_idle_closed_total", "Connections closed because the pool held too many idle connections.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	metrics.NewCounterFunc("rocket_db_max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime.",
+		poolStat(func(s *sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+
+	metrics.NewInfoFunc("rocket_build_info", "Build of the running server, the value is always 1.", func() []string {
+		info := AppBuildInfo
+		if info == nil {
+			info = &BuildInfo{}
+		}
+		return []string{
+			"build_date", info.BuildDate,
+			"commit", info.LatestCommit,
+			"build_number", info.BuildNumber,
+			"runtime_version", info.RuntimeVer,
+			"built_on_os", info.BuiltOnOs,
+		}
+	})
+}
+
+// InstrumentQueries time the create, query, update, delete and raw statements run through db, count their errors by table and
+// pass them to Logger
+func InstrumentQueries(db *gorm.DB) {
+	callbacks := db.Callback()
+	instrument(callbacks.Create, "create", "gorm:begin_transaction", "gorm:commit_or_rollback_transaction")
+	instrument(callbacks.Update, "update", "gorm:begin_transaction", "gorm:commit_or_rollback_transaction")
+	instrument(callbacks.Delete, "delete", "gorm:begin_transaction", "gorm:commit_or_rollback_transaction")
+	instrument(callbacks.Query, "select", "gorm:query", "gorm:after_query")
+	instrument(callbacks.RowQuery, "row_query", "gorm:row_query", "gorm:row_query")
+}
+
+// instrument register callbacks starting the clock before the first callback of the processor and observing after its last,
+// each registration needs its own processor as gorm keeps the one it is registered with
+func instrument(processor func() *gorm.CallbackProcessor, statement, first, last string) {
+	processor().Before(first).Register("metrics:before_"+statement, func(scope *gorm.Scope) {
+		scope.Set(queryStartKey, time.Now())
+	})
+
+	processor().After(last).Register("metrics:after_"+statement, func(scope *gorm.Scope) {
+		value, ok := scope.Get(queryStartKey)
+		if !ok {
+			return
+		}
+
+		elapsed := time.Since(value.(time.Time))
+		table := queryTable(scope)
+		err := scope.DB().Error
+		if gorm.IsRecordNotFoundError(err) {
+			err = nil
+		}
+
+		observeQuery(queryContext(scope), &Query{Table: table, Statement: statement, SQL: scope.SQL, Elapsed: elapsed,
+			RowsAffected: scope.DB().RowsAffected, Err: err})
+	})
+}
+
+// observeQuery record the duration and error of a completed statement and pass it to Logger, statements run outside of gorm,
+// ie by ExecuteStatement, call it themselves
+func observeQuery(ctx context.Context, query *Query) {
+	queryDuration.Observe(query.Elapsed.Seconds(), query.Table, query.Statement)
+	if query.Err != nil {
+		queryErrors.Inc(query.Table, query.Statement)
+	}
+	if Logger != nil {
+		Logger(ctx, query)
+	}
+}
+
+// queryTable the table of the statement of scope, empty for raw sql not bound to a model or table
+func queryTable(scope *gorm.Scope) (table string) {
+	if scope.Value == nil && scope.Search == nil {
+		return ""
+	}
+
+	defer func() {
+		if recover() != nil {
+			table = ""
+		}
+	}()
+	return scope.TableName()
+}
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rocket/logging"
+)
+
+// LogQueries LogSql writing statements to the default logger with their elapsed time and rows affected. Failed statements are
+// logged as errors, statements taking longer than slow as warnings flagged slow, 0 never flags a statement, and the others at
+// debug level when all is set.
+func LogQueries(slow time.Duration, all bool) LogSql {
+	return func(ctx context.Context, query *Query) {
+		isSlow := slow > 0 && query.Elapsed > slow
+		level := logging.LevelDebug
+		switch {
+		case query.Err != nil:
+			level = logging.LevelError
+		case isSlow:
+			level = logging.LevelWarn
+		case !all:
+			return
+		}
+
+		fields := logging.Fields{
+			"table":         query.Table,
+			"statement":     query.Statement,
+			"sql":           query.SQL,
+			"elapsed_ms":    float64(query.Elapsed.Microseconds()) / 1000,
+			"rows_affected": query.RowsAffected,
+		}
+		if isSlow {
+			fields["slow"] = true
+		}
+		if query.Err != nil {
+			fields["error"] = query.Err
+		}
+		logging.Default.Log(ctx, level, "sql", fields)
+	}
+}
+
+// GormLogger gorm logger writing its messages, ie registered callbacks and failed statements, to the default logger, set it
+// with SetLogger before InstrumentQueries. Sql statements are left to LogQueries.
+type GormLogger struct{}
+
+// Print log a message of gorm, v starts with its level
+func (GormLogger) Print(v ...interface{}) {
+	if len(v) < 2 {
+		return
+	}
+
+	kind, _ := v[0].(string)
+	switch kind {
+	case "info", "warning":
+		msg := fmt.Sprint(v[1:]...)
+		msg = strings.TrimPrefix(strings.TrimPrefix(msg, "[info] "), "[warning] ")
+		level := logging.LevelDebug
+		if kind == "warning" {
+			level = logging.LevelWarn
+		}
+		logging.Default.Log(context.Background(), level, "gorm: "+msg, nil)
+	case "error", "log":
+		logging.Error(context.Background(), "gorm: "+fmt.Sprint(v[2:]...), logging.Fields{"source": v[1]})
+	}
+}
@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllQuotes(ctx context.Context, page, pagesize int64, order string) (results []*model.Quotes, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Quotes{})
+	resultOrm := scopeQuery(ctx, "quotes", model.RetrieveMany, contextDB(ctx).Model(&model.Quotes{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllQuotes(ctx context.Context, page, pagesize int64, order string) (resu
 // error - ErrNotFound, db Find error
 func GetQuotes(ctx context.Context, argID int64) (record *model.Quotes, err error) {
 	record = &model.Quotes{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "quotes", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddQuotes is a function to add a single record to quotes table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddQuotes(ctx context.Context, record *model.Quotes) (result *model.Quotes, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "quotes", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "quotes", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddQuotes(ctx context.Context, record *model.Quotes) (result *model.Quotes,
 func UpdateQuotes(ctx context.Context, argID int64, updated *model.Quotes) (result *model.Quotes, RowsAffected int64, err error) {
 
 	result = &model.Quotes{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "quotes", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "quotes", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "quotes", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateQuotes(ctx context.Context, argID int64, updated *model.Quotes) (resu
 func DeleteQuotes(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Quotes{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "quotes", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "quotes", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
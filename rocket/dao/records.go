@@ -0,0 +1,403 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"rocket/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Records constructors of the record struct of every table, used by the table independent export and import
+var Records = map[string]func() model.Model{
+	"active_admin_comments":      func() model.Model { return &model.ActiveAdminComments{} },
+	"active_storage_attachments": func() model.Model { return &model.ActiveStorageAttachments{} },
+	"active_storage_blobs":       func() model.Model { return &model.ActiveStorageBlobs{} },
+	"addresses":                  func() model.Model { return &model.Addresses{} },
+	"admin_users":                func() model.Model { return &model.AdminUsers{} },
+	"ar_internal_metadata":       func() model.Model { return &model.ArInternalMetadata{} },
+	"audit_logs":                 func() model.Model { return &model.AuditLogs{} },
+	"batteries":                  func() model.Model { return &model.Batteries{} },
+	"blazer_audits":              func() model.Model { return &model.BlazerAudits{} },
+	"blazer_checks":              func() model.Model { return &model.BlazerChecks{} },
+	"blazer_dashboard_queries":   func() model.Model { return &model.BlazerDashboardQueries{} },
+	"blazer_dashboards":          func() model.Model { return &model.BlazerDashboards{} },
+	"blazer_queries":             func() model.Model { return &model.BlazerQueries{} },
+	"building_details":           func() model.Model { return &model.BuildingDetails{} },
+	"buildings":                  func() model.Model { return &model.Buildings{} },
+	"columns":                    func() model.Model { return &model.Columns{} },
+	"customers":                  func() model.Model { return &model.Customers{} },
+	"elevators":                  func() model.Model { return &model.Elevators{} },
+	"employees":                  func() model.Model { return &model.Employees{} },
+	"interventions":              func() model.Model { return &model.Interventions{} },
+	"leads":                      func() model.Model { return &model.Leads{} },
+	"maps":                       func() model.Model { return &model.Maps{} },
+	"quotes":                     func() model.Model { return &model.Quotes{} },
+	"schema_migrations":          func() model.Model { return &model.SchemaMigrations{} },
+	"users":                      func() model.Model { return &model.Users_{} },
+	"webhook_deliveries":         func() model.Model { return &model.WebhookDeliveries{} },
+	"webhook_subscriptions":      func() model.Model { return &model.WebhookSubscriptions{} },
+}
+
+// RecordFilter restricts StreamRecords and GetAllRecords results, Where holds column name to value equality conditions,
+// a nil value matches NULL
+type RecordFilter struct {
+	Where map[string]interface{}
+
+	// In column name to values conditions, a record matches when its column holds one of the values
+	In map[string][]interface{}
+
+	// Order db sort order, comma separated column names each optionally followed by asc or desc
+	Order string
+
+	// Limit most records read by StreamRecords, every matching record is read when 0
+	Limit int64
+}
+
+// ImportRow a row of an import, Data is the json object of the columns present in the row
+type ImportRow struct {
+	Line int
+	Data []byte
+
+	// Err set when the row could not be read, the row is reported as failed without being written
+	Err *ImportError
+}
+
+// ImportError a row of an import that failed, Column is empty when the error is not specific to a column
+type ImportError struct {
+	Row     int    `json:"row" example:"3"`
+	Column  string `json:"column,omitempty" example:"status"`
+	Message string `json:"message" example:"invalid value"`
+}
+
+// ImportResult outcome of an import, nothing is written when DryRun is set or a row failed
+type ImportResult struct {
+	DryRun    bool           `json:"dry_run"`
+	Committed bool           `json:"committed"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Failed    int            `json:"failed"`
+	Errors    []*ImportError `json:"errors"`
+}
+
+// ImportAuthorizer checks the caller may perform action on the table being imported, called once per row
+type ImportAuthorizer func(action model.Action) error
+
+type importedChange struct {
+	action        model.Action
+	before, after interface{}
+}
+
+// StreamRecords is a function to call fn with every record of table matching filter, rows are read one at a time
+// error - ErrNotFound, unknown table or db query error
+// error - ErrBadParams, unknown filter or order column
+func StreamRecords(ctx context.Context, table string, filter *RecordFilter, fn func(record model.Model) error) (err error) {
+	newRecord, ok := Records[table]
+	if !ok {
+		return ErrNotFound
+	}
+
+	resultOrm, err := filterQuery(ctx, table, newRecord(), filter)
+	if err != nil {
+		return err
+	}
+
+	if filter.Limit > 0 {
+		resultOrm = resultOrm.Limit(filter.Limit)
+	}
+
+	rows, err := resultOrm.Rows()
+	if err != nil {
+		return ErrNotFound
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := newRecord()
+		if err = DB.ScanRows(rows, record); err != nil {
+			return err
+		}
+
+		if err = fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetAllRecords is a function to get a slice of the record(s) of table matching filter
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// error - ErrNotFound, unknown table or db Find error
+// error - ErrBadParams, unknown filter or order column
+func GetAllRecords(ctx context.Context, table string, filter *RecordFilter, page, pagesize int64) (results []model.Model, totalRows int, err error) {
+	newRecord, ok := Records[table]
+	if !ok {
+		return nil, -1, ErrNotFound
+	}
+
+	sample := newRecord()
+	resultOrm, err := filterQuery(ctx, table, sample, filter)
+	if err != nil {
+		return nil, -1, err
+	}
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	records := reflect.New(reflect.SliceOf(reflect.TypeOf(sample)))
+	if err = resultOrm.Find(records.Interface()).Error; err != nil {
+		return nil, -1, ErrNotFound
+	}
+
+	list := records.Elem()
+	results = make([]model.Model, list.Len())
+	for i := range results {
+		results[i] = list.Index(i).Interface().(model.Model)
+	}
+	return results, totalRows, nil
+}
+
+// ImportRecords is a function to create or update the records of table from rows, a row whose primary key matches an existing
+// record updates the columns it contains, other rows are created. Rows are written in a transaction that is only committed
+// when every row succeeded and dryRun is false, change recorders are invoked after the commit.
+// error - ErrNotFound, unknown table
+// error - ErrInsertFailed, transaction could not be started or committed
+func ImportRecords(ctx context.Context, table string, rows []*ImportRow, dryRun bool, allow ImportAuthorizer) (result *ImportResult, err error) {
+	newRecord, ok := Records[table]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	tx := contextDB(ctx).Begin()
+	if tx.Error != nil {
+		return nil, ErrInsertFailed
+	}
+	defer func() {
+		if result == nil || !result.Committed {
+			tx.Rollback()
+		}
+	}()
+
+	result = &ImportResult{DryRun: dryRun, Errors: []*ImportError{}}
+	var changes []*importedChange
+	for _, row := range rows {
+		if row.Err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, row.Err)
+			continue
+		}
+
+		change, err := importRow(ctx, tx, table, newRecord, row, allow)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, &ImportError{Row: row.Line, Message: err.Error()})
+			continue
+		}
+
+		if change.action == model.Create {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		changes = append(changes, change)
+	}
+
+	if dryRun || result.Failed > 0 {
+		return result, nil
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, ErrInsertFailed
+	}
+	result.Committed = true
+
+	for _, change := range changes {
+		recordChange(ctx, table, change.action, change.before, change.after)
+	}
+	return result, nil
+}
+
+// importRow write a single row in tx, an update when its primary key matches an existing record and a create otherwise
+func importRow(ctx context.Context, tx *gorm.DB, table string, newRecord func() model.Model, row *ImportRow, allow ImportAuthorizer) (*importedChange, error) {
+	record := newRecord()
+	if err := json.Unmarshal(row.Data, record); err != nil {
+		return nil, err
+	}
+
+	existing := newRecord()
+	found := false
+	if where, ok := primaryKeyWhere(tx, record.TableInfo(), record); ok {
+		// soft deleted records are looked up as well, the primary key of a trashed record is still taken
+		db := tx.Unscoped().Where(where[0], where[1:]...).First(existing)
+		if db.Error != nil && !db.RecordNotFound() {
+			return nil, db.Error
+		}
+		found = db.Error == nil
+	}
+
+	if found {
+		if err := allow(model.Update); err != nil {
+			return nil, err
+		}
+
+		if err := authorizeRecord(ctx, table, model.Update, existing); err != nil {
+			return nil, err
+		}
+
+		// importing a trashed record restores it, which undoes a delete and takes the same permission as RestoreRecord
+		restore := trashed(existing)
+		if restore {
+			if err := authorizeRecord(ctx, table, model.Delete, existing); err != nil {
+				return nil, err
+			}
+		}
+
+		before := cloneRecord(existing)
+		if err := json.Unmarshal(row.Data, existing); err != nil {
+			return nil, err
+		}
+		if restore {
+			Untrash(existing)
+		}
+
+		existing.Prepare()
+		if err := existing.Validate(model.Update); err != nil {
+			return nil, err
+		}
+
+		// the row may reassign the record, ie to another employee, the new state must be allowed as well
+		if err := authorizeRecord(ctx, table, model.Update, existing); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Unscoped().Save(existing).Error; err != nil {
+			return nil, err
+		}
+		return &importedChange{action: model.Update, before: before, after: existing}, nil
+	}
+
+	if err := allow(model.Create); err != nil {
+		return nil, err
+	}
+
+	record.Prepare()
+	if err := record.Validate(model.Create); err != nil {
+		return nil, err
+	}
+
+	if err := authorizeRecord(ctx, table, model.Create, record); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(record).Error; err != nil {
+		return nil, err
+	}
+	return &importedChange{action: model.Create, after: record}, nil
+}
+
+// filterQuery the scoped query reading the records of table matching filter, column names are validated and quoted
+func filterQuery(ctx context.Context, table string, sample model.Model, filter *RecordFilter) (*gorm.DB, error) {
+	info := sample.TableInfo()
+	resultOrm := scopeQuery(ctx, table, model.RetrieveMany, contextDB(ctx).Model(sample))
+	for name, value := range filter.Where {
+		col := columnNamed(info, name)
+		if col == nil {
+			return nil, ErrBadParams
+		}
+
+		if value == nil {
+			resultOrm = resultOrm.Where(DB.Dialect().Quote(col.Name) + " IS NULL")
+		} else {
+			resultOrm = resultOrm.Where(DB.Dialect().Quote(col.Name)+" = ?", value)
+		}
+	}
+
+	for name, values := range filter.In {
+		col := columnNamed(info, name)
+		if col == nil {
+			return nil, ErrBadParams
+		}
+		resultOrm = resultOrm.Where(DB.Dialect().Quote(col.Name)+" IN (?)", values)
+	}
+
+	if filter.Order != "" {
+		order, err := orderClause(info, filter.Order)
+		if err != nil {
+			return nil, err
+		}
+		resultOrm = resultOrm.Order(order)
+	}
+	return resultOrm, nil
+}
+
+// primaryKeyWhere the condition selecting record by primary key, false when a key column is not set
+func primaryKeyWhere(db *gorm.DB, info *model.TableInfo, record interface{}) ([]interface{}, bool) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	for _, col := range info.Columns {
+		if !col.IsPrimaryKey {
+			continue
+		}
+
+		value, ok := model.ColumnValue(record, col)
+		if !ok || value == nil || reflect.ValueOf(value).IsZero() {
+			return nil, false
+		}
+		clauses = append(clauses, db.Dialect().Quote(col.Name)+" = ?")
+		args = append(args, value)
+	}
+
+	if len(clauses) == 0 {
+		return nil, false
+	}
+	return append([]interface{}{strings.Join(clauses, " AND ")}, args...), true
+}
+
+// columnNamed the column of info with the db or json name name, nil when there is none
+func columnNamed(info *model.TableInfo, name string) *model.ColumnInfo {
+	for _, col := range info.Columns {
+		if col.Name == name || col.JSONFieldName == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// orderClause validate a user supplied sort order against the columns of info and quote the column names
+func orderClause(info *model.TableInfo, order string) (string, error) {
+	var terms []string
+	for _, term := range strings.Split(order, ",") {
+		fields := strings.Fields(term)
+		if len(fields) == 0 || len(fields) > 2 {
+			return "", ErrBadParams
+		}
+
+		col := columnNamed(info, fields[0])
+		if col == nil {
+			return "", ErrBadParams
+		}
+
+		direction := "ASC"
+		if len(fields) == 2 {
+			direction = strings.ToUpper(fields[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", ErrBadParams
+			}
+		}
+		terms = append(terms, fmt.Sprintf("%s %s", DB.Dialect().Quote(col.Name), direction))
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,376 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocket/model"
+)
+
+// ErrDriftUnsupported error when the schema of the database cannot be read for drift detection
+var ErrDriftUnsupported = errors.New("schema drift detection supports mysql and sqlite only")
+
+// ColumnDrift a column whose type or nullability differs from its ColumnInfo
+type ColumnDrift struct {
+	Column   string `json:"column"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// TableDrift the differences between a table of the database and its TableInfo
+type TableDrift struct {
+	Table              string             `json:"table"`
+	MissingTable       bool               `json:"missing_table,omitempty"`
+	MissingColumns     []string           `json:"missing_columns,omitempty"`
+	ExtraColumns       []string           `json:"extra_columns,omitempty"`
+	TypeMismatches     []*ColumnDrift     `json:"type_mismatches,omitempty"`
+	NullableMismatches []*ColumnDrift     `json:"nullable_mismatches,omitempty"`
+	MissingIndexes     []*model.IndexInfo `json:"missing_indexes,omitempty"`
+}
+
+// SchemaDrift the tables of the database that drifted from their TableInfo, tables matching their TableInfo are left out
+type SchemaDrift struct {
+	Dialect   string        `json:"dialect"`
+	CheckedAt time.Time     `json:"checked_at"`
+	InSync    bool          `json:"in_sync"`
+	Tables    []*TableDrift `json:"tables"`
+}
+
+// liveColumn a column as the database reports it, Type is lower case without its length
+type liveColumn struct {
+	Name     string
+	Type     string
+	Length   int64
+	Nullable bool
+}
+
+// liveTable the columns, in table order, and indexes of a table as the database reports them
+type liveTable struct {
+	columns []*liveColumn
+	indexes []*model.IndexInfo
+}
+
+// DetectSchemaDrift compare the live schema of tables with their TableInfo, reporting missing and extra columns, type and
+// nullability mismatches and missing indexes. The schema is read from INFORMATION_SCHEMA on mysql and with pragmas on sqlite.
+func DetectSchemaDrift(ctx context.Context, tables []string) (*SchemaDrift, error) {
+	if DB == nil {
+		return nil, ErrNoDatabase
+	}
+
+	dialect := DB.Dialect().GetName()
+
+	var (
+		live map[string]*liveTable
+		err  error
+	)
+	switch dialect {
+	case "mysql":
+		live, err = mysqlSchema(ctx)
+	case "sqlite3":
+		live, err = sqliteSchema(ctx, tables)
+	default:
+		return nil, ErrDriftUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &SchemaDrift{Dialect: dialect, CheckedAt: time.Now().UTC(), Tables: []*TableDrift{}}
+	for _, table := range tables {
+		info, ok := model.GetTableInfo(table)
+		if !ok {
+			continue
+		}
+
+		if tableDrift := compareTable(dialect, info, live[table]); tableDrift != nil {
+			drift.Tables = append(drift.Tables, tableDrift)
+		}
+	}
+	drift.InSync = len(drift.Tables) == 0
+	return drift, nil
+}
+
+// compareTable the drift of a table from info, nil when it matches
+func compareTable(dialect string, info *model.TableInfo, live *liveTable) *TableDrift {
+	drift := &TableDrift{Table: info.Name}
+	if live == nil || len(live.columns) == 0 {
+		drift.MissingTable = true
+		return drift
+	}
+
+	columns := make(map[string]*liveColumn, len(live.columns))
+	for _, col := range live.columns {
+		columns[strings.ToLower(col.Name)] = col
+	}
+
+	expected := make(map[string]bool, len(info.Columns))
+	for _, col := range info.Columns {
+		expected[strings.ToLower(col.Name)] = true
+
+		actual, ok := columns[strings.ToLower(col.Name)]
+		if !ok {
+			drift.MissingColumns = append(drift.MissingColumns, col.Name)
+			continue
+		}
+
+		if want, got := expectedType(col), actualType(actual); !sameType(dialect, col, actual) {
+			drift.TypeMismatches = append(drift.TypeMismatches, &ColumnDrift{Column: col.Name, Expected: want, Actual: got})
+		}
+
+		if nullable := col.Nullable && !col.IsPrimaryKey; nullable != actual.Nullable {
+			drift.NullableMismatches = append(drift.NullableMismatches, &ColumnDrift{
+				Column: col.Name, Expected: nullability(nullable), Actual: nullability(actual.Nullable),
+			})
+		}
+	}
+
+	for _, col := range live.columns {
+		if !expected[strings.ToLower(col.Name)] {
+			drift.ExtraColumns = append(drift.ExtraColumns, col.Name)
+		}
+	}
+
+	for _, index := range info.Indexes {
+		if !hasIndex(live.indexes, index) {
+			drift.MissingIndexes = append(drift.MissingIndexes, index)
+		}
+	}
+
+	if len(drift.MissingColumns) == 0 && len(drift.ExtraColumns) == 0 && len(drift.TypeMismatches) == 0 &&
+		len(drift.NullableMismatches) == 0 && len(drift.MissingIndexes) == 0 {
+		return nil
+	}
+	return drift
+}
+
+// sameType reports if the live column has the type of col, sqlite only keeps the affinity of declared types and does not
+// enforce lengths
+func sameType(dialect string, col *model.ColumnInfo, actual *liveColumn) bool {
+	want := strings.ToLower(col.DatabaseTypeName)
+	if dialect == "sqlite3" {
+		return sqliteAffinity(want) == sqliteAffinity(actual.Type)
+	}
+
+	if want != actual.Type {
+		return false
+	}
+	if (want == "varchar" || want == "char") && col.ColumnLength > 0 && actual.Length > 0 {
+		return col.ColumnLength == actual.Length
+	}
+	return true
+}
+
+func expectedType(col *model.ColumnInfo) string {
+	want := strings.ToLower(col.DatabaseTypeName)
+	if (want == "varchar" || want == "char") && col.ColumnLength > 0 {
+		return fmt.Sprintf("%s(%d)", want, col.ColumnLength)
+	}
+	return want
+}
+
+func actualType(col *liveColumn) string {
+	if (col.Type == "varchar" || col.Type == "char") && col.Length > 0 {
+		return fmt.Sprintf("%s(%d)", col.Type, col.Length)
+	}
+	return col.Type
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "null"
+	}
+	return "not null"
+}
+
+// sqliteAffinity the type affinity sqlite gives a declared type
+func sqliteAffinity(declared string) string {
+	switch t := strings.ToLower(declared); {
+	case strings.Contains(t, "int"):
+		return "integer"
+	case strings.Contains(t, "char"), strings.Contains(t, "clob"), strings.Contains(t, "text"):
+		return "text"
+	case t == "" || strings.Contains(t, "blob"):
+		return "blob"
+	case strings.Contains(t, "real"), strings.Contains(t, "floa"), strings.Contains(t, "doub"):
+		return "real"
+	default:
+		return "numeric"
+	}
+}
+
+// hasIndex reports if an index of indexes covers the columns of index in the same order with the same uniqueness, index names
+// are not compared as they differ between rails and other tools
+func hasIndex(indexes []*model.IndexInfo, index *model.IndexInfo) bool {
+	for _, live := range indexes {
+		if live.Unique == index.Unique && strings.EqualFold(strings.Join(live.Columns, ","), strings.Join(index.Columns, ",")) {
+			return true
+		}
+	}
+	return false
+}
+
+// mysqlSchema read the columns and indexes of every table of the current database
+func mysqlSchema(ctx context.Context) (map[string]*liveTable, error) {
+	tables := make(map[string]*liveTable)
+	table := func(name string) *liveTable {
+		if tables[name] == nil {
+			tables[name] = &liveTable{}
+		}
+		return tables[name]
+	}
+
+	rows, err := contextDB(ctx).Raw(`SELECT TABLE_NAME, COLUMN_NAME, DATA_TYPE, IS_NULLABLE, CHARACTER_MAXIMUM_LENGTH
+		FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME, ORDINAL_POSITION`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			tableName, nullable string
+			col                 liveColumn
+			length              sql.NullInt64
+		)
+		if err := rows.Scan(&tableName, &col.Name, &col.Type, &nullable, &length); err != nil {
+			return nil, err
+		}
+		col.Type, col.Length, col.Nullable = strings.ToLower(col.Type), length.Int64, nullable == "YES"
+		table(tableName).columns = append(table(tableName).columns, &col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexRows, err := contextDB(ctx).Raw(`SELECT TABLE_NAME, INDEX_NAME, COLUMN_NAME, NON_UNIQUE
+		FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND INDEX_NAME <> 'PRIMARY'
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	var last *model.IndexInfo
+	for indexRows.Next() {
+		var (
+			tableName, indexName, column string
+			nonUnique                    int
+		)
+		if err := indexRows.Scan(&tableName, &indexName, &column, &nonUnique); err != nil {
+			return nil, err
+		}
+
+		t := table(tableName)
+		if last == nil || last.Name != indexName || len(t.indexes) == 0 || t.indexes[len(t.indexes)-1] != last {
+			last = &model.IndexInfo{Name: indexName, Unique: nonUnique == 0}
+			t.indexes = append(t.indexes, last)
+		}
+		last.Columns = append(last.Columns, column)
+	}
+	return tables, indexRows.Err()
+}
+
+// sqliteSchema read the columns and indexes of tables with the table_info, index_list and index_info pragmas
+func sqliteSchema(ctx context.Context, tables []string) (map[string]*liveTable, error) {
+	schema := make(map[string]*liveTable, len(tables))
+	quote := DB.Dialect().Quote
+
+	for _, name := range tables {
+		columns, err := pragma(ctx, "table_info("+quote(name)+")")
+		if err != nil {
+			return nil, err
+		}
+
+		t := &liveTable{}
+		for _, row := range columns {
+			declared := strings.ToLower(asString(row["type"]))
+			col := &liveColumn{Name: asString(row["name"]), Type: declared}
+			if i := strings.Index(declared, "("); i >= 0 {
+				col.Type = strings.TrimSpace(declared[:i])
+				col.Length, _ = strconv.ParseInt(strings.Trim(declared[i:], "() "), 10, 64)
+			}
+			col.Nullable = asInt(row["notnull"]) == 0 && asInt(row["pk"]) == 0
+			t.columns = append(t.columns, col)
+		}
+
+		indexes, err := pragma(ctx, "index_list("+quote(name)+")")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range indexes {
+			if asString(row["origin"]) == "pk" {
+				continue
+			}
+
+			index := &model.IndexInfo{Name: asString(row["name"]), Unique: asInt(row["unique"]) != 0}
+			info, err := pragma(ctx, "index_info("+quote(index.Name)+")")
+			if err != nil {
+				return nil, err
+			}
+
+			sort.Slice(info, func(i, j int) bool { return asInt(info[i]["seqno"]) < asInt(info[j]["seqno"]) })
+			for _, col := range info {
+				index.Columns = append(index.Columns, asString(col["name"]))
+			}
+			t.indexes = append(t.indexes, index)
+		}
+		schema[name] = t
+	}
+	return schema, nil
+}
+
+// pragma run a sqlite pragma returning its rows by column name
+func pragma(ctx context.Context, statement string) ([]map[string]interface{}, error) {
+	rows, err := contextDB(ctx).Raw("PRAGMA " + statement).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		pointers := make([]interface{}, len(names))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(names))
+		for i, name := range names {
+			row[name] = values[i]
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+func asString(v interface{}) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func asInt(v interface{}) int64 {
+	i, _ := strconv.ParseInt(asString(v), 10, 64)
+	return i
+}
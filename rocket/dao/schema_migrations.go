@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllSchemaMigrations(ctx context.Context, page, pagesize int64, order string) (results []*model.SchemaMigrations, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.SchemaMigrations{})
+	resultOrm := scopeQuery(ctx, "schema_migrations", model.RetrieveMany, contextDB(ctx).Model(&model.SchemaMigrations{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllSchemaMigrations(ctx context.Context, page, pagesize int64, order str
 // error - ErrNotFound, db Find error
 func GetSchemaMigrations(ctx context.Context, argVersion string) (record *model.SchemaMigrations, err error) {
 	record = &model.SchemaMigrations{}
-	if err = DB.First(record, argVersion).Error; err != nil {
+	if err = contextDB(ctx).First(record, argVersion).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "schema_migrations", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddSchemaMigrations is a function to add a single record to schema_migrations table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddSchemaMigrations(ctx context.Context, record *model.SchemaMigrations) (result *model.SchemaMigrations, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "schema_migrations", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "schema_migrations", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddSchemaMigrations(ctx context.Context, record *model.SchemaMigrations) (r
 func UpdateSchemaMigrations(ctx context.Context, argVersion string, updated *model.SchemaMigrations) (result *model.SchemaMigrations, RowsAffected int64, err error) {
 
 	result = &model.SchemaMigrations{}
-	db := DB.First(result, argVersion)
+	db := contextDB(ctx).First(result, argVersion)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "schema_migrations", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "schema_migrations", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "schema_migrations", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateSchemaMigrations(ctx context.Context, argVersion string, updated *mod
 func DeleteSchemaMigrations(ctx context.Context, argVersion string) (rowsAffected int64, err error) {
 
 	record := &model.SchemaMigrations{}
-	db := DB.First(record, argVersion)
+	db := contextDB(ctx).First(record, argVersion)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "schema_migrations", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "schema_migrations", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"rocket/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SearchField a column searched by Search, matches in columns with a higher Weight rank first
+type SearchField struct {
+	Column string
+	Weight float64
+}
+
+// SearchHit a record matching every term of a search
+type SearchHit struct {
+	Table  string
+	Record model.Model
+	Score  float64
+
+	// Matches columns containing at least one of the terms
+	Matches []string
+}
+
+// likeEscaper escape LIKE wildcards with the ! escape character, it is declared in the query so it works on every dialect
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_", "[", "![")
+
+// Search is a function to find the records of table where every term appears in at least one of fields, case insensitive,
+// at most limit hits are returned, best ranked first
+// error - ErrNotFound, unknown table, column or db Find error
+func Search(ctx context.Context, table string, fields []SearchField, terms []string, limit int) (hits []*SearchHit, err error) {
+	newRecord, ok := Records[table]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	sample := newRecord()
+	info := sample.TableInfo()
+	columns := make([]*model.ColumnInfo, len(fields))
+	for i, field := range fields {
+		if columns[i] = columnNamed(info, field.Column); columns[i] == nil {
+			return nil, ErrNotFound
+		}
+	}
+
+	resultOrm := scopeQuery(ctx, table, model.RetrieveMany, contextDB(ctx).Model(sample))
+	for _, term := range terms {
+		var (
+			clauses []string
+			args    []interface{}
+		)
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		for _, col := range columns {
+			clauses = append(clauses, fmt.Sprintf("LOWER(%s) LIKE ? ESCAPE '!'", DB.Dialect().Quote(col.Name)))
+			args = append(args, pattern)
+		}
+		resultOrm = resultOrm.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+
+	// the best ranked candidates are read, ties in primary key order so the limit cuts them the same way SortSearchHits does
+	resultOrm = resultOrm.Order(searchScoreSQL(columns, fields, terms))
+	for _, col := range info.Columns {
+		if col.IsPrimaryKey {
+			resultOrm = resultOrm.Order(DB.Dialect().Quote(col.Name))
+		}
+	}
+
+	records := reflect.New(reflect.SliceOf(reflect.TypeOf(sample)))
+	if err = resultOrm.Limit(limit).Find(records.Interface()).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	list := records.Elem()
+	for i := 0; i < list.Len(); i++ {
+		record := list.Index(i).Interface().(model.Model)
+		hit := &SearchHit{Table: table, Record: record}
+		for j, col := range columns {
+			value, _ := model.ColumnValue(record, col)
+			text, _ := value.(string)
+			score := 0.0
+			for _, term := range terms {
+				score += termScore(strings.ToLower(text), term)
+			}
+
+			if score > 0 {
+				hit.Score += score * fields[j].Weight
+				hit.Matches = append(hit.Matches, col.JSONFieldName)
+			}
+		}
+
+		// LOWER of the database may fold case differently, a record the terms do not match here is not a hit
+		if hit.Score > 0 {
+			hits = append(hits, hit)
+		}
+	}
+
+	SortSearchHits(hits)
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// SortSearchHits order hits best first, ties are ordered by table and primary key so pages are stable
+func SortSearchHits(hits []*SearchHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Table != hits[j].Table {
+			return hits[i].Table < hits[j].Table
+		}
+		return PrimaryKey(hits[i].Table, hits[i].Record) < PrimaryKey(hits[j].Table, hits[j].Record)
+	})
+}
+
+const (
+	scoreExact      = 10
+	scorePrefix     = 6
+	scoreWordPrefix = 4
+	scoreSubstring  = 2
+)
+
+// termScore how well term matches text, a whole value beats a prefix, a word prefix and a substring in that order
+func termScore(text, term string) float64 {
+	switch {
+	case text == term:
+		return scoreExact
+	case strings.HasPrefix(text, term):
+		return scorePrefix
+	case strings.Contains(" "+text, " "+term) || strings.Contains(text, "@"+term) || strings.Contains(text, "-"+term):
+		return scoreWordPrefix
+	case strings.Contains(text, term):
+		return scoreSubstring
+	default:
+		return 0
+	}
+}
+
+// searchScoreSQL the ORDER BY expression ranking records best first, the weighted sum of termScore of every column and term
+func searchScoreSQL(columns []*model.ColumnInfo, fields []SearchField, terms []string) *gorm.SqlExpr {
+	var (
+		sums []string
+		args []interface{}
+	)
+	for i, col := range columns {
+		name := "LOWER(" + DB.Dialect().Quote(col.Name) + ")"
+		for _, term := range terms {
+			escaped := likeEscaper.Replace(term)
+			sums = append(sums, fmt.Sprintf("(CASE WHEN %[1]s = ? THEN %[2]d WHEN %[1]s LIKE ? ESCAPE '!' THEN %[3]d "+
+				"WHEN %[1]s LIKE ? ESCAPE '!' OR %[1]s LIKE ? ESCAPE '!' OR %[1]s LIKE ? ESCAPE '!' THEN %[4]d "+
+				"WHEN %[1]s LIKE ? ESCAPE '!' THEN %[5]d ELSE 0 END) * ?",
+				name, scoreExact, scorePrefix, scoreWordPrefix, scoreSubstring))
+			args = append(args, term, escaped+"%", "% "+escaped+"%", "%@"+escaped+"%", "%-"+escaped+"%", "%"+escaped+"%", fields[i].Weight)
+		}
+	}
+
+	return gorm.Expr("("+strings.Join(sums, " + ")+") DESC", args...)
+}
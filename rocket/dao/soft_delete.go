@@ -0,0 +1,184 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+	"github.com/jinzhu/gorm"
+)
+
+// softDeleteModels the tables whose model has a deleted_at column, they soft delete once ConfigureSoftDelete enables them
+var softDeleteModels = map[string]func() model.Model{
+	"addresses":        func() model.Model { return &model.Addresses{} },
+	"batteries":        func() model.Model { return &model.Batteries{} },
+	"building_details": func() model.Model { return &model.BuildingDetails{} },
+	"buildings":        func() model.Model { return &model.Buildings{} },
+	"columns":          func() model.Model { return &model.Columns{} },
+	"customers":        func() model.Model { return &model.Customers{} },
+	"elevators":        func() model.Model { return &model.Elevators{} },
+	"employees":        func() model.Model { return &model.Employees{} },
+	"interventions":    func() model.Model { return &model.Interventions{} },
+	"leads":            func() model.Model { return &model.Leads{} },
+	"quotes":           func() model.Model { return &model.Quotes{} },
+}
+
+// SoftDeleteTables tables enabled by ConfigureSoftDelete, Delete only marks their records deleted and every dao read skips them.
+// It is empty unless tables are enabled, the records of the other tables are deleted for good.
+var SoftDeleteTables = map[string]func() model.Model{}
+
+// SoftDeletable the tables whose model can soft delete, sorted by name
+func SoftDeletable() []string {
+	tables := make([]string, 0, len(softDeleteModels))
+	for table := range softDeleteModels {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// ConfigureSoftDelete enable soft delete for the tables whose deleted_at column exists in db, ie once the
+// add_deleted_at_for_soft_delete migration ran, and register the callbacks keeping every other table from reading or writing
+// deleted_at. It returns the enabled tables, tables without the column are left out.
+// error - a table does not soft delete
+func ConfigureSoftDelete(db *gorm.DB, tables []string) (enabled []string, err error) {
+	SoftDeleteTables = map[string]func() model.Model{}
+	for _, table := range tables {
+		newRecord, ok := softDeleteModels[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s does not soft delete", table)
+		}
+
+		if db.Dialect().HasColumn(table, "deleted_at") {
+			SoftDeleteTables[table] = newRecord
+			enabled = append(enabled, table)
+		}
+	}
+
+	callbacks := db.Callback()
+	callbacks.Create().Before("gorm:begin_transaction").Register("soft_delete:create", softDeleteScope)
+	callbacks.Update().Before("gorm:begin_transaction").Register("soft_delete:update", softDeleteScope)
+	callbacks.Delete().Before("gorm:begin_transaction").Register("soft_delete:delete", softDeleteScope)
+	callbacks.Query().Before("gorm:query").Register("soft_delete:query", softDeleteScope)
+	callbacks.RowQuery().Before("gorm:row_query").Register("soft_delete:row_query", softDeleteScope)
+	return enabled, nil
+}
+
+// softDeleteScope gorm soft deletes every model with a DeletedAt field, statements on the tables not enabled are unscoped so
+// they read every row and delete for good, and deleted_at is left out of their inserts and updates as the column may not exist
+func softDeleteScope(scope *gorm.Scope) {
+	if _, ok := scope.FieldByName("DeletedAt"); !ok {
+		return
+	}
+
+	if _, ok := SoftDeleteTables[scope.TableName()]; ok {
+		return
+	}
+
+	scope.Search.Unscoped = true
+	scope.Search.Omit(append(scope.OmitAttrs(), "deleted_at")...)
+}
+
+// GetAllTrash is a function to get a slice of the soft deleted record(s) of table, most recently deleted first
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// error - ErrNotFound, table does not soft delete or db Find error
+func GetAllTrash(ctx context.Context, table string, page, pagesize int64) (results interface{}, totalRows int, err error) {
+	newRecord, ok := SoftDeleteTables[table]
+	if !ok {
+		return nil, -1, ErrNotFound
+	}
+
+	sample := newRecord()
+	resultOrm := scopeQuery(ctx, table, model.RetrieveMany, contextDB(ctx).Unscoped().Model(sample)).Where("deleted_at IS NOT NULL")
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	records := reflect.New(reflect.SliceOf(reflect.TypeOf(sample)))
+	if err = resultOrm.Order("deleted_at DESC").Find(records.Interface()).Error; err != nil {
+		err = ErrNotFound
+		return nil, -1, err
+	}
+
+	return records.Elem().Interface(), totalRows, nil
+}
+
+// RestoreRecord is a function to undo the soft delete of a record of table
+// error - ErrNotFound, table does not soft delete or no deleted record for id
+// error - ErrUpdateFailed, db update failed
+func RestoreRecord(ctx context.Context, table string, argID int64) (result model.Model, err error) {
+	newRecord, ok := SoftDeleteTables[table]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	result = newRecord()
+	db := contextDB(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(result, argID)
+	if err = db.Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	before := cloneRecord(result)
+
+	// restoring undoes a delete, so it takes the same permission
+	if err = authorizeRecord(ctx, table, model.Delete, result); err != nil {
+		return nil, err
+	}
+
+	if err = contextDB(ctx).Unscoped().Model(result).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return nil, ErrUpdateFailed
+	}
+
+	recordChange(ctx, table, model.Update, before, result)
+	return result, nil
+}
+
+// PurgeTrash is a function to permanently delete the records of every soft delete table deleted before cutoff
+// error - ErrDeleteFailed, db Delete failed for at least one table, the other tables are still purged
+func PurgeTrash(ctx context.Context, cutoff time.Time) (rowsAffected int64, err error) {
+	for _, newRecord := range SoftDeleteTables {
+		if ctx.Err() != nil {
+			return rowsAffected, ctx.Err()
+		}
+
+		db := contextDB(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(newRecord())
+		if db.Error != nil {
+			err = ErrDeleteFailed
+			continue
+		}
+		rowsAffected += db.RowsAffected
+	}
+
+	return rowsAffected, err
+}
+
+// trashed reports if record was soft deleted
+func trashed(record interface{}) bool {
+	field := reflect.Indirect(reflect.ValueOf(record)).FieldByName("DeletedAt")
+	if !field.IsValid() {
+		return false
+	}
+
+	deletedAt, ok := field.Interface().(null.Time)
+	return ok && deletedAt.Valid
+}
+
+// Untrash clear the deleted_at of record, ie a soft deleted record before it is saved or a record written by a client.
+// Clients may not soft delete or restore by writing deleted_at, Delete and restore are authorized and audited as such.
+func Untrash(record interface{}) {
+	field := reflect.Indirect(reflect.ValueOf(record)).FieldByName("DeletedAt")
+	if field.IsValid() && field.CanSet() {
+		field.Set(reflect.ValueOf(null.Time{}))
+	}
+}
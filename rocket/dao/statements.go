@@ -0,0 +1,135 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"rocket/sqlscript"
+)
+
+// ErrNotReadOnly error when a statement run by ExecuteStatement is not a single read only statement
+var ErrNotReadOnly = errors.New("only a single select statement may be executed")
+
+// readOnlyKeywords the first keywords of the statements ExecuteStatement runs
+var readOnlyKeywords = map[string]bool{"select": true, "with": true, "show": true, "explain": true, "describe": true, "desc": true}
+
+// StatementResult holds the columns and rows returned from executing a raw sql statement
+type StatementResult struct {
+	Columns    []string        `json:"columns"`
+	Rows       [][]interface{} `json:"rows"`
+	DurationMS float64         `json:"duration_ms"`
+}
+
+// ExecuteStatement is a function to run a raw sql statement such as a blazer_queries statement and collect the results.
+// Only a single select statement is run, in a read only transaction that is rolled back, so a statement cannot write.
+// The statement is bound to ctx, so a deadline on ctx cancels the query in the database driver.
+// params - maxRows - maximum number of rows collected, <= 0 collects every row
+// error - ErrNotReadOnly, the statement is not a single select statement
+// error - db Query error, ctx.Err() when the statement was cancelled or timed out
+func ExecuteStatement(ctx context.Context, statement string, maxRows int) (result *StatementResult, err error) {
+	if strings.TrimSpace(statement) == "" {
+		return nil, ErrBadParams
+	}
+
+	statements := sqlscript.Split(statement)
+	if len(statements) != 1 || !readOnlyStatement(statements[0]) {
+		return nil, ErrNotReadOnly
+	}
+
+	// the statement runs on a connection of its own, bypassing the gorm callbacks of InstrumentQueries
+	start := time.Now()
+	defer func() {
+		query := &Query{Statement: "execute", SQL: statements[0], Elapsed: time.Since(start), Err: err}
+		if result != nil {
+			query.RowsAffected = int64(len(result.Rows))
+		}
+		observeQuery(ctx, query)
+	}()
+
+	conn, err := DB.DB().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// the sqlite driver ignores read only transactions, query_only refuses writes on the connection instead. It is reset
+	// without ctx, which may be done by then, before the connection returns to the pool.
+	if DB.Dialect().GetName() == "sqlite3" {
+		if _, err = conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return nil, err
+		}
+		defer conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+	}
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, statements[0])
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	result = &StatementResult{}
+	if result.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		if maxRows > 0 && len(result.Rows) >= maxRows {
+			break
+		}
+
+		values := make([]interface{}, len(result.Columns))
+		dest := make([]interface{}, len(result.Columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		for i, v := range values {
+			// mysql driver returns text columns as []byte, convert for readable json
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+
+	if err = rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	result.DurationMS = float64(time.Since(start)) / float64(time.Millisecond)
+	return result, nil
+}
+
+// readOnlyStatement reports if statement starts with a read only keyword and does not write its result to a file
+func readOnlyStatement(statement string) bool {
+	fields := strings.Fields(strings.ToLower(statement))
+	if len(fields) == 0 || !readOnlyKeywords[strings.TrimLeft(fields[0], "(")] {
+		return false
+	}
+
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "into" && (fields[i+1] == "outfile" || fields[i+1] == "dumpfile") {
+			return false
+		}
+	}
+	return true
+}
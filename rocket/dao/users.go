@@ -23,7 +23,7 @@ var (
 // error - ErrNotFound, db Find error
 func GetAllUsers_(ctx context.Context, page, pagesize int64, order string) (results []*model.Users_, totalRows int, err error) {
 
-	resultOrm := DB.Model(&model.Users_{})
+	resultOrm := scopeQuery(ctx, "users", model.RetrieveMany, contextDB(ctx).Model(&model.Users_{}))
 	resultOrm.Count(&totalRows)
 
 	if page > 0 {
@@ -49,22 +49,31 @@ func GetAllUsers_(ctx context.Context, page, pagesize int64, order string) (resu
 // error - ErrNotFound, db Find error
 func GetUsers_(ctx context.Context, argID int64) (record *model.Users_, err error) {
 	record = &model.Users_{}
-	if err = DB.First(record, argID).Error; err != nil {
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
 		err = ErrNotFound
 		return record, err
 	}
 
+	if err = authorizeRecord(ctx, "users", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
 	return record, nil
 }
 
 // AddUsers_ is a function to add a single record to users table in the rocket_development database
 // error - ErrInsertFailed, db save call failed
 func AddUsers_(ctx context.Context, record *model.Users_) (result *model.Users_, RowsAffected int64, err error) {
-	db := DB.Save(record)
+	if err = authorizeRecord(ctx, "users", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrInsertFailed
 	}
 
+	recordChange(ctx, "users", model.Create, nil, record)
 	return record, db.RowsAffected, nil
 }
 
@@ -74,20 +83,30 @@ func AddUsers_(ctx context.Context, record *model.Users_) (result *model.Users_,
 func UpdateUsers_(ctx context.Context, argID int64, updated *model.Users_) (result *model.Users_, RowsAffected int64, err error) {
 
 	result = &model.Users_{}
-	db := DB.First(result, argID)
+	db := contextDB(ctx).First(result, argID)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrNotFound
 	}
 
+	before := *result
+	if err = authorizeRecord(ctx, "users", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	if err = Copy(result, updated); err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	if err = authorizeRecord(ctx, "users", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
 	db = db.Save(result)
 	if err = db.Error; err != nil {
 		return nil, -1, ErrUpdateFailed
 	}
 
+	recordChange(ctx, "users", model.Update, &before, result)
 	return result, db.RowsAffected, nil
 }
 
@@ -97,15 +116,20 @@ func UpdateUsers_(ctx context.Context, argID int64, updated *model.Users_) (resu
 func DeleteUsers_(ctx context.Context, argID int64) (rowsAffected int64, err error) {
 
 	record := &model.Users_{}
-	db := DB.First(record, argID)
+	db := contextDB(ctx).First(record, argID)
 	if db.Error != nil {
 		return -1, ErrNotFound
 	}
 
+	if err = authorizeRecord(ctx, "users", model.Delete, record); err != nil {
+		return -1, err
+	}
+
 	db = db.Delete(record)
 	if err = db.Error; err != nil {
 		return -1, ErrDeleteFailed
 	}
 
+	recordChange(ctx, "users", model.Delete, record, nil)
 	return db.RowsAffected, nil
 }
@@ -0,0 +1,135 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+// GetAllWebhookSubscriptions is a function to get a slice of record(s) from webhook_subscriptions table in the rocket_development database
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// params - order    - db sort order column
+// error - ErrNotFound, db Find error
+func GetAllWebhookSubscriptions(ctx context.Context, page, pagesize int64, order string) (results []*model.WebhookSubscriptions, totalRows int, err error) {
+
+	resultOrm := scopeQuery(ctx, "webhook_subscriptions", model.RetrieveMany, contextDB(ctx).Model(&model.WebhookSubscriptions{}))
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	if order != "" {
+		resultOrm = resultOrm.Order(order)
+	}
+
+	if err = resultOrm.Find(&results).Error; err != nil {
+		err = ErrNotFound
+		return nil, -1, err
+	}
+
+	return results, totalRows, nil
+}
+
+// GetWebhookSubscriptions is a function to get a single record from the webhook_subscriptions table in the rocket_development database
+// error - ErrNotFound, db Find error
+func GetWebhookSubscriptions(ctx context.Context, argID int64) (record *model.WebhookSubscriptions, err error) {
+	record = &model.WebhookSubscriptions{}
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
+		err = ErrNotFound
+		return record, err
+	}
+
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// AddWebhookSubscriptions is a function to add a single record to webhook_subscriptions table in the rocket_development database
+// error - ErrInsertFailed, db save call failed
+func AddWebhookSubscriptions(ctx context.Context, record *model.WebhookSubscriptions) (result *model.WebhookSubscriptions, RowsAffected int64, err error) {
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.Create, record); err != nil {
+		return nil, -1, err
+	}
+
+	db := contextDB(ctx).Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrInsertFailed
+	}
+
+	recordChange(ctx, "webhook_subscriptions", model.Create, nil, record)
+	return record, db.RowsAffected, nil
+}
+
+// UpdateWebhookSubscriptions is a function to update a single record from webhook_subscriptions table in the rocket_development database
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db meta data copy failed or db.Save call failed
+func UpdateWebhookSubscriptions(ctx context.Context, argID int64, updated *model.WebhookSubscriptions) (result *model.WebhookSubscriptions, RowsAffected int64, err error) {
+
+	result = &model.WebhookSubscriptions{}
+	db := contextDB(ctx).First(result, argID)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrNotFound
+	}
+
+	before := *result
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
+	if err = Copy(result, updated); err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.Update, result); err != nil {
+		return nil, -1, err
+	}
+
+	db = db.Save(result)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	recordChange(ctx, "webhook_subscriptions", model.Update, &before, result)
+	return result, db.RowsAffected, nil
+}
+
+// DeleteWebhookSubscriptions is a function to delete a single record from webhook_subscriptions table in the rocket_development database
+// error - ErrNotFound, db Find error
+// error - ErrDeleteFailed, db Delete failed error
+func DeleteWebhookSubscriptions(ctx context.Context, argID int64) (rowsAffected int64, err error) {
+
+	record := &model.WebhookSubscriptions{}
+	db := contextDB(ctx).First(record, argID)
+	if db.Error != nil {
+		return -1, ErrNotFound
+	}
+
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.Delete, record); err != nil {
+		return -1, err
+	}
+
+	db = db.Delete(record)
+	if err = db.Error; err != nil {
+		return -1, ErrDeleteFailed
+	}
+
+	recordChange(ctx, "webhook_subscriptions", model.Delete, record, nil)
+	return db.RowsAffected, nil
+}
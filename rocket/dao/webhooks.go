@@ -0,0 +1,190 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// WebhookPending delivery waiting for its first or next attempt
+	WebhookPending = "pending"
+
+	// WebhookDelivered delivery acknowledged with a 2xx response
+	WebhookDelivered = "delivered"
+
+	// WebhookFailed delivery given up after the last attempt or because its subscription is gone
+	WebhookFailed = "failed"
+)
+
+// WebhookDeliveryFilter restricts GetAllWebhookDeliveries results, empty fields match every delivery
+type WebhookDeliveryFilter struct {
+	SubscriptionID int64
+	Status         string
+	Table          string
+	RecordID       string
+}
+
+// GetActiveWebhookSubscriptions is a function to get the active webhook_subscriptions of table, including subscriptions to every table
+// error - ErrNotFound, db Find error
+func GetActiveWebhookSubscriptions(ctx context.Context, table string) (results []*model.WebhookSubscriptions, err error) {
+	if err = contextDB(ctx).Where("active = ? AND (table_name = ? OR table_name = '*')", true, table).Order("id").Find(&results).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// SaveWebhookSubscriptions is a function to write every column of a webhook_subscriptions record, unlike UpdateWebhookSubscriptions
+// false and empty values are stored so a subscription can be deactivated
+// error - ErrNotFound, db record for id not found
+// error - ErrUpdateFailed, db.Save call failed
+func SaveWebhookSubscriptions(ctx context.Context, record *model.WebhookSubscriptions) (result *model.WebhookSubscriptions, RowsAffected int64, err error) {
+	before := &model.WebhookSubscriptions{}
+	if err = contextDB(ctx).First(before, record.ID).Error; err != nil {
+		return nil, -1, ErrNotFound
+	}
+
+	if err = authorizeRecord(ctx, "webhook_subscriptions", model.Update, before); err != nil {
+		return nil, -1, err
+	}
+
+	record.CreatedAt = before.CreatedAt
+	db := contextDB(ctx).Save(record)
+	if err = db.Error; err != nil {
+		return nil, -1, ErrUpdateFailed
+	}
+
+	recordChange(ctx, "webhook_subscriptions", model.Update, before, record)
+	return record, db.RowsAffected, nil
+}
+
+// GetAllWebhookDeliveries is a function to get a slice of webhook_deliveries matching filter, newest first
+// params - page     - page requested (defaults to 0)
+// params - pagesize - number of records in a page  (defaults to 20)
+// error - ErrNotFound, db Find error
+func GetAllWebhookDeliveries(ctx context.Context, filter *WebhookDeliveryFilter, page, pagesize int64) (results []*model.WebhookDeliveries, totalRows int, err error) {
+
+	resultOrm := scopeQuery(ctx, "webhook_deliveries", model.RetrieveMany, contextDB(ctx).Model(&model.WebhookDeliveries{}))
+	if filter.SubscriptionID != 0 {
+		resultOrm = resultOrm.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+
+	if filter.Status != "" {
+		resultOrm = resultOrm.Where("status = ?", filter.Status)
+	}
+
+	if filter.Table != "" {
+		resultOrm = resultOrm.Where("table_name = ?", filter.Table)
+	}
+
+	if filter.RecordID != "" {
+		resultOrm = resultOrm.Where("record_id = ?", filter.RecordID)
+	}
+
+	resultOrm.Count(&totalRows)
+
+	if page > 0 {
+		offset := (page - 1) * pagesize
+		resultOrm = resultOrm.Offset(offset).Limit(pagesize)
+	} else {
+		resultOrm = resultOrm.Limit(pagesize)
+	}
+
+	if err = resultOrm.Order("id DESC").Find(&results).Error; err != nil {
+		err = ErrNotFound
+		return nil, -1, err
+	}
+
+	return results, totalRows, nil
+}
+
+// GetWebhookDeliveries is a function to get a single record from the webhook_deliveries table
+// error - ErrNotFound, db Find error
+func GetWebhookDeliveries(ctx context.Context, argID int64) (record *model.WebhookDeliveries, err error) {
+	record = &model.WebhookDeliveries{}
+	if err = contextDB(ctx).First(record, argID).Error; err != nil {
+		err = ErrNotFound
+		return record, err
+	}
+
+	if err = authorizeRecord(ctx, "webhook_deliveries", model.RetrieveOne, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// AddWebhookDeliveries is a function to queue deliveries, either all of them are stored or none
+// error - ErrInsertFailed, db create failed
+func AddWebhookDeliveries(ctx context.Context, records []*model.WebhookDeliveries) (err error) {
+	err = contextDB(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ErrInsertFailed
+	}
+
+	return nil
+}
+
+// GetDueWebhookDeliveries is a function to get up to limit pending deliveries whose next attempt is due at now, oldest first
+// error - ErrNotFound, db Find error
+func GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) (results []*model.WebhookDeliveries, err error) {
+	db := contextDB(ctx).Where("status = ? AND next_attempt_at <= ?", WebhookPending, now).Order("next_attempt_at, id").Limit(limit)
+	if err = db.Find(&results).Error; err != nil {
+		return nil, ErrNotFound
+	}
+
+	return results, nil
+}
+
+// SaveWebhookDeliveryAttempt is a function to store the outcome of a delivery attempt
+// error - ErrUpdateFailed, db.Save call failed
+func SaveWebhookDeliveryAttempt(ctx context.Context, record *model.WebhookDeliveries) (err error) {
+	if err = contextDB(ctx).Save(record).Error; err != nil {
+		return ErrUpdateFailed
+	}
+
+	return nil
+}
+
+// ReplayWebhookDelivery is a function to queue a new delivery of the payload of an earlier delivery
+// error - ErrNotFound, db record for id not found
+// error - ErrInsertFailed, db create failed
+func ReplayWebhookDelivery(ctx context.Context, argID int64, now time.Time) (result *model.WebhookDeliveries, err error) {
+	original, err := GetWebhookDeliveries(ctx, argID)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &model.WebhookDeliveries{
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		TableName_:     original.TableName_,
+		RecordID:       original.RecordID,
+		Payload:        original.Payload,
+		Status:         WebhookPending,
+		NextAttemptAt:  null.TimeFrom(now),
+		ReplayOf:       null.IntFrom(original.ID),
+	}
+
+	if err = authorizeRecord(ctx, "webhook_deliveries", model.Create, result); err != nil {
+		return nil, err
+	}
+
+	if err = contextDB(ctx).Create(result).Error; err != nil {
+		return nil, ErrInsertFailed
+	}
+
+	return result, nil
+}
@@ -0,0 +1,245 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Location line and column of a token in a document, both start at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error a syntax, validation or execution error as reported in the errors of a response
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) > 0 {
+		return fmt.Sprintf("%d:%d: %s", e.Locations[0].Line, e.Locations[0].Column, e.Message)
+	}
+	return e.Message
+}
+
+func newError(loc Location, format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
+
+// Document a parsed request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation a query, mutation or subscription of a document, Name is empty for anonymous operations
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []*Selection
+	Loc        Location
+}
+
+// VariableDefinition a variable declared by an operation, Default is nil when there is no default value
+type VariableDefinition struct {
+	Name    string
+	Type    *Type
+	Default *Value
+	Loc     Location
+}
+
+// Fragment a named fragment of a document
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []*Selection
+	Loc           Location
+}
+
+// SelectionKind kind of a selection
+type SelectionKind int
+
+const (
+	// FieldSelection a field, ie name(arg: 1) { ... }
+	FieldSelection SelectionKind = iota
+
+	// FragmentSpread a reference to a named fragment, ie ...fields
+	FragmentSpread
+
+	// InlineFragment an anonymous fragment, ie ... on Building { ... }
+	InlineFragment
+)
+
+// Selection a field, fragment spread or inline fragment of a selection set
+type Selection struct {
+	Kind SelectionKind
+
+	// Alias response key of a field, empty when the field is not aliased
+	Alias string
+
+	// Name field name, or fragment name of a spread
+	Name string
+
+	// TypeCondition type an inline fragment applies to, empty when it applies to any type
+	TypeCondition string
+
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []*Selection
+	Loc        Location
+}
+
+// ResponseKey the key of a field in the response, its alias or its name
+func (s *Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// Argument an argument of a field or directive
+type Argument struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+// Directive a directive applied to a selection or definition, ie @include(if: $all)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+// ValueKind kind of a value literal
+type ValueKind int
+
+const (
+	// VariableValue a reference to a variable, Raw is the variable name
+	VariableValue ValueKind = iota
+
+	// IntValue an integer, ie 12
+	IntValue
+
+	// FloatValue a number with a fraction or exponent, ie 1.5e3
+	FloatValue
+
+	// StringValue a string or block string
+	StringValue
+
+	// BooleanValue true or false
+	BooleanValue
+
+	// NullValue null
+	NullValue
+
+	// EnumValue any other name, ie ASC
+	EnumValue
+
+	// ListValue a list, ie [1, 2]
+	ListValue
+
+	// ObjectValue an input object, ie {status: "Active"}
+	ObjectValue
+)
+
+// Value a value literal, Raw holds the text of scalars, List the items of lists and Fields the fields of objects
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*Argument
+	Loc    Location
+}
+
+// Type a type reference of a variable definition, ie [ID!]!
+type Type struct {
+	Name    string
+	Elem    *Type
+	NonNull bool
+}
+
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Resolve the go value of v, variables are replaced by their value in vars. Ints are returned as int64, floats as float64,
+// enums as their name, lists as []interface{} and objects as map[string]interface{}.
+func (v *Value) Resolve(vars map[string]interface{}) (interface{}, error) {
+	switch v.Kind {
+	case VariableValue:
+		return vars[v.Raw], nil
+	case IntValue:
+		n, err := strconv.ParseInt(v.Raw, 10, 64)
+		if err != nil {
+			return nil, newError(v.Loc, "invalid int %s", v.Raw)
+		}
+		return n, nil
+	case FloatValue:
+		f, err := strconv.ParseFloat(v.Raw, 64)
+		if err != nil {
+			return nil, newError(v.Loc, "invalid float %s", v.Raw)
+		}
+		return f, nil
+	case StringValue, EnumValue:
+		return v.Raw, nil
+	case BooleanValue:
+		return v.Raw == "true", nil
+	case NullValue:
+		return nil, nil
+	case ListValue:
+		list := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			value, err := item.Resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	case ObjectValue:
+		object := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			value, err := field.Value.Resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = value
+		}
+		return object, nil
+	default:
+		return nil, newError(v.Loc, "unknown value")
+	}
+}
+
+// ArgumentValues the resolved values of args, arguments given a variable that is not set are left out
+func ArgumentValues(args []*Argument, vars map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		if arg.Value.Kind == VariableValue {
+			if _, ok := vars[arg.Value.Raw]; !ok {
+				continue
+			}
+		}
+
+		value, err := arg.Value.Resolve(vars)
+		if err != nil {
+			return nil, err
+		}
+		values[arg.Name] = value
+	}
+	return values, nil
+}
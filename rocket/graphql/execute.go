@@ -0,0 +1,270 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Request a graphql request as posted to the endpoint
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response the result of a request, Data is nil when the request could not be executed
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Object a response object, fields are encoded in the order of the selection set
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject an empty response object
+func NewObject() *Object {
+	return &Object{values: make(map[string]interface{})}
+}
+
+// Set the value of a field, a field set twice keeps its first position
+func (o *Object) Set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Get the value of a field
+func (o *Object) Get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// MarshalJSON encode the fields in order
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Operation the operation of the document to execute, name may be empty when the document holds a single operation
+// error - *Error, no operation matches name
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, &Error{Message: "operationName is required when the document holds several operations"}
+		}
+		return d.Operations[0], nil
+	}
+
+	for _, operation := range d.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, &Error{Message: "unknown operation " + name}
+}
+
+// CoerceVariables the variables of operation, default values are applied and required variables checked.
+// Values are not checked against their type, arguments are checked when the field using them is executed.
+// error - *Error, a required variable is missing or null
+func CoerceVariables(operation *Operation, provided map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(operation.Variables))
+	for _, definition := range operation.Variables {
+		value, ok := provided[definition.Name]
+		if !ok && definition.Default != nil {
+			defaultValue, err := definition.Default.Resolve(nil)
+			if err != nil {
+				return nil, err
+			}
+			value, ok = defaultValue, true
+		}
+
+		if definition.Type.NonNull && value == nil {
+			return nil, newError(definition.Loc, "variable $%s of type %s is required", definition.Name, definition.Type)
+		}
+
+		if ok {
+			vars[definition.Name] = value
+		}
+	}
+	return vars, nil
+}
+
+// CollectFields the fields of a selection set applying to typeName, fragments are expanded, @skip and @include are applied
+// and fields with the same response key are merged
+// error - *Error, unknown or recursive fragment, invalid directive argument
+func CollectFields(doc *Document, selections []*Selection, vars map[string]interface{}, typeName string) ([]*Selection, error) {
+	c := &collector{doc: doc, vars: vars, typeName: typeName, byKey: make(map[string]*Selection), visited: make(map[string]bool)}
+	if err := c.collect(selections); err != nil {
+		return nil, err
+	}
+	return c.fields, nil
+}
+
+type collector struct {
+	doc      *Document
+	vars     map[string]interface{}
+	typeName string
+	fields   []*Selection
+	byKey    map[string]*Selection
+	visited  map[string]bool
+}
+
+func (c *collector) collect(selections []*Selection) error {
+	for _, selection := range selections {
+		include, err := c.included(selection.Directives)
+		if err != nil {
+			return err
+		}
+
+		if !include {
+			continue
+		}
+
+		switch selection.Kind {
+		case FieldSelection:
+			key := selection.ResponseKey()
+			if previous, ok := c.byKey[key]; ok {
+				if previous.Name != selection.Name {
+					return newError(selection.Loc, "fields %s and %s can not both use the response key %s", previous.Name, selection.Name, key)
+				}
+
+				merged := *previous
+				merged.Selections = append(append([]*Selection{}, previous.Selections...), selection.Selections...)
+				c.byKey[key] = &merged
+				for i, field := range c.fields {
+					if field == previous {
+						c.fields[i] = &merged
+					}
+				}
+				continue
+			}
+			c.byKey[key] = selection
+			c.fields = append(c.fields, selection)
+		case FragmentSpread:
+			fragment, ok := c.doc.Fragments[selection.Name]
+			if !ok {
+				return newError(selection.Loc, "unknown fragment %s", selection.Name)
+			}
+
+			if c.visited[fragment.Name] {
+				return newError(selection.Loc, "fragment %s spreads itself", fragment.Name)
+			}
+
+			if fragment.TypeCondition != c.typeName {
+				continue
+			}
+
+			c.visited[fragment.Name] = true
+			err := c.collect(fragment.Selections)
+			delete(c.visited, fragment.Name)
+			if err != nil {
+				return err
+			}
+		case InlineFragment:
+			if selection.TypeCondition != "" && selection.TypeCondition != c.typeName {
+				continue
+			}
+
+			if err := c.collect(selection.Selections); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// included evaluate the @skip and @include directives of a selection
+func (c *collector) included(directives []*Directive) (bool, error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+
+		args, err := ArgumentValues(directive.Arguments, c.vars)
+		if err != nil {
+			return false, err
+		}
+
+		condition, ok := args["if"].(bool)
+		if !ok {
+			return false, newError(directive.Loc, "@%s requires a boolean if argument", directive.Name)
+		}
+
+		if (directive.Name == "skip") == condition {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CountFields the number of fields selected by selections and their subselections, fragments are expanded where they are
+// spread and aliases of a field are counted apart. Fields are counted whatever their directives.
+// error - *Error, more than max fields are selected or a fragment spreads itself
+func CountFields(doc *Document, selections []*Selection, max int) (int, error) {
+	c := &counter{doc: doc, max: max, visited: make(map[string]bool)}
+	if err := c.count(selections); err != nil {
+		return 0, err
+	}
+	return c.fields, nil
+}
+
+type counter struct {
+	doc     *Document
+	max     int
+	fields  int
+	visited map[string]bool
+}
+
+func (c *counter) count(selections []*Selection) error {
+	for _, selection := range selections {
+		switch selection.Kind {
+		case FieldSelection:
+			if c.fields++; c.fields > c.max {
+				return newError(selection.Loc, "operation selects more than %d fields", c.max)
+			}
+		case FragmentSpread:
+			fragment, ok := c.doc.Fragments[selection.Name]
+			if !ok {
+				// reported by CollectFields
+				continue
+			}
+
+			if c.visited[fragment.Name] {
+				return newError(selection.Loc, "fragment %s spreads itself", fragment.Name)
+			}
+
+			c.visited[fragment.Name] = true
+			err := c.count(fragment.Selections)
+			delete(c.visited, fragment.Name)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.count(selection.Selections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
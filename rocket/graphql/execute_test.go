@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mustParse the document of source, the test fails on a syntax error
+func mustParse(t *testing.T, source string) *Document {
+	t.Helper()
+	doc, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse(%q) = %v", source, err)
+	}
+	return doc
+}
+
+// keys the response keys of fields
+func keys(fields []*Selection) string {
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.ResponseKey())
+	}
+	return strings.Join(names, " ")
+}
+
+func TestOperation(t *testing.T) {
+	doc := mustParse(t, `query a { x } mutation b { y }`)
+
+	if operation, err := doc.Operation("b"); err != nil || operation.Type != "mutation" {
+		t.Errorf("Operation(b) = %v, %v, want the mutation", operation, err)
+	}
+	if _, err := doc.Operation(""); err == nil || !strings.Contains(err.Error(), "operationName is required") {
+		t.Errorf("Operation() of 2 operations = %v, want operationName to be required", err)
+	}
+	if _, err := doc.Operation("c"); err == nil || err.Error() != "unknown operation c" {
+		t.Errorf("Operation(c) = %v, want an unknown operation", err)
+	}
+}
+
+func TestCoerceVariables(t *testing.T) {
+	doc := mustParse(t, `query q($id: ID!, $size: Int = 20, $order: String, $status: String = "Active") { x }`)
+	operation := doc.Operations[0]
+
+	vars, err := CoerceVariables(operation, map[string]interface{}{"id": "4", "status": nil, "extra": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vars) != 3 || vars["id"] != "4" || vars["size"] != int64(20) || vars["status"] != nil {
+		t.Errorf("CoerceVariables = %v, want id 4, the default size and a null status", vars)
+	}
+	if _, ok := vars["order"]; ok {
+		t.Errorf("CoerceVariables set the order variable that was not provided")
+	}
+
+	for _, provided := range []map[string]interface{}{nil, {"id": nil}} {
+		if _, err := CoerceVariables(operation, provided); err == nil || !strings.Contains(err.Error(), "variable $id of type ID! is required") {
+			t.Errorf("CoerceVariables(%v) = %v, want $id to be required", provided, err)
+		}
+	}
+}
+
+func TestCollectFields(t *testing.T) {
+	doc := mustParse(t, `
+query q($all: Boolean!) {
+  id
+  name @skip(if: $all)
+  status @include(if: $all)
+  ...building
+  ...battery
+  ... on Building { address }
+  ... { floors: number_of_floors }
+  customer { id }
+  customer { name }
+}
+
+fragment building on Building { id type }
+fragment battery on Battery { certificate }
+`)
+	operation := doc.Operations[0]
+
+	fields, err := CollectFields(doc, operation.Selections, map[string]interface{}{"all": true}, "Building")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(fields), "id status type address floors customer"; got != want {
+		t.Errorf("CollectFields = %s, want %s", got, want)
+	}
+	if customer := fields[len(fields)-1]; keys(customer.Selections) != "id name" {
+		t.Errorf("customer selections = %s, want the merged id name", keys(customer.Selections))
+	}
+
+	fields, err = CollectFields(doc, operation.Selections, map[string]interface{}{"all": false}, "Battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(fields), "id name certificate floors customer"; got != want {
+		t.Errorf("CollectFields = %s, want %s", got, want)
+	}
+}
+
+func TestCollectFieldsErrors(t *testing.T) {
+	tests := []struct {
+		source, want string
+	}{
+		{`{ a: id a: name }`, "fields id and name can not both use the response key a"},
+		{`{ ...missing }`, "unknown fragment missing"},
+		{`{ ...f } fragment f on Query { ...g } fragment g on Query { ...f }`, "fragment f spreads itself"},
+		{`{ id @skip(if: "yes") }`, "@skip requires a boolean if argument"},
+	}
+
+	for _, tt := range tests {
+		doc := mustParse(t, tt.source)
+		_, err := CollectFields(doc, doc.Operations[0].Selections, nil, "Query")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("CollectFields(%q) = %v, want an error containing %q", tt.source, err, tt.want)
+		}
+	}
+}
+
+func TestCountFields(t *testing.T) {
+	doc := mustParse(t, `
+{
+  a: buildings { data { id ...f } }
+  b: buildings { data { id @skip(if: true) ...f } }
+}
+
+fragment f on Building { address batteries { id } }
+`)
+
+	if n, err := CountFields(doc, doc.Operations[0].Selections, 100); err != nil || n != 12 {
+		t.Errorf("CountFields = %d, %v, want 12", n, err)
+	}
+	if _, err := CountFields(doc, doc.Operations[0].Selections, 11); err == nil || !strings.Contains(err.Error(), "more than 11 fields") {
+		t.Errorf("CountFields over the limit = %v, want an error", err)
+	}
+
+	// each fragment doubles the fields, the count stops at the limit instead of expanding them all
+	source := "{ ...f0 } fragment f40 on Query { id }"
+	for i := 0; i < 40; i++ {
+		source += fmt.Sprintf(" fragment f%d on Query { ...f%d ...f%d }", i, i+1, i+1)
+	}
+	bomb := mustParse(t, source)
+	if _, err := CountFields(bomb, bomb.Operations[0].Selections, 500); err == nil || !strings.Contains(err.Error(), "more than 500 fields") {
+		t.Errorf("CountFields of nested fragments = %v, want the limit to be reached", err)
+	}
+
+	recursive := mustParse(t, `{ ...f } fragment f on Query { a { ...f } }`)
+	if _, err := CountFields(recursive, recursive.Operations[0].Selections, 500); err == nil || !strings.Contains(err.Error(), "fragment f spreads itself") {
+		t.Errorf("CountFields of a recursive fragment = %v, want an error", err)
+	}
+}
+
+func TestObjectMarshalJSON(t *testing.T) {
+	object := NewObject()
+	object.Set("z", 1)
+	object.Set("a", []interface{}{"x", nil})
+	object.Set("z", 2)
+
+	nested := NewObject()
+	nested.Set("id", "7")
+	object.Set("m", nested)
+
+	data, err := json.Marshal(&Response{Data: object})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"data":{"z":2,"a":["x",null],"m":{"id":"7"}}}`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+
+	if value, ok := object.Get("z"); !ok || value != 2 {
+		t.Errorf("Get(z) = %v, %v, want 2", value, ok)
+	}
+}
@@ -0,0 +1,289 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token a lexical token of a document, Value is the unescaped content of strings
+type token struct {
+	Kind  tokenKind
+	Value string
+	Loc   Location
+}
+
+// lexer splits a document in tokens, whitespace, commas and comments are skipped
+type lexer struct {
+	source    string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func newLexer(source string) *lexer {
+	return &lexer{source: source, line: 1}
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+// next read the token following the current position
+func (l *lexer) next() (*token, error) {
+	l.skipIgnored()
+
+	loc := l.location()
+	if l.pos >= len(l.source) {
+		return &token{Kind: tokenEOF, Loc: loc}, nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case c == '.':
+		if strings.HasPrefix(l.source[l.pos:], "...") {
+			l.pos += 3
+			return &token{Kind: tokenPunctuator, Value: "...", Loc: loc}, nil
+		}
+		return nil, newError(loc, "unexpected character %q", c)
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return &token{Kind: tokenPunctuator, Value: string(c), Loc: loc}, nil
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.pos++
+		}
+		return &token{Kind: tokenName, Value: l.source[start:l.pos], Loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(loc)
+	case c == '"':
+		if strings.HasPrefix(l.source[l.pos:], `"""`) {
+			return l.readBlockString(loc)
+		}
+		return l.readString(loc)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+		return nil, newError(loc, "unexpected character %q", r)
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.source) && l.source[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		case '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			// byte order mark
+			if strings.HasPrefix(l.source[l.pos:], "\ufeff") {
+				l.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) readNumber(loc Location) (*token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+
+	digits := l.pos
+	if !l.readDigits() || (l.source[digits] == '0' && l.pos-digits > 1) {
+		// the integer part has no leading zero
+		return nil, newError(loc, "invalid number %q", l.source[start:l.pos])
+	}
+
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.readDigits() {
+			return nil, newError(loc, "invalid number %q", l.source[start:l.pos])
+		}
+	}
+
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.readDigits() {
+			return nil, newError(loc, "invalid number %q", l.source[start:l.pos])
+		}
+	}
+
+	if l.pos < len(l.source) && (l.source[l.pos] == '_' || l.source[l.pos] == '.' || isLetter(l.source[l.pos])) {
+		return nil, newError(loc, "invalid number %q", l.source[start:l.pos+1])
+	}
+	return &token{Kind: kind, Value: l.source[start:l.pos], Loc: loc}, nil
+}
+
+func (l *lexer) readDigits() bool {
+	start := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+func (l *lexer) readString(loc Location) (*token, error) {
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return &token{Kind: tokenString, Value: b.String(), Loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return nil, newError(loc, "unterminated string")
+		case c == '\\':
+			if l.pos+1 >= len(l.source) {
+				return nil, newError(loc, "unterminated string")
+			}
+
+			escape := l.source[l.pos+1]
+			l.pos += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.source) {
+					return nil, newError(loc, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return nil, newError(loc, "invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return nil, newError(loc, "invalid escape \\%c", escape)
+			}
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return nil, newError(loc, "unterminated string")
+}
+
+func (l *lexer) readBlockString(loc Location) (*token, error) {
+	l.pos += 3
+	var b strings.Builder
+	for l.pos < len(l.source) {
+		switch {
+		case strings.HasPrefix(l.source[l.pos:], `"""`):
+			l.pos += 3
+			return &token{Kind: tokenString, Value: blockStringValue(b.String()), Loc: loc}, nil
+		case strings.HasPrefix(l.source[l.pos:], `\"""`):
+			b.WriteString(`"""`)
+			l.pos += 4
+		default:
+			c := l.source[l.pos]
+			b.WriteByte(c)
+			l.pos++
+			if c == '\n' {
+				l.newline()
+			}
+		}
+	}
+	return nil, newError(loc, "unterminated block string")
+}
+
+// blockStringValue remove the common indentation and the blank first and last lines of a block string
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (t *token) String() string {
+	switch t.Kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.Value)
+	default:
+		return fmt.Sprintf("%q", t.Value)
+	}
+}
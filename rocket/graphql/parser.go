@@ -0,0 +1,489 @@
+package graphql
+
+// parser a recursive descent parser of executable documents, type system definitions are not supported
+type parser struct {
+	lexer *lexer
+	tok   *token
+}
+
+// Parse the query document source
+// error - *Error, syntax error or duplicate operation or fragment name
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: newLexer(source)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+	if p.tok.Kind == tokenEOF {
+		return nil, newError(p.tok.Loc, "document does not contain any operation")
+	}
+
+	names := make(map[string]bool)
+	for p.tok.Kind != tokenEOF {
+		if p.peekName("fragment") {
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, newError(fragment.Loc, "fragment %s is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+			continue
+		}
+
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Name != "" && names[operation.Name] {
+			return nil, newError(operation.Loc, "operation %s is defined more than once", operation.Name)
+		}
+		names[operation.Name] = true
+		doc.Operations = append(doc.Operations, operation)
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, newError(Location{Line: 1, Column: 1}, "document does not contain any operation")
+	}
+
+	if len(doc.Operations) > 1 {
+		for _, operation := range doc.Operations {
+			if operation.Name == "" {
+				return nil, newError(operation.Loc, "anonymous operation must be the only operation of the document")
+			}
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(punctuator string) bool {
+	return p.tok.Kind == tokenPunctuator && p.tok.Value == punctuator
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.Kind == tokenName && p.tok.Value == name
+}
+
+// expect consume the punctuator or fail
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return newError(p.tok.Loc, "expected %q, found %s", punctuator, p.tok)
+	}
+	return p.advance()
+}
+
+// skip consume the punctuator when it is the current token
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(punctuator) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) parseName() (string, error) {
+	if p.tok.Kind != tokenName {
+		return "", newError(p.tok.Loc, "expected name, found %s", p.tok)
+	}
+
+	name := p.tok.Value
+	return name, p.advance()
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: "query", Loc: p.tok.Loc}
+	if p.peek("{") {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		operation.Selections = selections
+		return operation, nil
+	}
+
+	if p.tok.Kind != tokenName || (p.tok.Value != "query" && p.tok.Value != "mutation" && p.tok.Value != "subscription") {
+		return nil, newError(p.tok.Loc, "expected query, mutation, subscription or fragment, found %s", p.tok)
+	}
+	operation.Type = p.tok.Value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Kind == tokenName {
+		operation.Name = p.tok.Value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if operation.Variables, err = p.parseVariableDefinitions(); err != nil {
+		return nil, err
+	}
+
+	if operation.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	if operation.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	if ok, err := p.skip("("); !ok || err != nil {
+		return nil, err
+	}
+
+	var definitions []*VariableDefinition
+	for !p.peek(")") {
+		definition := &VariableDefinition{Loc: p.tok.Loc}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if definition.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if definition.Type, err = p.parseType(); err != nil {
+			return nil, err
+		}
+
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if definition.Default, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if len(definitions) == 0 {
+		return nil, newError(p.tok.Loc, "expected variable definition, found %s", p.tok)
+	}
+	return definitions, p.advance()
+}
+
+func (p *parser) parseType() (*Type, error) {
+	var t *Type
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &Type{Elem: elem}
+	} else {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		t = &Type{Name: name}
+	}
+
+	ok, err := p.skip("!")
+	t.NonNull = ok
+	return t, err
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	fragment := &Fragment{Loc: p.tok.Loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if fragment.Name, err = p.parseName(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Name == "on" {
+		return nil, newError(fragment.Loc, "fragment can not be named on")
+	}
+
+	if !p.peekName("on") {
+		return nil, newError(p.tok.Loc, "expected \"on\", found %s", p.tok)
+	}
+
+	if err = p.advance(); err != nil {
+		return nil, err
+	}
+
+	if fragment.TypeCondition, err = p.parseName(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*Selection
+	for !p.peek("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+
+	if len(selections) == 0 {
+		return nil, newError(p.tok.Loc, "expected selection, found %s", p.tok)
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (*Selection, error) {
+	selection := &Selection{Loc: p.tok.Loc}
+	var err error
+
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.parseFragmentSelection(selection)
+	}
+
+	if selection.Name, err = p.parseName(); err != nil {
+		return nil, err
+	}
+
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		selection.Alias = selection.Name
+		if selection.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if selection.Arguments, err = p.parseArguments(false); err != nil {
+		return nil, err
+	}
+
+	if selection.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	if p.peek("{") {
+		if selection.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return selection, nil
+}
+
+// parseFragmentSelection parse the spread or inline fragment following "..."
+func (p *parser) parseFragmentSelection(selection *Selection) (*Selection, error) {
+	var err error
+	if p.tok.Kind == tokenName && p.tok.Value != "on" {
+		selection.Kind = FragmentSpread
+		if selection.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+
+		selection.Directives, err = p.parseDirectives()
+		return selection, err
+	}
+
+	selection.Kind = InlineFragment
+	if p.peekName("on") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+
+		if selection.TypeCondition, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if selection.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	selection.Selections, err = p.parseSelectionSet()
+	return selection, err
+}
+
+func (p *parser) parseArguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); !ok || err != nil {
+		return nil, err
+	}
+
+	var args []*Argument
+	for !p.peek(")") {
+		arg := &Argument{Loc: p.tok.Loc}
+		var err error
+		if arg.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if arg.Value, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+
+		for _, other := range args {
+			if other.Name == arg.Name {
+				return nil, newError(arg.Loc, "argument %s is given more than once", arg.Name)
+			}
+		}
+		args = append(args, arg)
+	}
+
+	if len(args) == 0 {
+		return nil, newError(p.tok.Loc, "expected argument, found %s", p.tok)
+	}
+	return args, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		directive := &Directive{Loc: p.tok.Loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if directive.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+
+		if directive.Arguments, err = p.parseArguments(false); err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseValue parse a value literal, variables are rejected in constant values such as defaults
+func (p *parser) parseValue(constant bool) (*Value, error) {
+	value := &Value{Loc: p.tok.Loc, Raw: p.tok.Value}
+	switch p.tok.Kind {
+	case tokenInt:
+		value.Kind = IntValue
+	case tokenFloat:
+		value.Kind = FloatValue
+	case tokenString:
+		value.Kind = StringValue
+	case tokenName:
+		switch p.tok.Value {
+		case "true", "false":
+			value.Kind = BooleanValue
+		case "null":
+			value.Kind = NullValue
+		default:
+			value.Kind = EnumValue
+		}
+	case tokenPunctuator:
+		switch p.tok.Value {
+		case "$":
+			if constant {
+				return nil, newError(p.tok.Loc, "variables are not allowed in constant values")
+			}
+
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			value.Kind, value.Raw = VariableValue, name
+			return value, nil
+		case "[":
+			return p.parseList(value, constant)
+		case "{":
+			return p.parseObject(value, constant)
+		}
+		return nil, newError(p.tok.Loc, "expected value, found %s", p.tok)
+	default:
+		return nil, newError(p.tok.Loc, "expected value, found %s", p.tok)
+	}
+	return value, p.advance()
+}
+
+func (p *parser) parseList(value *Value, constant bool) (*Value, error) {
+	value.Kind, value.Raw = ListValue, ""
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.peek("]") {
+		item, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		value.List = append(value.List, item)
+	}
+	return value, p.advance()
+}
+
+func (p *parser) parseObject(value *Value, constant bool) (*Value, error) {
+	value.Kind, value.Raw = ObjectValue, ""
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.peek("}") {
+		field := &Argument{Loc: p.tok.Loc}
+		var err error
+		if field.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if field.Value, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+		value.Fields = append(value.Fields, field)
+	}
+	return value, p.advance()
+}
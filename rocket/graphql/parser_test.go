@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+# buildings of a customer
+query Buildings($customer: ID!, $size: Int = 20) @cached {
+  list: buildings(page_size: $size, filter: {customer_id: $customer, status: "Active"}, order: "id desc") {
+    data {
+      id
+      ...address
+      batteries(limit: 2) @include(if: true) { ... on Battery { status } }
+    }
+  }
+}
+
+fragment address on Building {
+  address_of_building
+  notes: full_name_of_the_building_administrator
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation, err := doc.Operation("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if operation.Type != "query" || operation.Name != "Buildings" || len(operation.Directives) != 1 || operation.Directives[0].Name != "cached" {
+		t.Errorf("operation = %s %s %v, want query Buildings @cached", operation.Type, operation.Name, operation.Directives)
+	}
+
+	if len(operation.Variables) != 2 {
+		t.Fatalf("variables = %d, want 2", len(operation.Variables))
+	}
+	if v := operation.Variables[0]; v.Name != "customer" || v.Type.String() != "ID!" || v.Default != nil {
+		t.Errorf("variable 0 = $%s: %s = %v, want $customer: ID!", v.Name, v.Type, v.Default)
+	}
+	if v := operation.Variables[1]; v.Name != "size" || v.Type.String() != "Int" || v.Default == nil || v.Default.Raw != "20" {
+		t.Errorf("variable 1 = $%s: %s = %v, want $size: Int = 20", v.Name, v.Type, v.Default)
+	}
+
+	list := operation.Selections[0]
+	if list.Alias != "list" || list.Name != "buildings" || list.ResponseKey() != "list" || list.Loc != (Location{Line: 4, Column: 3}) {
+		t.Errorf("field = %s: %s at %v, want list: buildings at 4:3", list.Alias, list.Name, list.Loc)
+	}
+
+	args, err := ArgumentValues(list.Arguments, map[string]interface{}{"size": int64(5), "customer": "7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"page_size": int64(5),
+		"filter":    map[string]interface{}{"customer_id": "7", "status": "Active"},
+		"order":     "id desc",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("arguments = %v, want %v", args, want)
+	}
+
+	data := list.Selections[0].Selections
+	if len(data) != 3 || data[1].Kind != FragmentSpread || data[1].Name != "address" {
+		t.Fatalf("data selections = %v, want id, ...address and batteries", data)
+	}
+	if batteries := data[2]; len(batteries.Directives) != 1 || batteries.Selections[0].Kind != InlineFragment || batteries.Selections[0].TypeCondition != "Battery" {
+		t.Errorf("batteries = %v %v, want an @include directive and an inline fragment on Battery", batteries.Directives, batteries.Selections)
+	}
+
+	fragment, ok := doc.Fragments["address"]
+	if !ok || fragment.TypeCondition != "Building" || len(fragment.Selections) != 2 || fragment.Selections[1].Alias != "notes" {
+		t.Errorf("fragment address = %v, want 2 fields on Building", fragment)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		value string
+		want  interface{}
+	}{
+		{`12`, int64(12)},
+		{`-3`, int64(-3)},
+		{`0`, int64(0)},
+		{`1.5e3`, 1500.0},
+		{`"a\"bé\n"`, "a\"bé\n"},
+		{"\"\"\"\n    first\n      second\n  \"\"\"", "first\n  second"},
+		{`true`, true},
+		{`null`, nil},
+		{`DESC`, "DESC"},
+		{`[1, "two", [3]]`, []interface{}{int64(1), "two", []interface{}{int64(3)}}},
+		{`{a: 1, b: {c: $v}}`, map[string]interface{}{"a": int64(1), "b": map[string]interface{}{"c": "var"}}},
+	}
+
+	for _, tt := range tests {
+		doc, err := Parse("{ f(v: " + tt.value + ") }")
+		if err != nil {
+			t.Errorf("Parse(%s) = %v", tt.value, err)
+			continue
+		}
+
+		got, err := doc.Operations[0].Selections[0].Arguments[0].Value.Resolve(map[string]interface{}{"v": "var"})
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("value %s = %#v, %v, want %#v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source, want string
+	}{
+		{``, "1:1: document does not contain any operation"},
+		{`fragment f on Building { id }`, "document does not contain any operation"},
+		{`{ id `, `expected name, found end of document`},
+		{`{ id(a: 1, a: 2) }`, "argument a is given more than once"},
+		{`{ f(a: "open) }`, "unterminated string"},
+		{`{ f(a: 01) }`, "invalid number"},
+		{`{ f(a: 1.) }`, "invalid number"},
+		{`{ f(a: "\q") }`, `invalid escape \q`},
+		{`{ a } { b }`, "anonymous operation must be the only operation of the document"},
+		{`query q { a } query q { b }`, "operation q is defined more than once"},
+		{`{ a } fragment f on A { a } fragment f on A { b }`, "fragment f is defined more than once"},
+		{`fragment on on A { a } { a }`, "fragment can not be named on"},
+		{`query q($v: Int = $w) { a }`, "variables are not allowed in constant values"},
+		{`schema { query: Query }`, "expected query, mutation, subscription or fragment"},
+		{"{ a }\n  %", "2:3: unexpected character '%'"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", tt.source, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package logging writes log lines as json objects, lines logged with the context of a request carry its request id
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Level severity of a log line
+type Level string
+
+const (
+	// LevelDebug detailed information, ie every sql statement
+	LevelDebug Level = "debug"
+
+	// LevelInfo normal operation, ie a served request
+	LevelInfo Level = "info"
+
+	// LevelWarn something unexpected that did not fail, ie a slow query
+	LevelWarn Level = "warn"
+
+	// LevelError a failure
+	LevelError Level = "error"
+)
+
+// Fields the fields of a log line besides time, level, msg and request_id
+type Fields map[string]interface{}
+
+// Logger writes one json object per line to its output
+type Logger struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Default the logger of the package level functions, it writes to stdout
+var Default = New(os.Stdout)
+
+// New a logger writing to out
+func New(out io.Writer) *Logger {
+	return &Logger{out: out}
+}
+
+// Log write a line with time, level, msg, the request id of ctx when it has one and fields sorted by name
+func (l *Logger) Log(ctx context.Context, level Level, msg string, fields Fields) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeField(&buf, "time", time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeField(&buf, "level", level)
+	buf.WriteByte(',')
+	writeField(&buf, "msg", msg)
+	if id := RequestID(ctx); id != "" {
+		buf.WriteByte(',')
+		writeField(&buf, "request_id", id)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteByte(',')
+		writeField(&buf, name, fields[name])
+	}
+	buf.WriteString("}\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(buf.Bytes())
+}
+
+func writeField(buf *bytes.Buffer, name string, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	key, _ := json.Marshal(name)
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+	}
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(data)
+}
+
+// Writer an io.Writer logging each write as the msg of a line with level, to route the standard log package through l
+func (l *Logger) Writer(level Level) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		l.Log(context.Background(), level, string(bytes.TrimRight(p, "\n")), nil)
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// Debug log a debug line with Default
+func Debug(ctx context.Context, msg string, fields Fields) {
+	Default.Log(ctx, LevelDebug, msg, fields)
+}
+
+// Info log an info line with Default
+func Info(ctx context.Context, msg string, fields Fields) {
+	Default.Log(ctx, LevelInfo, msg, fields)
+}
+
+// Warn log a warn line with Default
+func Warn(ctx context.Context, msg string, fields Fields) {
+	Default.Log(ctx, LevelWarn, msg, fields)
+}
+
+// Error log an error line with Default
+func Error(ctx context.Context, msg string, fields Fields) {
+	Default.Log(ctx, LevelError, msg, fields)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID a context carrying the request id id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID the request id of ctx, empty when it has none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID a random request id of 32 hex digits
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID reports if a client supplied request id may be logged, 1 to 128 letters, digits, dashes, underscores, dots
+// or colons
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
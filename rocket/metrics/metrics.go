@@ -0,0 +1,321 @@
+// Package metrics keeps counters, gauges and histograms and serves them in the prometheus text exposition format. Metrics are
+// registered with Default when they are created.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets upper bounds in seconds of the buckets of latency histograms, from 1ms to 10s
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector a metric family written to the exposition
+type Collector interface {
+	// Name the metric name, unique in a registry
+	Name() string
+
+	// Write write the HELP and TYPE lines and the samples of the family
+	Write(w io.Writer)
+}
+
+// Registry the collectors served by a metrics endpoint
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// Default the registry metrics are added to when they are created
+var Default = NewRegistry()
+
+// NewRegistry an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Register add c to the registry, it panics when a collector with the same name is registered
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.collectors[c.Name()]; ok {
+		panic("metrics: duplicate metric " + c.Name())
+	}
+	r.collectors[c.Name()] = c
+}
+
+// WriteTo write every family sorted by name
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := make([]Collector, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	buf := bufio.NewWriter(w)
+	cw := &countingWriter{w: buf}
+	for _, c := range collectors {
+		c.Write(cw)
+	}
+	return cw.n, buf.Flush()
+}
+
+// Handler serve the registry in the prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		r.WriteTo(w)
+	})
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// family the name, help and label names shared by the metrics of a vector
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (f *family) Name() string {
+	return f.name
+}
+
+func (f *family) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+}
+
+// key the map key of label values, it panics when the number of values does not match the labels
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs the {name="value",...} of values followed by extra pairs, empty without labels
+func (f *family) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, value := range values {
+		pairs = append(pairs, f.labels[i]+`="`+escapeLabel(value)+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec counters partitioned by label values
+type CounterVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec a counter family registered with Default
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: family{name: name, help: help, kind: "counter", labels: labels}, values: make(map[string]*sample)}
+	Default.Register(c)
+	return c
+}
+
+// Inc add 1 to the counter of values
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add add v, which must not be negative, to the counter of values
+func (c *CounterVec) Add(v float64, values ...string) {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), values...)}
+		c.values[key] = s
+	}
+	s.value += v
+}
+
+func (c *CounterVec) Write(w io.Writer) {
+	c.header(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		s := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(s.labels), formatFloat(s.value))
+	}
+}
+
+// HistogramVec histograms partitioned by label values
+type HistogramVec struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec a histogram family registered with Default, buckets are the sorted upper bounds of the buckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: family{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, values: make(map[string]*histogram)}
+	Default.Register(h)
+	return h
+}
+
+// Observe add v to the histogram of values
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	key := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogram{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) Write(w io.Writer) {
+	h.header(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		s := h.values[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.labels, "le", formatFloat(bound)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(s.labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(s.labels), s.count)
+	}
+}
+
+// Func a gauge or counter whose value is read from fn when the registry is written
+type Func struct {
+	family
+	fn func() float64
+}
+
+// NewGaugeFunc a gauge registered with Default reading its value from fn
+func NewGaugeFunc(name, help string, fn func() float64) *Func {
+	f := &Func{family: family{name: name, help: help, kind: "gauge"}, fn: fn}
+	Default.Register(f)
+	return f
+}
+
+// NewCounterFunc a counter registered with Default reading its value from fn, fn must never decrease
+func NewCounterFunc(name, help string, fn func() float64) *Func {
+	f := &Func{family: family{name: name, help: help, kind: "counter"}, fn: fn}
+	Default.Register(f)
+	return f
+}
+
+func (f *Func) Write(w io.Writer) {
+	f.header(w)
+	fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.fn()))
+}
+
+// Info a gauge that is always 1, its labels carry the information, ie the build of the server
+type Info struct {
+	family
+	fn func() []string
+}
+
+// NewInfoFunc an info metric registered with Default, fn returns its labels as alternating names and values
+func NewInfoFunc(name, help string, fn func() []string) *Info {
+	info := &Info{family: family{name: name, help: help, kind: "gauge"}, fn: fn}
+	Default.Register(info)
+	return info
+}
+
+func (i *Info) Write(w io.Writer) {
+	i.header(w)
+	fmt.Fprintf(w, "%s%s 1\n", i.name, i.labelPairs(nil, i.fn()...))
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch values := m.(type) {
+	case map[string]*sample:
+		for key := range values {
+			keys = append(keys, key)
+		}
+	case map[string]*histogram:
+		for key := range values {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,287 @@
+// Package migrate applies and reverts versioned sql migrations, the applied versions are recorded in the schema_migrations
+// table of the rails app so both keep one history
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rocket/sqlscript"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Migration a version with the sql applying and reverting it, read from VERSION_name.up.sql and VERSION_name.down.sql.
+// VERSION is a rails timestamp, ie 20210304101112, versions are ordered by their number.
+type Migration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status a migration and whether its version is recorded in schema_migrations
+type Status struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+var (
+	// ErrUnknownVersion error when migrating to a version without a migration file
+	ErrUnknownVersion = errors.New("no migration has this version")
+
+	// ErrIrreversible error when reverting a migration without a down file
+	ErrIrreversible = errors.New("migration has no down sql")
+
+	fileName = regexp.MustCompile(`^([0-9]+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+)
+
+// Load read the migrations of dir ordered by version, other files are ignored
+func Load(dir string) ([]*Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+
+		version, name, direction := strings.TrimLeft(match[1], "0"), match[2], match[3]
+		if version == "" {
+			return nil, fmt.Errorf("%s has version 0", file.Name())
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("version %s is used by %s and %s", match[1], m.Name, name)
+		}
+
+		sql, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if direction == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s_%s has no up sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return versionLess(migrations[i].Version, migrations[j].Version) })
+	return migrations, nil
+}
+
+// Migrator applies Migrations to DB
+type Migrator struct {
+	DB *gorm.DB
+
+	// Migrations ordered by version, as returned by Load
+	Migrations []*Migration
+
+	// Fake record and remove versions without running their sql, ie for a schema created before by AutoMigrate
+	Fake bool
+
+	// Log function invoked before a migration runs, up is false when it is reverted
+	Log func(m *Migration, up bool)
+}
+
+// New create a Migrator of migrations
+func New(db *gorm.DB, migrations []*Migration) *Migrator {
+	return &Migrator{DB: db, Migrations: migrations}
+}
+
+// Status every migration and whether it is applied, ordered by version
+func (m *Migrator) Status() ([]*Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		statuses = append(statuses, &Status{Version: migration.Version, Name: migration.Name, Applied: applied[migration.Version]})
+	}
+	return statuses, nil
+}
+
+// Pending the migrations not applied yet, ordered by version
+func (m *Migrator) Pending() ([]*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*Migration
+	for _, migration := range m.Migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up apply every pending migration, it stops at the first failing migration
+func (m *Migrator) Up() ([]*Migration, error) {
+	return m.To("")
+}
+
+// Down revert the steps migrations applied last
+func (m *Migrator) Down(steps int) ([]*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []*Migration
+	for i := len(m.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.Migrations[i]
+		if !applied[migration.Version] {
+			continue
+		}
+
+		if err := m.run(migration, false); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// To apply the pending migrations up to version and revert the applied migrations after it, an empty version applies every
+// migration and 0 reverts every migration
+func (m *Migrator) To(version string) ([]*Migration, error) {
+	all, target := version == "", strings.TrimLeft(version, "0")
+	if target != "" && m.find(target) == nil {
+		return nil, ErrUnknownVersion
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []*Migration
+	for i := len(m.Migrations) - 1; i >= 0 && !all; i-- {
+		migration := m.Migrations[i]
+		if !applied[migration.Version] || !versionLess(target, migration.Version) {
+			continue
+		}
+
+		if err := m.run(migration, false); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+
+	for _, migration := range m.Migrations {
+		if applied[migration.Version] || (!all && versionLess(target, migration.Version)) {
+			continue
+		}
+
+		if err := m.run(migration, true); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// run apply or revert migration and record it in schema_migrations in a transaction. Mysql commits ddl statements as they
+// run, a failing migration may be left half applied there and must be repaired by hand.
+func (m *Migrator) run(migration *Migration, up bool) error {
+	sql := migration.Up
+	if !up {
+		sql = migration.Down
+		if strings.TrimSpace(sql) == "" {
+			return fmt.Errorf("reverting %s_%s: %w", migration.Version, migration.Name, ErrIrreversible)
+		}
+	}
+
+	if m.Log != nil {
+		m.Log(migration, up)
+	}
+
+	tx := m.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if !m.Fake {
+		for _, statement := range sqlscript.Split(sql) {
+			if err := tx.Exec(statement).Error; err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %s_%s failed running %q: %v", migration.Version, migration.Name, statement, err)
+			}
+		}
+	}
+
+	var record *gorm.DB
+	if up {
+		record = tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", migration.Version)
+	} else {
+		record = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+	if err := record.Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("recording migration %s_%s failed: %v", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit().Error
+}
+
+// applied the versions recorded in schema_migrations, the table is created when the rails app did not create it
+func (m *Migrator) applied() (map[string]bool, error) {
+	if err := m.DB.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version varchar(255) NOT NULL PRIMARY KEY)").Error; err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	if err := m.DB.Table("schema_migrations").Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		applied[strings.TrimLeft(version, "0")] = true
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version string) *Migration {
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// versionLess compare versions without leading zeros by their number
+func versionLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,2 @@
+DROP INDEX `index_active_admin_comments_on_parent_id` ON `active_admin_comments`;
+ALTER TABLE `active_admin_comments` DROP COLUMN `parent_id`;
@@ -0,0 +1,3 @@
+-- replies of the threaded comments endpoints
+ALTER TABLE `active_admin_comments` ADD COLUMN `parent_id` bigint DEFAULT NULL;
+CREATE INDEX `index_active_admin_comments_on_parent_id` ON `active_admin_comments` (`parent_id`);
@@ -0,0 +1,15 @@
+CREATE TABLE `audit_logs` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `table_name` varchar(255) NOT NULL,
+  `record_id` varchar(255) NOT NULL,
+  `action` varchar(16) NOT NULL,
+  `actor_type` varchar(255) DEFAULT NULL,
+  `actor_id` bigint DEFAULT NULL,
+  `actor_email` varchar(255) DEFAULT NULL,
+  `ip_address` varchar(45) DEFAULT NULL,
+  `changes` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_audit_logs_on_table_name_and_record_id` (`table_name`,`record_id`),
+  KEY `index_audit_logs_on_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
@@ -0,0 +1,22 @@
+DROP INDEX `index_addresses_on_deleted_at` ON `addresses`;
+ALTER TABLE `addresses` DROP COLUMN `deleted_at`;
+DROP INDEX `index_batteries_on_deleted_at` ON `batteries`;
+ALTER TABLE `batteries` DROP COLUMN `deleted_at`;
+DROP INDEX `index_building_details_on_deleted_at` ON `building_details`;
+ALTER TABLE `building_details` DROP COLUMN `deleted_at`;
+DROP INDEX `index_buildings_on_deleted_at` ON `buildings`;
+ALTER TABLE `buildings` DROP COLUMN `deleted_at`;
+DROP INDEX `index_columns_on_deleted_at` ON `columns`;
+ALTER TABLE `columns` DROP COLUMN `deleted_at`;
+DROP INDEX `index_customers_on_deleted_at` ON `customers`;
+ALTER TABLE `customers` DROP COLUMN `deleted_at`;
+DROP INDEX `index_elevators_on_deleted_at` ON `elevators`;
+ALTER TABLE `elevators` DROP COLUMN `deleted_at`;
+DROP INDEX `index_employees_on_deleted_at` ON `employees`;
+ALTER TABLE `employees` DROP COLUMN `deleted_at`;
+DROP INDEX `index_interventions_on_deleted_at` ON `interventions`;
+ALTER TABLE `interventions` DROP COLUMN `deleted_at`;
+DROP INDEX `index_leads_on_deleted_at` ON `leads`;
+ALTER TABLE `leads` DROP COLUMN `deleted_at`;
+DROP INDEX `index_quotes_on_deleted_at` ON `quotes`;
+ALTER TABLE `quotes` DROP COLUMN `deleted_at`;
@@ -0,0 +1,23 @@
+-- soft delete, records with a deleted_at are in the trash until they are restored or purged
+ALTER TABLE `addresses` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_addresses_on_deleted_at` ON `addresses` (`deleted_at`);
+ALTER TABLE `batteries` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_batteries_on_deleted_at` ON `batteries` (`deleted_at`);
+ALTER TABLE `building_details` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_building_details_on_deleted_at` ON `building_details` (`deleted_at`);
+ALTER TABLE `buildings` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_buildings_on_deleted_at` ON `buildings` (`deleted_at`);
+ALTER TABLE `columns` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_columns_on_deleted_at` ON `columns` (`deleted_at`);
+ALTER TABLE `customers` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_customers_on_deleted_at` ON `customers` (`deleted_at`);
+ALTER TABLE `elevators` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_elevators_on_deleted_at` ON `elevators` (`deleted_at`);
+ALTER TABLE `employees` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_employees_on_deleted_at` ON `employees` (`deleted_at`);
+ALTER TABLE `interventions` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_interventions_on_deleted_at` ON `interventions` (`deleted_at`);
+ALTER TABLE `leads` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_leads_on_deleted_at` ON `leads` (`deleted_at`);
+ALTER TABLE `quotes` ADD COLUMN `deleted_at` datetime DEFAULT NULL;
+CREATE INDEX `index_quotes_on_deleted_at` ON `quotes` (`deleted_at`);
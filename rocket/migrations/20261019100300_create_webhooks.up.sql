@@ -0,0 +1,34 @@
+CREATE TABLE `webhook_subscriptions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `table_name` varchar(255) NOT NULL,
+  `actions` varchar(255) NOT NULL DEFAULT '*',
+  `target_url` varchar(2048) NOT NULL,
+  `secret` varchar(255) NOT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `description` varchar(255) DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_webhook_subscriptions_on_table_name` (`table_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `subscription_id` bigint NOT NULL,
+  `event` varchar(255) NOT NULL,
+  `table_name` varchar(255) NOT NULL,
+  `record_id` varchar(255) NOT NULL,
+  `payload` text NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `attempts` bigint NOT NULL DEFAULT '0',
+  `next_attempt_at` datetime DEFAULT NULL,
+  `last_status_code` bigint DEFAULT NULL,
+  `last_error` text,
+  `delivered_at` datetime DEFAULT NULL,
+  `replay_of` bigint DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_webhook_deliveries_on_subscription_id` (`subscription_id`),
+  KEY `index_webhook_deliveries_on_status_and_next_attempt_at` (`status`,`next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
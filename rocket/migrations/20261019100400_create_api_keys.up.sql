@@ -0,0 +1,18 @@
+CREATE TABLE `api_keys` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `prefix` varchar(32) NOT NULL,
+  `key_hash` varchar(64) NOT NULL,
+  `previous_key_hash` varchar(64) DEFAULT NULL,
+  `previous_key_expires_at` datetime DEFAULT NULL,
+  `scopes` varchar(2048) NOT NULL DEFAULT '*',
+  `roles` varchar(255) NOT NULL DEFAULT '',
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `expires_at` datetime DEFAULT NULL,
+  `last_used_at` datetime DEFAULT NULL,
+  `last_used_ip` varchar(45) DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `index_api_keys_on_prefix` (`prefix`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
@@ -30,10 +30,12 @@ CREATE TABLE `active_admin_comments` (
   `author_id` bigint DEFAULT NULL,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `parent_id` bigint DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_active_admin_comments_on_resource_type_and_resource_id` (`resource_type`,`resource_id`),
   KEY `index_active_admin_comments_on_author_type_and_author_id` (`author_type`,`author_id`),
-  KEY `index_active_admin_comments_on_namespace` (`namespace`)
+  KEY `index_active_admin_comments_on_namespace` (`namespace`),
+  KEY `index_active_admin_comments_on_parent_id` (`parent_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3
 
 JSON Sample
@@ -64,6 +66,8 @@ type ActiveAdminComments struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[ 8] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[ 9] parent_id                                      bigint               null: true   primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	ParentID null.Int `gorm:"column:parent_id;type:bigint;index:index_active_admin_comments_on_parent_id;" json:"parent_id"`
 }
 
 var active_admin_commentsTableInfo = &TableInfo{
@@ -258,6 +262,33 @@ var active_admin_commentsTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        9,
 		},
+
+		&ColumnInfo{
+			Index:              9,
+			Name:               "parent_id",
+			Comment:            `comment this comment replies to, not used by ActiveAdmin`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ParentID",
+			GoFieldType:        "null.Int",
+			JSONFieldName:      "parent_id",
+			ProtobufFieldName:  "parent_id",
+			ProtobufType:       "int64",
+			ProtobufPos:        10,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_active_admin_comments_on_resource_type_and_resource_id", Columns: []string{"resource_type", "resource_id"}},
+		&IndexInfo{Name: "index_active_admin_comments_on_author_type_and_author_id", Columns: []string{"author_type", "author_id"}},
+		&IndexInfo{Name: "index_active_admin_comments_on_namespace", Columns: []string{"namespace"}},
+		&IndexInfo{Name: "index_active_admin_comments_on_parent_id", Columns: []string{"parent_id"}},
 	},
 }
 
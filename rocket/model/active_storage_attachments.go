@@ -187,6 +187,10 @@ var active_storage_attachmentsTableInfo = &TableInfo{
 			ProtobufPos:        6,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_active_storage_attachments_uniqueness", Columns: []string{"record_type", "record_id", "name", "blob_id"}, Unique: true},
+		&IndexInfo{Name: "index_active_storage_attachments_on_blob_id", Columns: []string{"blob_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
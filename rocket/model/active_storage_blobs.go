@@ -233,6 +233,9 @@ var active_storage_blobsTableInfo = &TableInfo{
 			ProtobufPos:        8,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_active_storage_blobs_on_key", Columns: []string{"key"}, Unique: true},
+	},
 }
 
 // TableName sets the insert table name for this struct type
@@ -35,7 +35,9 @@ CREATE TABLE `addresses` (
   `updated_at` datetime NOT NULL,
   `latitude` float DEFAULT NULL,
   `longitude` float DEFAULT NULL,
-  PRIMARY KEY (`id`)
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_addresses_on_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB AUTO_INCREMENT=51 DEFAULT CHARSET=utf8mb3
 
 JSON Sample
@@ -76,6 +78,8 @@ type Addresses struct {
 	Latitude null.Float `gorm:"column:latitude;type:float;" json:"latitude"`
 	//[13] longitude                                      float                null: true   primary: false  isArray: false  auto: false  col: float           len: -1      default: []
 	Longitude null.Float `gorm:"column:longitude;type:float;" json:"longitude"`
+	//[14] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_addresses_on_deleted_at;" json:"deleted_at"`
 }
 
 var addressesTableInfo = &TableInfo{
@@ -375,6 +379,30 @@ var addressesTableInfo = &TableInfo{
 			ProtobufType:       "float",
 			ProtobufPos:        14,
 		},
+
+		&ColumnInfo{
+			Index:              14,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        15,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_addresses_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
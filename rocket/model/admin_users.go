@@ -119,6 +119,7 @@ var admin_usersTableInfo = &TableInfo{
 			IsPrimaryKey:       false,
 			IsAutoIncrement:    false,
 			IsArray:            false,
+			IsSensitive:        true,
 			ColumnType:         "varchar",
 			ColumnLength:       255,
 			GoFieldName:        "EncryptedPassword",
@@ -140,6 +141,7 @@ var admin_usersTableInfo = &TableInfo{
 			IsPrimaryKey:       false,
 			IsAutoIncrement:    false,
 			IsArray:            false,
+			IsSensitive:        true,
 			ColumnType:         "varchar",
 			ColumnLength:       255,
 			GoFieldName:        "ResetPasswordToken",
@@ -161,6 +163,7 @@ var admin_usersTableInfo = &TableInfo{
 			IsPrimaryKey:       false,
 			IsAutoIncrement:    false,
 			IsArray:            false,
+			IsSensitive:        true,
 			ColumnType:         "datetime",
 			ColumnLength:       -1,
 			GoFieldName:        "ResetPasswordSentAt",
@@ -182,6 +185,7 @@ var admin_usersTableInfo = &TableInfo{
 			IsPrimaryKey:       false,
 			IsAutoIncrement:    false,
 			IsArray:            false,
+			IsSensitive:        true,
 			ColumnType:         "datetime",
 			ColumnLength:       -1,
 			GoFieldName:        "RememberCreatedAt",
@@ -234,6 +238,10 @@ var admin_usersTableInfo = &TableInfo{
 			ProtobufPos:        8,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_admin_users_on_email", Columns: []string{"email"}, Unique: true},
+		&IndexInfo{Name: "index_admin_users_on_reset_password_token", Columns: []string{"reset_password_token"}, Unique: true},
+	},
 }
 
 // TableName sets the insert table name for this struct type
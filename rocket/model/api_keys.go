@@ -0,0 +1,409 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `api_keys` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `prefix` varchar(32) NOT NULL,
+  `key_hash` varchar(64) NOT NULL,
+  `previous_key_hash` varchar(64) DEFAULT NULL,
+  `previous_key_expires_at` datetime DEFAULT NULL,
+  `scopes` varchar(2048) NOT NULL DEFAULT '*',
+  `roles` varchar(255) NOT NULL DEFAULT '',
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `expires_at` datetime DEFAULT NULL,
+  `last_used_at` datetime DEFAULT NULL,
+  `last_used_ip` varchar(45) DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `index_api_keys_on_prefix` (`prefix`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3
+
+JSON Sample
+-------------------------------------
+{    "id": 1,    "name": "iot gateway",    "prefix": "rk_3f9a1c2e",    "key_hash": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",    "previous_key_hash": null,    "previous_key_expires_at": null,    "scopes": "elevators:*,columns:*,batteries:*",    "roles": "technician",    "active": true,    "expires_at": "2022-03-04T10:11:12Z",    "last_used_at": "2021-03-05T08:09:10Z",    "last_used_ip": "203.0.113.7",    "created_at": "2021-03-04T10:11:12Z",    "updated_at": "2021-03-04T10:11:12Z"}
+
+
+
+*/
+
+// APIKeys struct is a row record of the api_keys table, a credential of a machine client sent as Authorization: ApiKey <key>
+type APIKeys struct {
+	//[ 0] id                                             bigint               null: false  primary: true   isArray: false  auto: true   col: bigint          len: -1      default: []
+	ID int64 `gorm:"primary_key;AUTO_INCREMENT;column:id;type:bigint;" json:"id"`
+	//[ 1] name                                           varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Name string `gorm:"column:name;type:varchar;size:255;" json:"name"`
+	//[ 2] prefix                                         varchar(32)          null: false  primary: false  isArray: false  auto: false  col: varchar         len: 32      default: []
+	Prefix string `gorm:"column:prefix;type:varchar;size:32;unique_index:index_api_keys_on_prefix;" json:"prefix"`
+	//[ 3] key_hash                                       varchar(64)          null: false  primary: false  isArray: false  auto: false  col: varchar         len: 64      default: []
+	KeyHash string `gorm:"column:key_hash;type:varchar;size:64;" json:"key_hash"`
+	//[ 4] previous_key_hash                              varchar(64)          null: true   primary: false  isArray: false  auto: false  col: varchar         len: 64      default: []
+	PreviousKeyHash null.String `gorm:"column:previous_key_hash;type:varchar;size:64;" json:"previous_key_hash"`
+	//[ 5] previous_key_expires_at                        datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	PreviousKeyExpiresAt null.Time `gorm:"column:previous_key_expires_at;type:datetime;" json:"previous_key_expires_at"`
+	//[ 6] scopes                                         varchar(2048)        null: false  primary: false  isArray: false  auto: false  col: varchar         len: 2048    default: []
+	Scopes string `gorm:"column:scopes;type:varchar;size:2048;default:'*';" json:"scopes"`
+	//[ 7] roles                                          varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Roles string `gorm:"column:roles;type:varchar;size:255;" json:"roles"`
+	//[ 8] active                                         tinyint(1)           null: false  primary: false  isArray: false  auto: false  col: tinyint         len: -1      default: []
+	Active bool `gorm:"column:active;type:tinyint;" json:"active"`
+	//[ 9] expires_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	ExpiresAt null.Time `gorm:"column:expires_at;type:datetime;" json:"expires_at"`
+	//[10] last_used_at                                   datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	LastUsedAt null.Time `gorm:"column:last_used_at;type:datetime;" json:"last_used_at"`
+	//[11] last_used_ip                                   varchar(45)          null: true   primary: false  isArray: false  auto: false  col: varchar         len: 45      default: []
+	LastUsedIP null.String `gorm:"column:last_used_ip;type:varchar;size:45;" json:"last_used_ip"`
+	//[12] created_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
+	//[13] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+}
+
+var api_keysTableInfo = &TableInfo{
+	Name: "api_keys",
+	Columns: []*ColumnInfo{
+
+		&ColumnInfo{
+			Index:              0,
+			Name:               "id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       true,
+			IsAutoIncrement:    true,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ID",
+			GoFieldType:        "int64",
+			JSONFieldName:      "id",
+			ProtobufFieldName:  "id",
+			ProtobufType:       "int64",
+			ProtobufPos:        1,
+		},
+
+		&ColumnInfo{
+			Index:              1,
+			Name:               "name",
+			Comment:            `what the key is used for, ie the iot gateway`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Name",
+			GoFieldType:        "string",
+			JSONFieldName:      "name",
+			ProtobufFieldName:  "name",
+			ProtobufType:       "string",
+			ProtobufPos:        2,
+		},
+
+		&ColumnInfo{
+			Index:              2,
+			Name:               "prefix",
+			Comment:            `public start of the key identifying it in requests and logs`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(32)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       32,
+			GoFieldName:        "Prefix",
+			GoFieldType:        "string",
+			JSONFieldName:      "prefix",
+			ProtobufFieldName:  "prefix",
+			ProtobufType:       "string",
+			ProtobufPos:        3,
+		},
+
+		&ColumnInfo{
+			Index:              3,
+			Name:               "key_hash",
+			Comment:            `sha256 of the key, the key itself is only shown when it is created or rotated`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(64)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			IsSensitive:        true,
+			ColumnType:         "varchar",
+			ColumnLength:       64,
+			GoFieldName:        "KeyHash",
+			GoFieldType:        "string",
+			JSONFieldName:      "key_hash",
+			ProtobufFieldName:  "key_hash",
+			ProtobufType:       "string",
+			ProtobufPos:        4,
+		},
+
+		&ColumnInfo{
+			Index:              4,
+			Name:               "previous_key_hash",
+			Comment:            `sha256 of the key replaced by the last rotation`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(64)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			IsSensitive:        true,
+			ColumnType:         "varchar",
+			ColumnLength:       64,
+			GoFieldName:        "PreviousKeyHash",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "previous_key_hash",
+			ProtobufFieldName:  "previous_key_hash",
+			ProtobufType:       "string",
+			ProtobufPos:        5,
+		},
+
+		&ColumnInfo{
+			Index:              5,
+			Name:               "previous_key_expires_at",
+			Comment:            `until when the key replaced by the last rotation is still accepted`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "PreviousKeyExpiresAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "previous_key_expires_at",
+			ProtobufFieldName:  "previous_key_expires_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        6,
+		},
+
+		&ColumnInfo{
+			Index:              6,
+			Name:               "scopes",
+			Comment:            `comma separated table:action the key may use, * for any table or action`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(2048)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       2048,
+			GoFieldName:        "Scopes",
+			GoFieldType:        "string",
+			JSONFieldName:      "scopes",
+			ProtobufFieldName:  "scopes",
+			ProtobufType:       "string",
+			ProtobufPos:        7,
+		},
+
+		&ColumnInfo{
+			Index:              7,
+			Name:               "roles",
+			Comment:            `comma separated policy roles of the key, empty for the default role`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Roles",
+			GoFieldType:        "string",
+			JSONFieldName:      "roles",
+			ProtobufFieldName:  "roles",
+			ProtobufType:       "string",
+			ProtobufPos:        8,
+		},
+
+		&ColumnInfo{
+			Index:              8,
+			Name:               "active",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "tinyint",
+			DatabaseTypePretty: "tinyint(1)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "tinyint",
+			ColumnLength:       -1,
+			GoFieldName:        "Active",
+			GoFieldType:        "bool",
+			JSONFieldName:      "active",
+			ProtobufFieldName:  "active",
+			ProtobufType:       "bool",
+			ProtobufPos:        9,
+		},
+
+		&ColumnInfo{
+			Index:              9,
+			Name:               "expires_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "ExpiresAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "expires_at",
+			ProtobufFieldName:  "expires_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        10,
+		},
+
+		&ColumnInfo{
+			Index:              10,
+			Name:               "last_used_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "LastUsedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "last_used_at",
+			ProtobufFieldName:  "last_used_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        11,
+		},
+
+		&ColumnInfo{
+			Index:              11,
+			Name:               "last_used_ip",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(45)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       45,
+			GoFieldName:        "LastUsedIP",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "last_used_ip",
+			ProtobufFieldName:  "last_used_ip",
+			ProtobufType:       "string",
+			ProtobufPos:        12,
+		},
+
+		&ColumnInfo{
+			Index:              12,
+			Name:               "created_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "CreatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "created_at",
+			ProtobufFieldName:  "created_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        13,
+		},
+
+		&ColumnInfo{
+			Index:              13,
+			Name:               "updated_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "UpdatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "updated_at",
+			ProtobufFieldName:  "updated_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        14,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_api_keys_on_prefix", Columns: []string{"prefix"}, Unique: true},
+	},
+}
+
+// TableName sets the insert table name for this struct type
+func (a *APIKeys) TableName() string {
+	return "api_keys"
+}
+
+// BeforeSave invoked before saving, return an error if field is not populated.
+func (a *APIKeys) BeforeSave() error {
+	return nil
+}
+
+// Prepare invoked before saving, can be used to populate fields etc.
+func (a *APIKeys) Prepare() {
+}
+
+// Validate invoked before performing action, return an error if field is not populated.
+func (a *APIKeys) Validate(action Action) error {
+	return nil
+}
+
+// TableInfo return table meta data
+func (a *APIKeys) TableInfo() *TableInfo {
+	return api_keysTableInfo
+}
@@ -0,0 +1,314 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `audit_logs` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `table_name` varchar(255) NOT NULL,
+  `record_id` varchar(255) NOT NULL,
+  `action` varchar(16) NOT NULL,
+  `actor_type` varchar(255) DEFAULT NULL,
+  `actor_id` bigint DEFAULT NULL,
+  `actor_email` varchar(255) DEFAULT NULL,
+  `ip_address` varchar(45) DEFAULT NULL,
+  `changes` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_audit_logs_on_table_name_and_record_id` (`table_name`,`record_id`),
+  KEY `index_audit_logs_on_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3
+
+JSON Sample
+-------------------------------------
+{    "id": 1,    "table_name": "elevators",    "record_id": "12",    "action": "Update",    "actor_type": "AdminUser",    "actor_id": 1,    "actor_email": "admin@example.com",    "ip_address": "203.0.113.7",    "changes": {"status": {"old": "Active", "new": "Intervention"}},    "created_at": "2021-03-04T10:11:12Z"}
+
+
+
+*/
+
+// AuditLogs struct is a row record of the audit_logs table, one row per record created, updated or deleted through the dao package
+type AuditLogs struct {
+	//[ 0] id                                             bigint               null: false  primary: true   isArray: false  auto: true   col: bigint          len: -1      default: []
+	ID int64 `gorm:"primary_key;AUTO_INCREMENT;column:id;type:bigint;" json:"id"`
+	//[ 1] table_name                                     varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	TableName_ string `gorm:"column:table_name;type:varchar;size:255;index:index_audit_logs_on_table_name_and_record_id;" json:"table_name"`
+	//[ 2] record_id                                      varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	RecordID string `gorm:"column:record_id;type:varchar;size:255;index:index_audit_logs_on_table_name_and_record_id;" json:"record_id"`
+	//[ 3] action                                         varchar(16)          null: false  primary: false  isArray: false  auto: false  col: varchar         len: 16      default: []
+	Action string `gorm:"column:action;type:varchar;size:16;" json:"action"`
+	//[ 4] actor_type                                     varchar(255)         null: true   primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	ActorType null.String `gorm:"column:actor_type;type:varchar;size:255;" json:"actor_type"`
+	//[ 5] actor_id                                       bigint               null: true   primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	ActorID null.Int `gorm:"column:actor_id;type:bigint;" json:"actor_id"`
+	//[ 6] actor_email                                    varchar(255)         null: true   primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	ActorEmail null.String `gorm:"column:actor_email;type:varchar;size:255;" json:"actor_email"`
+	//[ 7] ip_address                                     varchar(45)          null: true   primary: false  isArray: false  auto: false  col: varchar         len: 45      default: []
+	IPAddress null.String `gorm:"column:ip_address;type:varchar;size:45;" json:"ip_address"`
+	//[ 8] changes                                        text                 null: false  primary: false  isArray: false  auto: false  col: text            len: -1      default: []
+	Changes json.RawMessage `gorm:"column:changes;type:text;" json:"changes"`
+	//[ 9] created_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;index:index_audit_logs_on_created_at;" json:"created_at"`
+}
+
+var audit_logsTableInfo = &TableInfo{
+	Name: "audit_logs",
+	Columns: []*ColumnInfo{
+
+		&ColumnInfo{
+			Index:              0,
+			Name:               "id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       true,
+			IsAutoIncrement:    true,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ID",
+			GoFieldType:        "int64",
+			JSONFieldName:      "id",
+			ProtobufFieldName:  "id",
+			ProtobufType:       "int64",
+			ProtobufPos:        1,
+		},
+
+		&ColumnInfo{
+			Index:              1,
+			Name:               "table_name",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "TableName_",
+			GoFieldType:        "string",
+			JSONFieldName:      "table_name",
+			ProtobufFieldName:  "table_name",
+			ProtobufType:       "string",
+			ProtobufPos:        2,
+		},
+
+		&ColumnInfo{
+			Index:              2,
+			Name:               "record_id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "RecordID",
+			GoFieldType:        "string",
+			JSONFieldName:      "record_id",
+			ProtobufFieldName:  "record_id",
+			ProtobufType:       "string",
+			ProtobufPos:        3,
+		},
+
+		&ColumnInfo{
+			Index:              3,
+			Name:               "action",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(16)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       16,
+			GoFieldName:        "Action",
+			GoFieldType:        "string",
+			JSONFieldName:      "action",
+			ProtobufFieldName:  "action",
+			ProtobufType:       "string",
+			ProtobufPos:        4,
+		},
+
+		&ColumnInfo{
+			Index:              4,
+			Name:               "actor_type",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "ActorType",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "actor_type",
+			ProtobufFieldName:  "actor_type",
+			ProtobufType:       "string",
+			ProtobufPos:        5,
+		},
+
+		&ColumnInfo{
+			Index:              5,
+			Name:               "actor_id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ActorID",
+			GoFieldType:        "null.Int",
+			JSONFieldName:      "actor_id",
+			ProtobufFieldName:  "actor_id",
+			ProtobufType:       "int64",
+			ProtobufPos:        6,
+		},
+
+		&ColumnInfo{
+			Index:              6,
+			Name:               "actor_email",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "ActorEmail",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "actor_email",
+			ProtobufFieldName:  "actor_email",
+			ProtobufType:       "string",
+			ProtobufPos:        7,
+		},
+
+		&ColumnInfo{
+			Index:              7,
+			Name:               "ip_address",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(45)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       45,
+			GoFieldName:        "IPAddress",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "ip_address",
+			ProtobufFieldName:  "ip_address",
+			ProtobufType:       "string",
+			ProtobufPos:        8,
+		},
+
+		&ColumnInfo{
+			Index:              8,
+			Name:               "changes",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "text",
+			DatabaseTypePretty: "text",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "text",
+			ColumnLength:       -1,
+			GoFieldName:        "Changes",
+			GoFieldType:        "json.RawMessage",
+			JSONFieldName:      "changes",
+			ProtobufFieldName:  "changes",
+			ProtobufType:       "string",
+			ProtobufPos:        9,
+		},
+
+		&ColumnInfo{
+			Index:              9,
+			Name:               "created_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "CreatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "created_at",
+			ProtobufFieldName:  "created_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        10,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_audit_logs_on_table_name_and_record_id", Columns: []string{"table_name", "record_id"}},
+		&IndexInfo{Name: "index_audit_logs_on_created_at", Columns: []string{"created_at"}},
+	},
+}
+
+// TableName sets the insert table name for this struct type
+func (a *AuditLogs) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeSave invoked before saving, return an error if field is not populated.
+func (a *AuditLogs) BeforeSave() error {
+	return nil
+}
+
+// Prepare invoked before saving, can be used to populate fields etc.
+func (a *AuditLogs) Prepare() {
+}
+
+// Validate invoked before performing action, return an error if field is not populated.
+func (a *AuditLogs) Validate(action Action) error {
+	return nil
+}
+
+// TableInfo return table meta data
+func (a *AuditLogs) TableInfo() *TableInfo {
+	return audit_logsTableInfo
+}
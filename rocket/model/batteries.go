@@ -33,9 +33,11 @@ CREATE TABLE `batteries` (
   `Notes` text,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_batteries_on_building_id` (`building_id`),
   KEY `index_batteries_on_employee_id` (`employee_id`),
+  KEY `index_batteries_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_ceeeaf55f7` FOREIGN KEY (`employee_id`) REFERENCES `employees` (`id`),
   CONSTRAINT `fk_rails_fc40470545` FOREIGN KEY (`building_id`) REFERENCES `buildings` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=98 DEFAULT CHARSET=utf8mb3
@@ -74,6 +76,8 @@ type Batteries struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[11] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[12] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_batteries_on_deleted_at;" json:"deleted_at"`
 }
 
 var batteriesTableInfo = &TableInfo{
@@ -331,6 +335,32 @@ var batteriesTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        12,
 		},
+
+		&ColumnInfo{
+			Index:              12,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        13,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_batteries_on_building_id", Columns: []string{"building_id"}},
+		&IndexInfo{Name: "index_batteries_on_employee_id", Columns: []string{"employee_id"}},
+		&IndexInfo{Name: "index_batteries_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
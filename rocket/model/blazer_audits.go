@@ -186,6 +186,10 @@ var blazer_auditsTableInfo = &TableInfo{
 			ProtobufPos:        6,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_blazer_audits_on_user_id", Columns: []string{"user_id"}},
+		&IndexInfo{Name: "index_blazer_audits_on_query_id", Columns: []string{"query_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
@@ -330,6 +330,10 @@ var blazer_checksTableInfo = &TableInfo{
 			ProtobufPos:        12,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_blazer_checks_on_creator_id", Columns: []string{"creator_id"}},
+		&IndexInfo{Name: "index_blazer_checks_on_query_id", Columns: []string{"query_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
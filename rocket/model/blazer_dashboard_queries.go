@@ -186,6 +186,10 @@ var blazer_dashboard_queriesTableInfo = &TableInfo{
 			ProtobufPos:        6,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_blazer_dashboard_queries_on_dashboard_id", Columns: []string{"dashboard_id"}},
+		&IndexInfo{Name: "index_blazer_dashboard_queries_on_query_id", Columns: []string{"query_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
@@ -161,6 +161,9 @@ var blazer_dashboardsTableInfo = &TableInfo{
 			ProtobufPos:        5,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_blazer_dashboards_on_creator_id", Columns: []string{"creator_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
@@ -257,6 +257,9 @@ var blazer_queriesTableInfo = &TableInfo{
 			ProtobufPos:        9,
 		},
 	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_blazer_queries_on_creator_id", Columns: []string{"creator_id"}},
+	},
 }
 
 // TableName sets the insert table name for this struct type
@@ -27,8 +27,10 @@ CREATE TABLE `building_details` (
   `Value` varchar(255) DEFAULT NULL,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_building_details_on_building_id` (`building_id`),
+  KEY `index_building_details_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_51749f8eac` FOREIGN KEY (`building_id`) REFERENCES `buildings` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=128 DEFAULT CHARSET=utf8mb3
 
@@ -54,6 +56,8 @@ type BuildingDetails struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[ 5] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[ 6] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_building_details_on_deleted_at;" json:"deleted_at"`
 }
 
 var building_detailsTableInfo = &TableInfo{
@@ -185,6 +189,31 @@ var building_detailsTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        6,
 		},
+
+		&ColumnInfo{
+			Index:              6,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        7,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_building_details_on_building_id", Columns: []string{"building_id"}},
+		&IndexInfo{Name: "index_building_details_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
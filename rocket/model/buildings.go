@@ -32,9 +32,11 @@ CREATE TABLE `buildings` (
   `TechContactPhoneForBuilding` int DEFAULT NULL,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_buildings_on_address_id` (`address_id`),
   KEY `index_buildings_on_customer_id` (`customer_id`),
+  KEY `index_buildings_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_6dc7a885ab` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`),
   CONSTRAINT `fk_rails_c29cbe7fb8` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=50 DEFAULT CHARSET=utf8mb3
@@ -71,6 +73,8 @@ type Buildings struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[10] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[11] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_buildings_on_deleted_at;" json:"deleted_at"`
 }
 
 var buildingsTableInfo = &TableInfo{
@@ -307,6 +311,32 @@ var buildingsTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        11,
 		},
+
+		&ColumnInfo{
+			Index:              11,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        12,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_buildings_on_address_id", Columns: []string{"address_id"}},
+		&IndexInfo{Name: "index_buildings_on_customer_id", Columns: []string{"customer_id"}},
+		&IndexInfo{Name: "index_buildings_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
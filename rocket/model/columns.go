@@ -30,8 +30,10 @@ CREATE TABLE `columns` (
   `Notes` text,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_columns_on_battery_id` (`battery_id`),
+  KEY `index_columns_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_021eb14ac4` FOREIGN KEY (`battery_id`) REFERENCES `batteries` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=197 DEFAULT CHARSET=utf8mb3
 
@@ -63,6 +65,8 @@ type Columns struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[ 8] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[ 9] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_columns_on_deleted_at;" json:"deleted_at"`
 }
 
 var columnsTableInfo = &TableInfo{
@@ -257,6 +261,31 @@ var columnsTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        9,
 		},
+
+		&ColumnInfo{
+			Index:              9,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        10,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_columns_on_battery_id", Columns: []string{"battery_id"}},
+		&IndexInfo{Name: "index_columns_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
@@ -37,9 +37,11 @@ CREATE TABLE `customers` (
   `TechManagerEmailService` varchar(255) DEFAULT NULL,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_customers_on_user_id` (`user_id`),
   KEY `index_customers_on_address_id` (`address_id`),
+  KEY `index_customers_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_3f9404ba26` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`),
   CONSTRAINT `fk_rails_9917eeaf5d` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=35 DEFAULT CHARSET=utf8mb3
@@ -86,6 +88,8 @@ type Customers struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[15] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[16] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_customers_on_deleted_at;" json:"deleted_at"`
 }
 
 var customersTableInfo = &TableInfo{
@@ -427,6 +431,32 @@ var customersTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        16,
 		},
+
+		&ColumnInfo{
+			Index:              16,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        17,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_customers_on_user_id", Columns: []string{"user_id"}},
+		&IndexInfo{Name: "index_customers_on_address_id", Columns: []string{"address_id"}},
+		&IndexInfo{Name: "index_customers_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
@@ -34,8 +34,10 @@ CREATE TABLE `elevators` (
   `Notes` text,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_elevators_on_column_id` (`column_id`),
+  KEY `index_elevators_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_69442d7bc2` FOREIGN KEY (`column_id`) REFERENCES `columns` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=592 DEFAULT CHARSET=utf8mb3
 
@@ -75,6 +77,8 @@ type Elevators struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[12] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[13] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_elevators_on_deleted_at;" json:"deleted_at"`
 }
 
 var elevatorsTableInfo = &TableInfo{
@@ -353,6 +357,31 @@ var elevatorsTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        13,
 		},
+
+		&ColumnInfo{
+			Index:              13,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        14,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_elevators_on_column_id", Columns: []string{"column_id"}},
+		&IndexInfo{Name: "index_elevators_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
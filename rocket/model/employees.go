@@ -29,8 +29,10 @@ CREATE TABLE `employees` (
   `email` varchar(255) DEFAULT NULL,
   `created_at` datetime NOT NULL,
   `updated_at` datetime NOT NULL,
+  `deleted_at` datetime DEFAULT NULL,
   PRIMARY KEY (`id`),
   KEY `index_employees_on_user_id` (`user_id`),
+  KEY `index_employees_on_deleted_at` (`deleted_at`),
   CONSTRAINT `fk_rails_dcfd3d4fc3` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=11 DEFAULT CHARSET=utf8mb3
 
@@ -60,6 +62,8 @@ type Employees struct {
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
 	//[ 7] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+	//[ 8] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_employees_on_deleted_at;" json:"deleted_at"`
 }
 
 var employeesTableInfo = &TableInfo{
@@ -233,6 +237,31 @@ var employeesTableInfo = &TableInfo{
 			ProtobufType:       "google.protobuf.Timestamp",
 			ProtobufPos:        8,
 		},
+
+		&ColumnInfo{
+			Index:              8,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        9,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_employees_on_user_id", Columns: []string{"user_id"}},
+		&IndexInfo{Name: "index_employees_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
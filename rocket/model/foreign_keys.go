@@ -0,0 +1,58 @@
+package model
+
+// ForeignKey a column of Table referencing the RefColumn of RefTable
+type ForeignKey struct {
+	Table     string `json:"table"`
+	Column    string `json:"column"`
+	RefTable  string `json:"ref_table"`
+	RefColumn string `json:"ref_column"`
+}
+
+// ForeignKeys relationships between the tables, the FOREIGN KEY constraints of the schema followed by the rails belongs_to
+// associations of the interventions and blazer tables which are not backed by a constraint
+var ForeignKeys = []*ForeignKey{
+	{Table: "active_storage_attachments", Column: "blob_id", RefTable: "active_storage_blobs", RefColumn: "id"},
+	{Table: "batteries", Column: "employee_id", RefTable: "employees", RefColumn: "id"},
+	{Table: "batteries", Column: "building_id", RefTable: "buildings", RefColumn: "id"},
+	{Table: "building_details", Column: "building_id", RefTable: "buildings", RefColumn: "id"},
+	{Table: "buildings", Column: "address_id", RefTable: "addresses", RefColumn: "id"},
+	{Table: "buildings", Column: "customer_id", RefTable: "customers", RefColumn: "id"},
+	{Table: "columns", Column: "battery_id", RefTable: "batteries", RefColumn: "id"},
+	{Table: "customers", Column: "address_id", RefTable: "addresses", RefColumn: "id"},
+	{Table: "customers", Column: "user_id", RefTable: "users", RefColumn: "id"},
+	{Table: "elevators", Column: "column_id", RefTable: "columns", RefColumn: "id"},
+	{Table: "employees", Column: "user_id", RefTable: "users", RefColumn: "id"},
+	{Table: "webhook_deliveries", Column: "subscription_id", RefTable: "webhook_subscriptions", RefColumn: "id"},
+
+	{Table: "interventions", Column: "customer_id", RefTable: "customers", RefColumn: "id"},
+	{Table: "interventions", Column: "building_id", RefTable: "buildings", RefColumn: "id"},
+	{Table: "interventions", Column: "battery_id", RefTable: "batteries", RefColumn: "id"},
+	{Table: "interventions", Column: "column_id", RefTable: "columns", RefColumn: "id"},
+	{Table: "interventions", Column: "elevator_id", RefTable: "elevators", RefColumn: "id"},
+	{Table: "interventions", Column: "employee_id", RefTable: "employees", RefColumn: "id"},
+	{Table: "blazer_checks", Column: "query_id", RefTable: "blazer_queries", RefColumn: "id"},
+	{Table: "blazer_dashboard_queries", Column: "dashboard_id", RefTable: "blazer_dashboards", RefColumn: "id"},
+	{Table: "blazer_dashboard_queries", Column: "query_id", RefTable: "blazer_queries", RefColumn: "id"},
+}
+
+// ForeignKeysOf the foreign keys declared by the columns of table
+func ForeignKeysOf(table string) []*ForeignKey {
+	var keys []*ForeignKey
+	for _, key := range ForeignKeys {
+		if key.Table == table {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ReferencesTo the foreign keys of other tables referencing table
+func ReferencesTo(table string) []*ForeignKey {
+	var keys []*ForeignKey
+	for _, key := range ForeignKeys {
+		if key.RefTable == table {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -1,6 +1,12 @@
 package model
 
-import "fmt"
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
 
 // Action CRUD actions
 type Action int32
@@ -24,7 +30,17 @@ var (
 	// FetchDDL action when fetching ddl info from db
 	FetchDDL = Action(5)
 
+	// Execute action when the sql statement held by a record is run, ie a blazer_queries statement
+	Execute = Action(6)
+
 	tables map[string]*TableInfo
+
+	// railsNamespaces table name prefixes of namespaced rails models, ie blazer_queries is Blazer::Query
+	railsNamespaces = []struct{ prefix, module string }{
+		{"active_admin_", "ActiveAdmin::"},
+		{"active_storage_", "ActiveStorage::"},
+		{"blazer_", "Blazer::"},
+	}
 )
 
 func init() {
@@ -35,7 +51,9 @@ func init() {
 	tables["active_storage_blobs"] = active_storage_blobsTableInfo
 	tables["addresses"] = addressesTableInfo
 	tables["admin_users"] = admin_usersTableInfo
+	tables["api_keys"] = api_keysTableInfo
 	tables["ar_internal_metadata"] = ar_internal_metadataTableInfo
+	tables["audit_logs"] = audit_logsTableInfo
 	tables["batteries"] = batteriesTableInfo
 	tables["blazer_audits"] = blazer_auditsTableInfo
 	tables["blazer_checks"] = blazer_checksTableInfo
@@ -54,6 +72,8 @@ func init() {
 	tables["quotes"] = quotesTableInfo
 	tables["schema_migrations"] = schema_migrationsTableInfo
 	tables["users"] = usersTableInfo
+	tables["webhook_deliveries"] = webhook_deliveriesTableInfo
+	tables["webhook_subscriptions"] = webhook_subscriptionsTableInfo
 }
 
 // String describe the action
@@ -71,6 +91,8 @@ func (i Action) String() string {
 		return "Delete"
 	case FetchDDL:
 		return "FetchDDL"
+	case Execute:
+		return "Execute"
 	default:
 		return fmt.Sprintf("unknown action: %d", int(i))
 	}
@@ -89,6 +111,14 @@ type Model interface {
 type TableInfo struct {
 	Name    string        `json:"name"`
 	Columns []*ColumnInfo `json:"columns"`
+	Indexes []*IndexInfo  `json:"indexes,omitempty"`
+}
+
+// IndexInfo describes an index of a database table besides its primary key
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"is_unique"`
 }
 
 // ColumnInfo describes a column in the database table
@@ -109,13 +139,166 @@ type ColumnInfo struct {
 	IsPrimaryKey       bool   `json:"is_primary_key"`
 	IsAutoIncrement    bool   `json:"is_auto_increment"`
 	IsArray            bool   `json:"is_array"`
+	IsSensitive        bool   `json:"is_sensitive"`
 	ColumnType         string `json:"column_type"`
 	ColumnLength       int64  `json:"column_length"`
 	DefaultValue       string `json:"default_value"`
 }
 
+// SensitiveColumns columns of the table holding credentials or secrets, they are never sent to or accepted from api clients
+func (t *TableInfo) SensitiveColumns() []*ColumnInfo {
+	var columns []*ColumnInfo
+	for _, col := range t.Columns {
+		if col.IsSensitive {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// Redacted copy of the table info without sensitive columns
+func (t *TableInfo) Redacted() *TableInfo {
+	if len(t.SensitiveColumns()) == 0 {
+		return t
+	}
+
+	sensitive := make(map[string]bool)
+	redacted := &TableInfo{Name: t.Name}
+	for _, col := range t.Columns {
+		if col.IsSensitive {
+			sensitive[col.Name] = true
+		} else {
+			redacted.Columns = append(redacted.Columns, col)
+		}
+	}
+
+	// indexes would reveal the sensitive columns they cover
+	for _, index := range t.Indexes {
+		covers := false
+		for _, name := range index.Columns {
+			covers = covers || sensitive[name]
+		}
+		if !covers {
+			redacted.Indexes = append(redacted.Indexes, index)
+		}
+	}
+	return redacted
+}
+
+// Redact return a copy of record without its sensitive columns, suitable for json encoding.
+// Records without sensitive columns are returned as is.
+func Redact(record Model) interface{} {
+	sensitive := make(map[string]bool)
+	for _, col := range record.TableInfo().SensitiveColumns() {
+		sensitive[col.GoFieldName] = true
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if len(sensitive) == 0 || v.Kind() != reflect.Struct {
+		return record
+	}
+
+	var (
+		fields []reflect.StructField
+		index  []int
+	)
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); !sensitive[f.Name] {
+			fields = append(fields, f)
+			index = append(index, i)
+		}
+	}
+
+	redacted := reflect.New(reflect.StructOf(fields)).Elem()
+	for j, i := range index {
+		redacted.Field(j).Set(v.Field(i))
+	}
+	return redacted.Interface()
+}
+
+// ColumnValue read the value of a column from a record struct, null types are unwrapped and invalid values returned as nil
+func ColumnValue(record interface{}, col *ColumnInfo) (interface{}, bool) {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	f := v.FieldByName(col.GoFieldName)
+	if !f.IsValid() {
+		return nil, false
+	}
+
+	if valuer, ok := f.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		return value, err == nil
+	}
+
+	return f.Interface(), true
+}
+
+// RailsClassName the rails model class of a table as stored in polymorphic *_type columns, ie building_details is BuildingDetail
+func RailsClassName(table string) string {
+	module := ""
+	for _, ns := range railsNamespaces {
+		if strings.HasPrefix(table, ns.prefix) {
+			module, table = ns.module, strings.TrimPrefix(table, ns.prefix)
+			break
+		}
+	}
+
+	return module + TypeName(table)
+}
+
+// TypeName the camel case name of a single record of a table, ie building_details is BuildingDetail
+func TypeName(table string) string {
+	parts := strings.Split(SingularName(table), "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// SingularName the name of a single record of a table, the last word of the table name in the singular, ie building_details
+// is building_detail
+func SingularName(table string) string {
+	parts := strings.Split(table, "_")
+	last := len(parts) - 1
+	switch word := parts[last]; {
+	case strings.HasSuffix(word, "ies"):
+		parts[last] = strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"):
+		parts[last] = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s"):
+		parts[last] = strings.TrimSuffix(word, "s")
+	}
+	return strings.Join(parts, "_")
+}
+
 // GetTableInfo retrieve TableInfo for a table
 func GetTableInfo(name string) (*TableInfo, bool) {
 	val, ok := tables[name]
 	return val, ok
 }
+
+// SensitiveColumnNames the names of the sensitive columns of every table, ie to redact the result of a raw sql statement
+func SensitiveColumnNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, table := range tables {
+		for _, col := range table.SensitiveColumns() {
+			names[strings.ToLower(col.Name)] = true
+		}
+	}
+	return names
+}
+
+// TableNames the tables with a TableInfo, sorted by name
+func TableNames() []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -46,7 +46,9 @@ CREATE TABLE `quotes` (
   `department` varchar(255) DEFAULT NULL,
   `project_name` varchar(255) DEFAULT NULL,
   `project_description` varchar(255) DEFAULT NULL,
-  PRIMARY KEY (`id`)
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_quotes_on_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB AUTO_INCREMENT=51 DEFAULT CHARSET=utf8mb3
 
 JSON Sample
@@ -109,6 +111,8 @@ type Quotes struct {
 	ProjectName null.String `gorm:"column:project_name;type:varchar;size:255;" json:"project_name"`
 	//[24] project_description                            varchar(255)         null: true   primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
 	ProjectDescription null.String `gorm:"column:project_description;type:varchar;size:255;" json:"project_description"`
+	//[25] deleted_at                                     datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeletedAt null.Time `gorm:"column:deleted_at;type:datetime;index:index_quotes_on_deleted_at;" json:"deleted_at"`
 }
 
 var quotesTableInfo = &TableInfo{
@@ -639,6 +643,30 @@ var quotesTableInfo = &TableInfo{
 			ProtobufType:       "string",
 			ProtobufPos:        25,
 		},
+
+		&ColumnInfo{
+			Index:              25,
+			Name:               "deleted_at",
+			Comment:            `set when the record is soft deleted, soft deleted records are hidden from every dao read`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeletedAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "deleted_at",
+			ProtobufFieldName:  "deleted_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        26,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_quotes_on_deleted_at", Columns: []string{"deleted_at"}},
 	},
 }
 
@@ -0,0 +1,434 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `subscription_id` bigint NOT NULL,
+  `event` varchar(255) NOT NULL,
+  `table_name` varchar(255) NOT NULL,
+  `record_id` varchar(255) NOT NULL,
+  `payload` text NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `attempts` bigint NOT NULL DEFAULT '0',
+  `next_attempt_at` datetime DEFAULT NULL,
+  `last_status_code` bigint DEFAULT NULL,
+  `last_error` text,
+  `delivered_at` datetime DEFAULT NULL,
+  `replay_of` bigint DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_webhook_deliveries_on_subscription_id` (`subscription_id`),
+  KEY `index_webhook_deliveries_on_status_and_next_attempt_at` (`status`,`next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3
+
+JSON Sample
+-------------------------------------
+{    "id": 1,    "subscription_id": 1,    "event": "interventions.Update",    "table_name": "interventions",    "record_id": "12",    "payload": {"event": "interventions.Update"},    "status": "delivered",    "attempts": 1,    "next_attempt_at": null,    "last_status_code": 200,    "last_error": null,    "delivered_at": "2021-03-04T10:11:13Z",    "replay_of": null,    "created_at": "2021-03-04T10:11:12Z",    "updated_at": "2021-03-04T10:11:13Z"}
+
+
+
+*/
+
+// WebhookDeliveries struct is a row record of the webhook_deliveries table, one attempt sequence to deliver a change event to a webhook subscription
+type WebhookDeliveries struct {
+	//[ 0] id                                             bigint               null: false  primary: true   isArray: false  auto: true   col: bigint          len: -1      default: []
+	ID int64 `gorm:"primary_key;AUTO_INCREMENT;column:id;type:bigint;" json:"id"`
+	//[ 1] subscription_id                                bigint               null: false  primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	SubscriptionID int64 `gorm:"column:subscription_id;type:bigint;index:index_webhook_deliveries_on_subscription_id;" json:"subscription_id"`
+	//[ 2] event                                          varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Event string `gorm:"column:event;type:varchar;size:255;" json:"event"`
+	//[ 3] table_name                                     varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	TableName_ string `gorm:"column:table_name;type:varchar;size:255;" json:"table_name"`
+	//[ 4] record_id                                      varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	RecordID string `gorm:"column:record_id;type:varchar;size:255;" json:"record_id"`
+	//[ 5] payload                                        text                 null: false  primary: false  isArray: false  auto: false  col: text            len: -1      default: []
+	Payload json.RawMessage `gorm:"column:payload;type:text;" json:"payload"`
+	//[ 6] status                                         varchar(16)          null: false  primary: false  isArray: false  auto: false  col: varchar         len: 16      default: []
+	Status string `gorm:"column:status;type:varchar;size:16;index:index_webhook_deliveries_on_status_and_next_attempt_at;" json:"status"`
+	//[ 7] attempts                                       bigint               null: false  primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	Attempts int64 `gorm:"column:attempts;type:bigint;" json:"attempts"`
+	//[ 8] next_attempt_at                                datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	NextAttemptAt null.Time `gorm:"column:next_attempt_at;type:datetime;index:index_webhook_deliveries_on_status_and_next_attempt_at;" json:"next_attempt_at"`
+	//[ 9] last_status_code                               bigint               null: true   primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	LastStatusCode null.Int `gorm:"column:last_status_code;type:bigint;" json:"last_status_code"`
+	//[10] last_error                                     text                 null: true   primary: false  isArray: false  auto: false  col: text            len: -1      default: []
+	LastError null.String `gorm:"column:last_error;type:text;" json:"last_error"`
+	//[11] delivered_at                                   datetime             null: true   primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	DeliveredAt null.Time `gorm:"column:delivered_at;type:datetime;" json:"delivered_at"`
+	//[12] replay_of                                      bigint               null: true   primary: false  isArray: false  auto: false  col: bigint          len: -1      default: []
+	ReplayOf null.Int `gorm:"column:replay_of;type:bigint;" json:"replay_of"`
+	//[13] created_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
+	//[14] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+}
+
+var webhook_deliveriesTableInfo = &TableInfo{
+	Name: "webhook_deliveries",
+	Columns: []*ColumnInfo{
+
+		&ColumnInfo{
+			Index:              0,
+			Name:               "id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       true,
+			IsAutoIncrement:    true,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ID",
+			GoFieldType:        "int64",
+			JSONFieldName:      "id",
+			ProtobufFieldName:  "id",
+			ProtobufType:       "int64",
+			ProtobufPos:        1,
+		},
+
+		&ColumnInfo{
+			Index:              1,
+			Name:               "subscription_id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "SubscriptionID",
+			GoFieldType:        "int64",
+			JSONFieldName:      "subscription_id",
+			ProtobufFieldName:  "subscription_id",
+			ProtobufType:       "int64",
+			ProtobufPos:        2,
+		},
+
+		&ColumnInfo{
+			Index:              2,
+			Name:               "event",
+			Comment:            `<table>.<action>, sent as X-Rocket-Event`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Event",
+			GoFieldType:        "string",
+			JSONFieldName:      "event",
+			ProtobufFieldName:  "event",
+			ProtobufType:       "string",
+			ProtobufPos:        3,
+		},
+
+		&ColumnInfo{
+			Index:              3,
+			Name:               "table_name",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "TableName_",
+			GoFieldType:        "string",
+			JSONFieldName:      "table_name",
+			ProtobufFieldName:  "table_name",
+			ProtobufType:       "string",
+			ProtobufPos:        4,
+		},
+
+		&ColumnInfo{
+			Index:              4,
+			Name:               "record_id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "RecordID",
+			GoFieldType:        "string",
+			JSONFieldName:      "record_id",
+			ProtobufFieldName:  "record_id",
+			ProtobufType:       "string",
+			ProtobufPos:        5,
+		},
+
+		&ColumnInfo{
+			Index:              5,
+			Name:               "payload",
+			Comment:            `json body posted to the target url`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "text",
+			DatabaseTypePretty: "text",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "text",
+			ColumnLength:       -1,
+			GoFieldName:        "Payload",
+			GoFieldType:        "json.RawMessage",
+			JSONFieldName:      "payload",
+			ProtobufFieldName:  "payload",
+			ProtobufType:       "string",
+			ProtobufPos:        6,
+		},
+
+		&ColumnInfo{
+			Index:              6,
+			Name:               "status",
+			Comment:            `pending, delivered or failed`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(16)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       16,
+			GoFieldName:        "Status",
+			GoFieldType:        "string",
+			JSONFieldName:      "status",
+			ProtobufFieldName:  "status",
+			ProtobufType:       "string",
+			ProtobufPos:        7,
+		},
+
+		&ColumnInfo{
+			Index:              7,
+			Name:               "attempts",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "Attempts",
+			GoFieldType:        "int64",
+			JSONFieldName:      "attempts",
+			ProtobufFieldName:  "attempts",
+			ProtobufType:       "int64",
+			ProtobufPos:        8,
+		},
+
+		&ColumnInfo{
+			Index:              8,
+			Name:               "next_attempt_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "NextAttemptAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "next_attempt_at",
+			ProtobufFieldName:  "next_attempt_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        9,
+		},
+
+		&ColumnInfo{
+			Index:              9,
+			Name:               "last_status_code",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "LastStatusCode",
+			GoFieldType:        "null.Int",
+			JSONFieldName:      "last_status_code",
+			ProtobufFieldName:  "last_status_code",
+			ProtobufType:       "int64",
+			ProtobufPos:        10,
+		},
+
+		&ColumnInfo{
+			Index:              10,
+			Name:               "last_error",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "text",
+			DatabaseTypePretty: "text",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "text",
+			ColumnLength:       -1,
+			GoFieldName:        "LastError",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "last_error",
+			ProtobufFieldName:  "last_error",
+			ProtobufType:       "string",
+			ProtobufPos:        11,
+		},
+
+		&ColumnInfo{
+			Index:              11,
+			Name:               "delivered_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "DeliveredAt",
+			GoFieldType:        "null.Time",
+			JSONFieldName:      "delivered_at",
+			ProtobufFieldName:  "delivered_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        12,
+		},
+
+		&ColumnInfo{
+			Index:              12,
+			Name:               "replay_of",
+			Comment:            `delivery this one replays`,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ReplayOf",
+			GoFieldType:        "null.Int",
+			JSONFieldName:      "replay_of",
+			ProtobufFieldName:  "replay_of",
+			ProtobufType:       "int64",
+			ProtobufPos:        13,
+		},
+
+		&ColumnInfo{
+			Index:              13,
+			Name:               "created_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "CreatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "created_at",
+			ProtobufFieldName:  "created_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        14,
+		},
+
+		&ColumnInfo{
+			Index:              14,
+			Name:               "updated_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "UpdatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "updated_at",
+			ProtobufFieldName:  "updated_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        15,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_webhook_deliveries_on_subscription_id", Columns: []string{"subscription_id"}},
+		&IndexInfo{Name: "index_webhook_deliveries_on_status_and_next_attempt_at", Columns: []string{"status", "next_attempt_at"}},
+	},
+}
+
+// TableName sets the insert table name for this struct type
+func (w *WebhookDeliveries) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeSave invoked before saving, return an error if field is not populated.
+func (w *WebhookDeliveries) BeforeSave() error {
+	return nil
+}
+
+// Prepare invoked before saving, can be used to populate fields etc.
+func (w *WebhookDeliveries) Prepare() {
+}
+
+// Validate invoked before performing action, return an error if field is not populated.
+func (w *WebhookDeliveries) Validate(action Action) error {
+	return nil
+}
+
+// TableInfo return table meta data
+func (w *WebhookDeliveries) TableInfo() *TableInfo {
+	return webhook_deliveriesTableInfo
+}
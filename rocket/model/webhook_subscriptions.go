@@ -0,0 +1,288 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	_ = time.Second
+	_ = sql.LevelDefault
+	_ = null.Bool{}
+	_ = uuid.UUID{}
+)
+
+/*
+DB Table Details
+-------------------------------------
+
+
+CREATE TABLE `webhook_subscriptions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `table_name` varchar(255) NOT NULL,
+  `actions` varchar(255) NOT NULL DEFAULT '*',
+  `target_url` varchar(2048) NOT NULL,
+  `secret` varchar(255) NOT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `description` varchar(255) DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `index_webhook_subscriptions_on_table_name` (`table_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3
+
+JSON Sample
+-------------------------------------
+{    "id": 1,    "table_name": "interventions",    "actions": "Create,Update",    "target_url": "https://tickets.example.com/hooks/rocket",    "secret": "s3cr3t",    "active": true,    "description": "ticketing",    "created_at": "2021-03-04T10:11:12Z",    "updated_at": "2021-03-04T10:11:12Z"}
+
+
+
+*/
+
+// WebhookSubscriptions struct is a row record of the webhook_subscriptions table, an endpoint notified when records of a table change
+type WebhookSubscriptions struct {
+	//[ 0] id                                             bigint               null: false  primary: true   isArray: false  auto: true   col: bigint          len: -1      default: []
+	ID int64 `gorm:"primary_key;AUTO_INCREMENT;column:id;type:bigint;" json:"id"`
+	//[ 1] table_name                                     varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	TableName_ string `gorm:"column:table_name;type:varchar;size:255;index:index_webhook_subscriptions_on_table_name;" json:"table_name"`
+	//[ 2] actions                                        varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Actions string `gorm:"column:actions;type:varchar;size:255;default:'*';" json:"actions"`
+	//[ 3] target_url                                     varchar(2048)        null: false  primary: false  isArray: false  auto: false  col: varchar         len: 2048    default: []
+	TargetURL string `gorm:"column:target_url;type:varchar;size:2048;" json:"target_url"`
+	//[ 4] secret                                         varchar(255)         null: false  primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Secret string `gorm:"column:secret;type:varchar;size:255;" json:"secret"`
+	//[ 5] active                                         tinyint(1)           null: false  primary: false  isArray: false  auto: false  col: tinyint         len: -1      default: []
+	Active bool `gorm:"column:active;type:tinyint;" json:"active"`
+	//[ 6] description                                    varchar(255)         null: true   primary: false  isArray: false  auto: false  col: varchar         len: 255     default: []
+	Description null.String `gorm:"column:description;type:varchar;size:255;" json:"description"`
+	//[ 7] created_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	CreatedAt time.Time `gorm:"column:created_at;type:datetime;" json:"created_at"`
+	//[ 8] updated_at                                     datetime             null: false  primary: false  isArray: false  auto: false  col: datetime        len: -1      default: []
+	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;" json:"updated_at"`
+}
+
+var webhook_subscriptionsTableInfo = &TableInfo{
+	Name: "webhook_subscriptions",
+	Columns: []*ColumnInfo{
+
+		&ColumnInfo{
+			Index:              0,
+			Name:               "id",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "bigint",
+			DatabaseTypePretty: "bigint",
+			IsPrimaryKey:       true,
+			IsAutoIncrement:    true,
+			IsArray:            false,
+			ColumnType:         "bigint",
+			ColumnLength:       -1,
+			GoFieldName:        "ID",
+			GoFieldType:        "int64",
+			JSONFieldName:      "id",
+			ProtobufFieldName:  "id",
+			ProtobufType:       "int64",
+			ProtobufPos:        1,
+		},
+
+		&ColumnInfo{
+			Index:              1,
+			Name:               "table_name",
+			Comment:            `table whose changes are sent, * for every table`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "TableName_",
+			GoFieldType:        "string",
+			JSONFieldName:      "table_name",
+			ProtobufFieldName:  "table_name",
+			ProtobufType:       "string",
+			ProtobufPos:        2,
+		},
+
+		&ColumnInfo{
+			Index:              2,
+			Name:               "actions",
+			Comment:            `comma separated Create, Update and Delete, * for every action`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Actions",
+			GoFieldType:        "string",
+			JSONFieldName:      "actions",
+			ProtobufFieldName:  "actions",
+			ProtobufType:       "string",
+			ProtobufPos:        3,
+		},
+
+		&ColumnInfo{
+			Index:              3,
+			Name:               "target_url",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(2048)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       2048,
+			GoFieldName:        "TargetURL",
+			GoFieldType:        "string",
+			JSONFieldName:      "target_url",
+			ProtobufFieldName:  "target_url",
+			ProtobufType:       "string",
+			ProtobufPos:        4,
+		},
+
+		&ColumnInfo{
+			Index:              4,
+			Name:               "secret",
+			Comment:            `key of the X-Rocket-Signature HMAC`,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			IsSensitive:        true,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Secret",
+			GoFieldType:        "string",
+			JSONFieldName:      "secret",
+			ProtobufFieldName:  "secret",
+			ProtobufType:       "string",
+			ProtobufPos:        5,
+		},
+
+		&ColumnInfo{
+			Index:              5,
+			Name:               "active",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "tinyint",
+			DatabaseTypePretty: "tinyint(1)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "tinyint",
+			ColumnLength:       -1,
+			GoFieldName:        "Active",
+			GoFieldType:        "bool",
+			JSONFieldName:      "active",
+			ProtobufFieldName:  "active",
+			ProtobufType:       "bool",
+			ProtobufPos:        6,
+		},
+
+		&ColumnInfo{
+			Index:              6,
+			Name:               "description",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           true,
+			DatabaseTypeName:   "varchar",
+			DatabaseTypePretty: "varchar(255)",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "varchar",
+			ColumnLength:       255,
+			GoFieldName:        "Description",
+			GoFieldType:        "null.String",
+			JSONFieldName:      "description",
+			ProtobufFieldName:  "description",
+			ProtobufType:       "string",
+			ProtobufPos:        7,
+		},
+
+		&ColumnInfo{
+			Index:              7,
+			Name:               "created_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "CreatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "created_at",
+			ProtobufFieldName:  "created_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        8,
+		},
+
+		&ColumnInfo{
+			Index:              8,
+			Name:               "updated_at",
+			Comment:            ``,
+			Notes:              ``,
+			Nullable:           false,
+			DatabaseTypeName:   "datetime",
+			DatabaseTypePretty: "datetime",
+			IsPrimaryKey:       false,
+			IsAutoIncrement:    false,
+			IsArray:            false,
+			ColumnType:         "datetime",
+			ColumnLength:       -1,
+			GoFieldName:        "UpdatedAt",
+			GoFieldType:        "time.Time",
+			JSONFieldName:      "updated_at",
+			ProtobufFieldName:  "updated_at",
+			ProtobufType:       "google.protobuf.Timestamp",
+			ProtobufPos:        9,
+		},
+	},
+	Indexes: []*IndexInfo{
+		&IndexInfo{Name: "index_webhook_subscriptions_on_table_name", Columns: []string{"table_name"}},
+	},
+}
+
+// TableName sets the insert table name for this struct type
+func (w *WebhookSubscriptions) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// BeforeSave invoked before saving, return an error if field is not populated.
+func (w *WebhookSubscriptions) BeforeSave() error {
+	return nil
+}
+
+// Prepare invoked before saving, can be used to populate fields etc.
+func (w *WebhookSubscriptions) Prepare() {
+}
+
+// Validate invoked before performing action, return an error if field is not populated.
+func (w *WebhookSubscriptions) Validate(action Action) error {
+	return nil
+}
+
+// TableInfo return table meta data
+func (w *WebhookSubscriptions) TableInfo() *TableInfo {
+	return webhook_subscriptionsTableInfo
+}
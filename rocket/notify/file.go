@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends messages to a file instead of sending them, a stand in for a mail server during local development
+type FileNotifier struct {
+	// Path file messages are appended to, it is created when missing
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileNotifier create a FileNotifier appending to path
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{Path: path}
+}
+
+// Notify append msg to the file
+func (n *FileNotifier) Notify(ctx context.Context, msg *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err = writeMessage(f, msg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LogNotifier writes messages to a logger, nil uses the standard logger
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+// Notify log msg
+func (n *LogNotifier) Notify(ctx context.Context, msg *Message) error {
+	var buf strings.Builder
+	if err := writeMessage(&buf, msg); err != nil {
+		return err
+	}
+
+	if n.Logger != nil {
+		n.Logger.Print(buf.String())
+	} else {
+		log.Print(buf.String())
+	}
+	return nil
+}
+
+func writeMessage(w io.Writer, msg *Message) error {
+	_, err := fmt.Fprintf(w, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z), strings.Join(append(msg.To, msg.Channels...), ", "), msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Message is a notification to be delivered by a Notifier
+type Message struct {
+	// To email addresses the message is addressed to
+	To []string `json:"to,omitempty"`
+
+	// Channels chat channels the message is addressed to, ie slack channels
+	Channels []string `json:"channels,omitempty"`
+
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+
+	// Fields structured details about the event, passed through to webhook receivers
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Notifier delivers a message to its recipients
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(ctx context.Context, msg *Message) error
+
+// Notify invokes f
+func (f NotifierFunc) Notify(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Multi fans a message out to several notifiers, every notifier is invoked even when an earlier one fails.
+type Multi []Notifier
+
+// Notify delivers msg through every notifier, returning a combined error of all failures
+func (m Multi) Notify(ctx context.Context, msg *Message) error {
+	var errs []string
+	for _, n := range m {
+		if n == nil {
+			continue
+		}
+
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SplitRecipients splits a comma, semicolon or whitespace separated list as stored in the emails or slack_channels columns
+func SplitRecipients(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t' || r == '\r'
+	})
+
+	var recipients []string
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			recipients = append(recipients, f)
+		}
+	}
+	return recipients
+}
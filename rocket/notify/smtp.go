@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// smtpTimeout longest smtp conversation when the context of Notify has no earlier deadline
+const smtpTimeout = time.Minute
+
+// SMTPNotifier delivers messages as plain text email through an SMTP relay
+type SMTPNotifier struct {
+	// Addr host:port of the smtp server
+	Addr string
+
+	// From address used in the envelope and the From header
+	From string
+
+	// Auth optional smtp authentication, nil for unauthenticated relays such as a local mail catcher
+	Auth smtp.Auth
+}
+
+// NewSMTPNotifier create a SMTPNotifier, PLAIN auth is used when username is not empty
+func NewSMTPNotifier(addr, from, username, password string) *SMTPNotifier {
+	n := &SMTPNotifier{Addr: addr, From: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		n.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return n
+}
+
+// Notify sends msg to the email recipients, messages without email recipients are ignored
+func (n *SMTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", sanitizeHeader(msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.Replace(msg.Body, "\n", "\r\n", -1))
+
+	if err := n.send(ctx, msg.To, buf.Bytes()); err != nil {
+		return fmt.Errorf("smtp send to %s failed: %v", n.Addr, err)
+	}
+	return nil
+}
+
+// send deliver message as smtp.SendMail does, the conversation ends when ctx is done or after smtpTimeout so a hung server
+// cannot block the caller
+func (n *SMTPNotifier) send(ctx context.Context, to []string, message []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// cancelling ctx interrupts a read or write in progress
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	host, _, _ := net.SplitHostPort(n.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	defer c.Close()
+
+	if err := n.converse(c, host, to, message); err != nil {
+		return contextError(ctx, err)
+	}
+	return nil
+}
+
+// converse send message over c, upgrading to tls when the server offers STARTTLS
+func (n *SMTPNotifier) converse(c *smtp.Client, host string, to []string, message []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if n.Auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("the server does not support AUTH")
+		}
+		if err := c.Auth(n.Auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// contextError the error of ctx when it ended the conversation, err otherwise. The connection deadline may expire just
+// before ctx reports it.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+func sanitizeHeader(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
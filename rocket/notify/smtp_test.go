@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hungSMTPServer a local server accepting connections without ever greeting, as a stalled relay
+func hungSMTPServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conns []net.Conn
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		listener.Close()
+		<-done
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	return listener.Addr().String()
+}
+
+func TestSMTPNotifierHonorsContext(t *testing.T) {
+	addr := hungSMTPServer(t)
+	n := NewSMTPNotifier(addr, "rocket@example.com", "", "")
+	msg := &Message{To: []string{"ops@example.com"}, Subject: "check failed", Body: "elevators"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := n.Notify(ctx, msg)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Notify to a hung server = %v, want the deadline of the context", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify returned after %v, want shortly after the 100ms deadline", elapsed)
+	}
+
+	// a context without deadline ends the conversation when cancelled
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start = time.Now()
+	err = n.Notify(ctx, msg)
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("Notify cancelled while the server hangs = %v, want the context cancellation", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify returned after %v, want shortly after the cancellation", elapsed)
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier delivers messages by POSTing them as json to a url, ie a slack relay or chat-ops bot
+type WebhookNotifier struct {
+	URL string
+
+	// Headers additional headers sent with every request, ie an authorization token
+	Headers map[string]string
+
+	Client *http.Client
+}
+
+// NewWebhookNotifier create a WebhookNotifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts msg to the webhook url, any non 2xx response is reported as an error
+func (n *WebhookNotifier) Notify(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook post to %s failed: %v", n.URL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook post to %s returned status %d", n.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package policy
+
+// DefaultPolicy policy used when no policy file is configured
+const DefaultPolicy = `
+default_role: read-only
+admin_role: admin
+
+employee_titles:
+  Dispatcher: dispatcher
+  Technician: technician
+  Sales: sales
+  Sales Representative: sales
+
+rules:
+  - roles: [admin]
+    tables: ["*"]
+    actions: ["*"]
+    effect: allow
+
+  # every authenticated account may read the business tables and the table metadata, running the statements of
+  # blazer_queries (Execute) is left to admins
+  - roles: ["*"]
+    tables: ["*"]
+    actions: [RetrieveOne, RetrieveMany, FetchDDL]
+    effect: allow
+
+  # any account may comment on the records it can read as itself, see /<resource>/{id}/comments. Writing
+  # active_admin_comments directly sets any author and is left to admins
+  - roles: ["*"]
+    tables: [comments]
+    actions: [Create]
+    effect: allow
+
+  - roles: [dispatcher]
+    tables: [interventions, elevators, columns, batteries, buildings, building_details]
+    actions: [Create, Update]
+    effect: allow
+
+  - roles: [dispatcher]
+    tables: [interventions]
+    actions: [Delete]
+    effect: allow
+
+  - roles: [technician]
+    tables: [interventions]
+    actions: [Update]
+    effect: allow
+    where:
+      employee_id: $employee_id
+
+  - roles: [technician]
+    tables: [elevators, columns, batteries]
+    actions: [Update]
+    effect: allow
+
+  - roles: [sales]
+    tables: [leads, quotes, customers, addresses, buildings, building_details]
+    actions: [Create, Update]
+    effect: allow
+
+  # accounts, credentials, webhooks, internal rails bookkeeping and the server metrics are reserved to admins
+  - roles: [dispatcher, technician, sales, read-only]
+    tables: [users, admin_users, api_keys, ar_internal_metadata, schema_migrations, audit_logs, webhook_subscriptions, webhook_deliveries, metrics]
+    actions: ["*"]
+    effect: deny
+`
@@ -0,0 +1,329 @@
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"rocket/model"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Wildcard matches any role, table or action in a rule
+	Wildcard = "*"
+
+	// EffectAllow rule grants access
+	EffectAllow = "allow"
+
+	// EffectDeny rule refuses access, deny rules take precedence over allow rules
+	EffectDeny = "deny"
+)
+
+// PseudoTables names authorized like tables that are not tables, ddl guards the table metadata, metrics the /metrics endpoint
+// and comments posting to /<resource>/{id}/comments
+var PseudoTables = map[string]bool{"ddl": true, "metrics": true, "comments": true}
+
+// Policy declarative access policy mapping role x table x action to allow or deny
+type Policy struct {
+	// DefaultRole role of a users account without any other assignment
+	DefaultRole string `yaml:"default_role"`
+
+	// AdminRole role of every admin_users account
+	AdminRole string `yaml:"admin_role"`
+
+	// EmployeeTitles role of a users account linked to an employees record, keyed by employees.title
+	EmployeeTitles map[string]string `yaml:"employee_titles"`
+
+	// Users explicit roles of users accounts keyed by email, overrides EmployeeTitles. Emails are matched ignoring case.
+	Users map[string][]string `yaml:"users"`
+
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule grants or refuses roles the listed actions on the listed tables.
+// Where restricts an allow rule to rows whose columns equal the given values, a value starting with $ refers to
+// an attribute of the caller such as $user_id or $employee_id.
+type Rule struct {
+	Roles   []string          `yaml:"roles"`
+	Tables  []string          `yaml:"tables"`
+	Actions []string          `yaml:"actions"`
+	Effect  string            `yaml:"effect"`
+	Where   map[string]string `yaml:"where"`
+}
+
+// Subject the caller a decision is made for
+type Subject struct {
+	Roles []string
+
+	// Attributes values referenced by $name in rule conditions, ie user_id and employee_id
+	Attributes map[string]interface{}
+}
+
+// Condition column values a row must have
+type Condition map[string]interface{}
+
+// Decision outcome of evaluating a policy for a table and action.
+// When Allowed is true and Conditions is not empty the caller may only act on rows matching one of the conditions.
+type Decision struct {
+	Allowed    bool
+	Conditions []Condition
+}
+
+// Unconditional reports if the decision applies to every row of the table
+func (d *Decision) Unconditional() bool {
+	return d.Allowed && len(d.Conditions) == 0
+}
+
+// Load read and validate a policy file
+func Load(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parse and validate a yaml policy document
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+
+	// emails are looked up lowercased by RolesFor
+	users := make(map[string][]string, len(p.Users))
+	for email, roles := range p.Users {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if _, ok := users[key]; ok {
+			return nil, fmt.Errorf("invalid policy: user %q is listed twice", key)
+		}
+		users[key] = roles
+	}
+	p.Users = users
+
+	for i, rule := range p.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("invalid policy: rule %d effect must be allow or deny, got %q", i, rule.Effect)
+		}
+
+		if len(rule.Roles) == 0 || len(rule.Tables) == 0 || len(rule.Actions) == 0 {
+			return nil, fmt.Errorf("invalid policy: rule %d must list roles, tables and actions", i)
+		}
+
+		for _, table := range rule.Tables {
+			if _, ok := model.GetTableInfo(table); !ok && table != Wildcard && !PseudoTables[table] {
+				return nil, fmt.Errorf("invalid policy: rule %d unknown table %q", i, table)
+			}
+		}
+
+		for _, action := range rule.Actions {
+			if action != Wildcard && ParseAction(action) < 0 {
+				return nil, fmt.Errorf("invalid policy: rule %d unknown action %q", i, action)
+			}
+		}
+
+		for column := range rule.Where {
+			for _, table := range rule.Tables {
+				if table == Wildcard {
+					continue
+				}
+				if _, ok := FindColumn(table, column); !ok {
+					return nil, fmt.Errorf("invalid policy: rule %d unknown column %q in table %q", i, column, table)
+				}
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// RolesFor resolve the roles of an account, admin accounts get AdminRole, users accounts an explicit role by email,
+// a role by the title of their employees record or DefaultRole.
+func (p *Policy) RolesFor(admin bool, email, employeeTitle string) []string {
+	if admin {
+		return []string{p.AdminRole}
+	}
+
+	if roles, ok := p.Users[strings.ToLower(email)]; ok {
+		return roles
+	}
+
+	for title, role := range p.EmployeeTitles {
+		if employeeTitle != "" && strings.EqualFold(title, employeeTitle) {
+			return []string{role}
+		}
+	}
+
+	return []string{p.DefaultRole}
+}
+
+// Decide evaluate the policy for subject performing action on table. Deny rules win over allow rules, anything not allowed is denied.
+func (p *Policy) Decide(subject *Subject, table string, action model.Action) *Decision {
+	decision := &Decision{}
+	unconditional := false
+
+	for _, rule := range p.Rules {
+		if !rule.matches(subject.Roles, table, action) {
+			continue
+		}
+
+		if rule.Effect == EffectDeny {
+			if len(rule.Where) == 0 {
+				return &Decision{}
+			}
+			// conditional deny rules are enforced per row by Check
+			continue
+		}
+
+		decision.Allowed = true
+		if len(rule.Where) == 0 {
+			unconditional = true
+			continue
+		}
+
+		cond, ok := rule.condition(subject)
+		if !ok {
+			// the subject lacks an attribute the rule depends on, ie a user without an employees record
+			continue
+		}
+		decision.Conditions = append(decision.Conditions, cond)
+	}
+
+	if unconditional {
+		decision.Conditions = nil
+	} else if decision.Allowed && len(decision.Conditions) == 0 {
+		decision.Allowed = false
+	}
+
+	return decision
+}
+
+// Check evaluate the policy for a single record of table
+func (p *Policy) Check(subject *Subject, table string, action model.Action, record interface{}) bool {
+	for _, rule := range p.Rules {
+		if rule.Effect != EffectDeny || len(rule.Where) == 0 || !rule.matches(subject.Roles, table, action) {
+			continue
+		}
+
+		if cond, ok := rule.condition(subject); ok && cond.Matches(table, record) {
+			return false
+		}
+	}
+
+	decision := p.Decide(subject, table, action)
+	if !decision.Allowed {
+		return false
+	}
+
+	if decision.Unconditional() {
+		return true
+	}
+
+	for _, cond := range decision.Conditions {
+		if cond.Matches(table, record) {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyConditions conditions of deny rules that apply to subject, rows matching any of them must be excluded
+func (p *Policy) DenyConditions(subject *Subject, table string, action model.Action) []Condition {
+	var conditions []Condition
+	for _, rule := range p.Rules {
+		if rule.Effect != EffectDeny || len(rule.Where) == 0 || !rule.matches(subject.Roles, table, action) {
+			continue
+		}
+
+		if cond, ok := rule.condition(subject); ok {
+			conditions = append(conditions, cond)
+		}
+	}
+	return conditions
+}
+
+func (r *Rule) matches(roles []string, table string, action model.Action) bool {
+	return matchAny(r.Roles, roles) && matchOne(r.Tables, table) && matchOne(r.Actions, action.String())
+}
+
+func (r *Rule) condition(subject *Subject) (Condition, bool) {
+	cond := make(Condition, len(r.Where))
+	for column, value := range r.Where {
+		if strings.HasPrefix(value, "$") {
+			attr, ok := subject.Attributes[value[1:]]
+			if !ok || attr == nil {
+				return nil, false
+			}
+			cond[column] = attr
+			continue
+		}
+		cond[column] = value
+	}
+	return cond, true
+}
+
+// Matches reports if every column of the condition has the expected value in record
+func (c Condition) Matches(table string, record interface{}) bool {
+	for column, expected := range c {
+		actual, ok := ColumnValue(table, record, column)
+		if !ok || actual == nil || fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindColumn look up a column of table by its database or json name
+func FindColumn(table, name string) (*model.ColumnInfo, bool) {
+	info, ok := model.GetTableInfo(table)
+	if !ok {
+		return nil, false
+	}
+
+	for _, col := range info.Columns {
+		if strings.EqualFold(col.Name, name) || col.JSONFieldName == name {
+			return col, true
+		}
+	}
+	return nil, false
+}
+
+// ColumnValue read the value of a column from a model struct, null types are unwrapped and invalid values returned as nil
+func ColumnValue(table string, record interface{}, column string) (interface{}, bool) {
+	col, ok := FindColumn(table, column)
+	if !ok {
+		return nil, false
+	}
+
+	return model.ColumnValue(record, col)
+}
+
+func matchAny(patterns, values []string) bool {
+	for _, v := range values {
+		if matchOne(patterns, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOne(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if p == Wildcard || strings.EqualFold(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAction the action called name, ie RetrieveMany, case insensitive, -1 for an unknown name
+func ParseAction(name string) model.Action {
+	for _, action := range []model.Action{model.Create, model.RetrieveOne, model.RetrieveMany, model.Update, model.Delete, model.FetchDDL, model.Execute} {
+		if strings.EqualFold(action.String(), name) {
+			return action
+		}
+	}
+	return -1
+}
@@ -0,0 +1,149 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"rocket/model"
+
+	"github.com/guregu/null"
+)
+
+func TestParseDefaultPolicy(t *testing.T) {
+	if _, err := Parse([]byte(DefaultPolicy)); err != nil {
+		t.Fatalf("Parse(DefaultPolicy) = %v", err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name, policy, want string
+	}{
+		{"unknown field", "rule: []", "field rule not found"},
+		{"bad effect", "rules: [{roles: [a], tables: [leads], actions: [Create], effect: maybe}]", "effect must be allow or deny"},
+		{"no roles", "rules: [{tables: [leads], actions: [Create], effect: allow}]", "must list roles, tables and actions"},
+		{"unknown table", "rules: [{roles: [a], tables: [nope], actions: [Create], effect: allow}]", `unknown table "nope"`},
+		{"unknown action", "rules: [{roles: [a], tables: [leads], actions: [Launch], effect: allow}]", `unknown action "Launch"`},
+		{"unknown column", "rules: [{roles: [a], tables: [leads], actions: [Update], effect: allow, where: {nope: $user_id}}]", `unknown column "nope"`},
+		{"user twice", "users: {jane@rocket.io: [sales], Jane@Rocket.io: [admin]}", `user "jane@rocket.io" is listed twice`},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.policy))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse with %s = %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRolesFor(t *testing.T) {
+	p, err := Parse([]byte(`
+default_role: read-only
+admin_role: admin
+employee_titles:
+  Technician: technician
+users:
+  Jane.Doe@Rocket.io: [sales, dispatcher]
+  " bob@rocket.io ": [sales]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		admin        bool
+		email, title string
+		want         []string
+	}{
+		{true, "jane.doe@rocket.io", "", []string{"admin"}},
+		{false, "jane.doe@rocket.io", "", []string{"sales", "dispatcher"}},
+		{false, "JANE.DOE@ROCKET.IO", "Technician", []string{"sales", "dispatcher"}},
+		{false, "bob@rocket.io", "", []string{"sales"}},
+		{false, "tom@rocket.io", "technician", []string{"technician"}},
+		{false, "tom@rocket.io", "", []string{"read-only"}},
+	}
+
+	for _, tt := range tests {
+		if got := p.RolesFor(tt.admin, tt.email, tt.title); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RolesFor(%v, %q, %q) = %v, want %v", tt.admin, tt.email, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	p, err := Parse([]byte(DefaultPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	technician := &Subject{Roles: []string{"technician"}, Attributes: map[string]interface{}{"employee_id": int64(3)}}
+	tests := []struct {
+		subject       *Subject
+		table         string
+		action        model.Action
+		allowed       bool
+		unconditional bool
+	}{
+		{&Subject{Roles: []string{"admin"}}, "users", model.Delete, true, true},
+		{&Subject{Roles: []string{"read-only"}}, "leads", model.RetrieveMany, true, true},
+		{&Subject{Roles: []string{"read-only"}}, "leads", model.Create, false, false},
+		{&Subject{Roles: []string{"read-only"}}, "users", model.RetrieveOne, false, false},
+		{&Subject{Roles: []string{"sales"}}, "metrics", model.RetrieveMany, false, false},
+		{&Subject{Roles: []string{"sales"}}, "comments", model.Create, true, true},
+		{&Subject{Roles: []string{"sales"}}, "active_admin_comments", model.Create, false, false},
+		{technician, "interventions", model.Update, true, false},
+		{&Subject{Roles: []string{"technician"}}, "interventions", model.Update, false, false},
+		{&Subject{Roles: []string{"dispatcher"}}, "blazer_queries", model.Execute, false, false},
+	}
+
+	for _, tt := range tests {
+		d := p.Decide(tt.subject, tt.table, tt.action)
+		if d.Allowed != tt.allowed || d.Unconditional() != tt.unconditional {
+			t.Errorf("Decide(%v, %s, %s) = allowed %v unconditional %v, want %v %v", tt.subject.Roles, tt.table, tt.action,
+				d.Allowed, d.Unconditional(), tt.allowed, tt.unconditional)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(`
+rules:
+  - roles: [technician]
+    tables: [interventions]
+    actions: [Update]
+    effect: allow
+    where:
+      employee_id: $employee_id
+  - roles: ["*"]
+    tables: [interventions]
+    actions: [Update]
+    effect: deny
+    where:
+      status: Closed
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	technician := &Subject{Roles: []string{"technician"}, Attributes: map[string]interface{}{"employee_id": int64(3)}}
+	tests := []struct {
+		record *model.Interventions
+		want   bool
+	}{
+		{&model.Interventions{EmployeeID: null.IntFrom(3), Status: null.StringFrom("Pending")}, true},
+		{&model.Interventions{EmployeeID: null.IntFrom(4), Status: null.StringFrom("Pending")}, false},
+		{&model.Interventions{Status: null.StringFrom("Pending")}, false},
+		{&model.Interventions{EmployeeID: null.IntFrom(3), Status: null.StringFrom("Closed")}, false},
+	}
+
+	for _, tt := range tests {
+		if got := p.Check(technician, "interventions", model.Update, tt.record); got != tt.want {
+			t.Errorf("Check(employee %v, status %v) = %v, want %v", tt.record.EmployeeID.Int64, tt.record.Status.String, got, tt.want)
+		}
+	}
+
+	if conds := p.DenyConditions(technician, "interventions", model.Update); len(conds) != 1 || conds[0]["status"] != "Closed" {
+		t.Errorf("DenyConditions = %v, want the closed status", conds)
+	}
+}
@@ -0,0 +1,167 @@
+// Package ratelimit token buckets limiting how often a client, ie a user or an ip address, may call the api
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit Requests allowed per Period, a client may spend them in a single burst and regains them evenly over the period. The
+// zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited reports if l allows everything
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "none"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// ParseLimit parse a limit written as requests/period, ie 60/1m or 10/s, none or 0 is the zero Limit
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "none" || s == "0" {
+		return Limit{}, nil
+	}
+
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("rate limit %q is not requests/period", s)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid number of requests", s)
+	}
+
+	period := parts[1]
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid period", s)
+	}
+	return Limit{Requests: requests, Period: duration}, nil
+}
+
+// Result outcome of a request against a bucket
+type Result struct {
+	// Allowed reports if the request may proceed
+	Allowed bool
+
+	// Limit the size of the bucket
+	Limit int
+
+	// Remaining requests left in the bucket
+	Remaining int
+
+	// Reset time until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter time until the next request is allowed, 0 when Allowed
+	RetryAfter time.Duration
+}
+
+// sweepInterval how often buckets that filled up again are dropped
+const sweepInterval = time.Minute
+
+// Limiter token buckets by key, a bucket is dropped once it is full again so memory stays bounded by the active clients
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// Now returns the current time, tests may replace it
+	Now func() time.Time
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// New an empty limiter
+func New() *Limiter {
+	return &Limiter{buckets: make(map[string]*bucket), Now: time.Now}
+}
+
+// Allow take a token from the bucket of key, which is created full with limit
+func (l *Limiter) Allow(key string, limit Limit) Result {
+	if limit.Unlimited() {
+		return Result{Allowed: true}
+	}
+
+	now := l.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{limit: limit, tokens: float64(limit.Requests), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = b.timeFor(1)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = b.timeFor(float64(limit.Requests))
+	return result
+}
+
+// sweep drop the buckets that are full by now, a new full bucket replaces them on their next request
+func (l *Limiter) sweep(now time.Time) {
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.rate())
+}
+
+// rate tokens regained per second
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / b.limit.Period.Seconds()
+}
+
+// timeFor time until the bucket holds tokens
+func (b *bucket) timeFor(tokens float64) time.Duration {
+	missing := tokens - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.rate() * float64(time.Second))
+}
@@ -0,0 +1,359 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"rocket/model"
+)
+
+// MarshalRecord the message of a record, one field per column that is not sensitive numbered by its protobuf position. Null columns
+// and zero values of columns that are not nullable are left out as protobuf does.
+func MarshalRecord(record model.Model) ([]byte, error) {
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+
+	var message []byte
+	for _, col := range record.TableInfo().Columns {
+		raw, ok := fields[col.JSONFieldName]
+		if col.IsSensitive || !ok || string(raw) == "null" {
+			continue
+		}
+
+		if message, err = appendColumn(message, col, raw); err != nil {
+			return nil, fmt.Errorf("column %s: %v", col.JSONFieldName, err)
+		}
+	}
+	return message, nil
+}
+
+// appendColumn append the field of col holding the json value raw
+func appendColumn(b []byte, col *model.ColumnInfo, raw json.RawMessage) ([]byte, error) {
+	num := protowire.Number(col.ProtobufPos)
+	switch col.ProtobufType {
+	case "int32", "int64":
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, err
+		}
+		if n == 0 && !col.Nullable {
+			return b, nil
+		}
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(n)), nil
+	case "float", "double":
+		var f float64
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, err
+		}
+		if f == 0 && !col.Nullable {
+			return b, nil
+		}
+		if col.ProtobufType == "float" {
+			b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+			return protowire.AppendFixed32(b, math.Float32bits(float32(f))), nil
+		}
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(f)), nil
+	case "bool":
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		if !v && !col.Nullable {
+			return b, nil
+		}
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v)), nil
+	case "string", "bytes":
+		var v []byte
+		switch {
+		case col.GoFieldType == "json.RawMessage":
+			v = raw
+		case col.ProtobufType == "bytes":
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, err
+			}
+		default:
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			v = []byte(s)
+		}
+		if len(v) == 0 && !col.Nullable {
+			return b, nil
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, v), nil
+	case "google.protobuf.Timestamp":
+		var t time.Time
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, err
+		}
+		if t.IsZero() && !col.Nullable {
+			return b, nil
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, marshalTimestamp(t)), nil
+	default:
+		return nil, fmt.Errorf("unsupported protobuf type %s", col.ProtobufType)
+	}
+}
+
+// UnmarshalRecord set the columns of record from its message, unknown fields are skipped and sensitive fields refused
+func UnmarshalRecord(message []byte, record model.Model) error {
+	columns := make(map[protowire.Number]*model.ColumnInfo)
+	for _, col := range record.TableInfo().Columns {
+		columns[protowire.Number(col.ProtobufPos)] = col
+	}
+
+	fields := make(map[string]interface{})
+	err := walkMessage(message, func(num protowire.Number, typ protowire.Type, value field) error {
+		col, ok := columns[num]
+		if !ok {
+			return nil
+		}
+		if col.IsSensitive {
+			return fmt.Errorf("column %s can not be written", col.JSONFieldName)
+		}
+
+		v, err := columnValue(col, typ, value)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", col.ProtobufFieldName, err)
+		}
+		fields[col.JSONFieldName] = v
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, record)
+}
+
+// columnValue the json value of a field of col
+func columnValue(col *model.ColumnInfo, typ protowire.Type, value field) (interface{}, error) {
+	expected := protowire.BytesType
+	switch col.ProtobufType {
+	case "int32", "int64", "bool":
+		expected = protowire.VarintType
+	case "float":
+		expected = protowire.Fixed32Type
+	case "double":
+		expected = protowire.Fixed64Type
+	}
+	if typ != expected {
+		return nil, fmt.Errorf("wire type %d, expected %d", typ, expected)
+	}
+
+	switch col.ProtobufType {
+	case "int32":
+		return int64(int32(value.n)), nil
+	case "int64":
+		return int64(value.n), nil
+	case "bool":
+		return protowire.DecodeBool(value.n), nil
+	case "float":
+		return math.Float32frombits(uint32(value.n)), nil
+	case "double":
+		return math.Float64frombits(value.n), nil
+	case "bytes":
+		return value.b, nil
+	case "string":
+		if col.GoFieldType == "json.RawMessage" {
+			if !json.Valid(value.b) {
+				return nil, fmt.Errorf("invalid json")
+			}
+			return json.RawMessage(value.b), nil
+		}
+		return string(value.b), nil
+	case "google.protobuf.Timestamp":
+		return unmarshalTimestamp(value.b)
+	default:
+		return nil, fmt.Errorf("unsupported protobuf type %s", col.ProtobufType)
+	}
+}
+
+// marshalTimestamp the google.protobuf.Timestamp message of t
+func marshalTimestamp(t time.Time) []byte {
+	var b []byte
+	if seconds := t.Unix(); seconds != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(seconds))
+	}
+	if nanos := t.Nanosecond(); nanos != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(nanos))
+	}
+	return b
+}
+
+// unmarshalTimestamp the time of a google.protobuf.Timestamp message, in UTC
+func unmarshalTimestamp(message []byte) (time.Time, error) {
+	var seconds, nanos int64
+	err := walkMessage(message, func(num protowire.Number, typ protowire.Type, value field) error {
+		if typ != protowire.VarintType {
+			return fmt.Errorf("malformed timestamp")
+		}
+		switch num {
+		case 1:
+			seconds = int64(value.n)
+		case 2:
+			nanos = int64(int32(value.n))
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if nanos < 0 || nanos >= int64(time.Second) {
+		return time.Time{}, fmt.Errorf("timestamp nanos out of range")
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
+
+// field the value of a field, n holds varint and fixed values and b length delimited ones
+type field struct {
+	n uint64
+	b []byte
+}
+
+// walkMessage call fn with each field of message in the order they are encoded, groups are refused
+func walkMessage(message []byte, fn func(num protowire.Number, typ protowire.Type, value field) error) error {
+	for len(message) > 0 {
+		num, typ, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		message = message[n:]
+
+		var value field
+		switch typ {
+		case protowire.VarintType:
+			value.n, n = protowire.ConsumeVarint(message)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(message)
+			value.n = uint64(v)
+		case protowire.Fixed64Type:
+			value.n, n = protowire.ConsumeFixed64(message)
+		case protowire.BytesType:
+			value.b, n = protowire.ConsumeBytes(message)
+		default:
+			return fmt.Errorf("unsupported wire type %d", typ)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		message = message[n:]
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KeyRequest the request of a Get, Update or Delete method, the primary key in field 1 and for Update the record in field 2
+type KeyRequest struct {
+	Key    string
+	Record []byte
+}
+
+// UnmarshalKeyRequest decode a request keyed by the primary key pk, integer keys are returned in decimal
+func UnmarshalKeyRequest(message []byte, pk *model.ColumnInfo) (*KeyRequest, error) {
+	request := &KeyRequest{}
+	err := walkMessage(message, func(num protowire.Number, typ protowire.Type, value field) error {
+		switch {
+		case num == 1 && pk.ProtobufType == "string" && typ == protowire.BytesType:
+			request.Key = string(value.b)
+		case num == 1 && pk.ProtobufType != "string" && typ == protowire.VarintType:
+			request.Key = strconv.FormatInt(int64(value.n), 10)
+		case num == 2 && typ == protowire.BytesType:
+			request.Record = value.b
+		case num == 1 || num == 2:
+			return fmt.Errorf("field %d has wire type %d", num, typ)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Key == "" && pk.ProtobufType != "string" {
+		request.Key = "0"
+	}
+	return request, nil
+}
+
+// ListRequest the request of a List or StreamList method, Page starts at 1 and 0 reads the first page
+type ListRequest struct {
+	Page     int64
+	PageSize int64
+	Order    string
+}
+
+// UnmarshalListRequest decode a request of a List or StreamList method
+func UnmarshalListRequest(message []byte) (*ListRequest, error) {
+	request := &ListRequest{}
+	err := walkMessage(message, func(num protowire.Number, typ protowire.Type, value field) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			request.Page = int64(value.n)
+		case num == 2 && typ == protowire.VarintType:
+			request.PageSize = int64(value.n)
+		case num == 3 && typ == protowire.BytesType:
+			request.Order = string(value.b)
+		case num >= 1 && num <= 3:
+			return fmt.Errorf("field %d has wire type %d", num, typ)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// MarshalListResponse the response of a List method, records are the encoded messages of the page
+func MarshalListResponse(page, pageSize int64, totalRecords int, records [][]byte) []byte {
+	var b []byte
+	for num, n := range []int64{page, pageSize, int64(totalRecords)} {
+		if n != 0 {
+			b = protowire.AppendTag(b, protowire.Number(num+1), protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(n))
+		}
+	}
+
+	for _, record := range records {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, record)
+	}
+	return b
+}
+
+// MarshalDeleteResponse the response of a Delete method
+func MarshalDeleteResponse(rowsAffected int64) []byte {
+	if rowsAffected == 0 {
+		return []byte{}
+	}
+	b := protowire.AppendTag(nil, 1, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(rowsAffected))
+}
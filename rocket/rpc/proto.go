@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"rocket/model"
+)
+
+// ServiceName the service of the records of a table, ie BatteryService
+func ServiceName(table string) string {
+	return model.TypeName(table) + "Service"
+}
+
+// PrimaryKey the primary key column of a table, the key of its Get, Update and Delete requests
+func PrimaryKey(info *model.TableInfo) *model.ColumnInfo {
+	for _, col := range info.Columns {
+		if col.IsPrimaryKey {
+			return col
+		}
+	}
+	return nil
+}
+
+// ProtoFile the .proto definitions of the services of tables, one message per table with a field per column at its protobuf
+// position and a crud service. Nullable columns are optional fields, sensitive columns are reserved.
+func ProtoFile(tables []string) string {
+	tables = append([]string(nil), tables...)
+	sort.Strings(tables)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by app/protogen from the column metadata of the models. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", Package)
+	b.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+	b.WriteString("message ListRequest {\n  // page to read, starting at 1, 0 reads the first page\n  int64 page = 1;\n  // records per page, 20 when 0\n  int64 page_size = 2;\n  string order = 3;\n}\n\n")
+	b.WriteString("message DeleteResponse {\n  int64 rows_affected = 1;\n}\n")
+
+	for _, table := range tables {
+		info, ok := model.GetTableInfo(table)
+		if !ok {
+			continue
+		}
+		pk := PrimaryKey(info)
+		if pk == nil {
+			continue
+		}
+
+		name := model.TypeName(table)
+		fmt.Fprintf(&b, "\n// %s a record of %s\nmessage %s {\n", name, table, name)
+		for _, col := range info.Columns {
+			if col.IsSensitive {
+				fmt.Fprintf(&b, "  reserved %d;\n  reserved \"%s\";\n", col.ProtobufPos, col.ProtobufFieldName)
+				continue
+			}
+
+			optional := ""
+			if col.Nullable {
+				optional = "optional "
+			}
+			fmt.Fprintf(&b, "  %s%s %s = %d;\n", optional, col.ProtobufType, col.ProtobufFieldName, col.ProtobufPos)
+		}
+		b.WriteString("}\n")
+
+		key := fmt.Sprintf("  %s %s = 1;\n", pk.ProtobufType, pk.ProtobufFieldName)
+		fmt.Fprintf(&b, "\nmessage Get%sRequest {\n%s}\n", name, key)
+		fmt.Fprintf(&b, "\nmessage List%sResponse {\n  int64 page = 1;\n  int64 page_size = 2;\n  int64 total_records = 3;\n  repeated %s data = 4;\n}\n", name, name)
+		fmt.Fprintf(&b, "\nmessage Update%sRequest {\n%s  %s record = 2;\n}\n", name, key, name)
+		fmt.Fprintf(&b, "\nmessage Delete%sRequest {\n%s}\n", name, key)
+
+		fmt.Fprintf(&b, "\nservice %s {\n", ServiceName(table))
+		fmt.Fprintf(&b, "  rpc Get(Get%sRequest) returns (%s);\n", name, name)
+		fmt.Fprintf(&b, "  rpc List(ListRequest) returns (List%sResponse);\n", name)
+		fmt.Fprintf(&b, "  // StreamList sends the records in order, page and page_size are ignored\n")
+		fmt.Fprintf(&b, "  rpc StreamList(ListRequest) returns (stream %s);\n", name)
+		fmt.Fprintf(&b, "  rpc Create(%s) returns (%s);\n", name, name)
+		fmt.Fprintf(&b, "  rpc Update(Update%sRequest) returns (%s);\n", name, name)
+		fmt.Fprintf(&b, "  rpc Delete(Delete%sRequest) returns (DeleteResponse);\n", name)
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,226 @@
+// Package rpc serves grpc calls over http/2, the methods of the services are registered as handlers reading the request message
+// and sending response messages already encoded in the protobuf wire format
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxMessageSize largest request message accepted, as the default of grpc servers
+	MaxMessageSize = 4 << 20
+
+	// Package protobuf package of the generated services
+	Package = "rocket.v1"
+)
+
+// Call a call of a method, Message is the encoded request message and Send writes an encoded response message, unary methods
+// call Send once and server streaming methods once per message
+type Call struct {
+	Request *http.Request
+	Message []byte
+	Send    func(message []byte) error
+}
+
+// Context the context of the call, it is done when the client cancels the call or its grpc-timeout expires
+func (c *Call) Context() context.Context {
+	return c.Request.Context()
+}
+
+// Handler a method of a service, an error that is not a *Status is reported as Unknown
+type Handler func(call *Call) error
+
+// Server an http.Handler dispatching the calls of grpc clients to the handlers of their method, it expects http/2 requests so
+// is served over tls or wrapped with h2c for cleartext connections
+type Server struct {
+	mu      sync.RWMutex
+	methods map[string]Handler
+}
+
+// NewServer a server without methods
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Handler)}
+}
+
+// Handle register h as method of service, service is its name without the package, ie BatteryService
+func (s *Server) Handle(service, method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods["/"+Package+"."+service+"/"+method] = h
+}
+
+// Methods the paths of the registered methods, ie /rocket.v1.BatteryService/Get
+func (s *Server) Methods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	paths := make([]string, 0, len(s.methods))
+	for path := range s.methods {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "grpc calls must be sent with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") {
+		http.Error(w, "content type must be application/grpc", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	status := StatusOf(s.serve(w, r))
+	code, message := status.trailers()
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", code)
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", message)
+	}
+}
+
+// serve read the request message of the call and run the handler of its method
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) error {
+	s.mu.RLock()
+	h, ok := s.methods[r.URL.Path]
+	s.mu.RUnlock()
+	if !ok {
+		return Errorf(Unimplemented, "unknown method %s", r.URL.Path)
+	}
+
+	if encoding := r.Header.Get("Grpc-Encoding"); encoding != "" && encoding != "identity" {
+		return Errorf(Unimplemented, "compression %s is not supported", encoding)
+	}
+
+	if timeout := r.Header.Get("Grpc-Timeout"); timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return Errorf(InvalidArgument, "malformed grpc-timeout %s", timeout)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	message, err := readMessage(r.Body)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	call := &Call{Request: r, Message: message}
+	call.Send = func(message []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := contextStatus(r.Context()); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(frame(message)); err != nil {
+			return Errorf(Canceled, "client went away")
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	if err := h(call); err != nil {
+		if ctxErr := contextStatus(r.Context()); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	return nil
+}
+
+// contextStatus the status of a call whose context is done, nil while it is not
+func contextStatus(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return Errorf(DeadlineExceeded, "grpc-timeout expired")
+	default:
+		return Errorf(Canceled, "call canceled")
+	}
+}
+
+// readMessage read the single length prefixed message of a unary or server streaming call
+func readMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, Errorf(InvalidArgument, "missing request message")
+	}
+
+	if prefix[0] != 0 {
+		return nil, Errorf(Unimplemented, "compressed messages are not supported")
+	}
+
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > MaxMessageSize {
+		return nil, Errorf(ResourceExhausted, "request message of %d bytes is larger than %d", size, MaxMessageSize)
+	}
+
+	message := make([]byte, size)
+	if _, err := io.ReadFull(body, message); err != nil {
+		return nil, Errorf(InvalidArgument, "truncated request message")
+	}
+
+	if n, _ := body.Read(prefix[:1]); n != 0 {
+		return nil, Errorf(Unimplemented, "client streaming is not supported")
+	}
+	return message, nil
+}
+
+// frame the length prefixed encoding of an uncompressed message
+func frame(message []byte) []byte {
+	buf := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(message)))
+	copy(buf[5:], message)
+	return buf
+}
+
+var timeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseTimeout parse a grpc-timeout header, at most 8 digits followed by a unit, ie 100m
+func parseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, errors.New("malformed timeout")
+	}
+
+	unit, ok := timeoutUnits[s[len(s)-1]]
+	if !ok {
+		return 0, errors.New("unknown timeout unit")
+	}
+
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("malformed timeout")
+	}
+	return time.Duration(n) * unit, nil
+}
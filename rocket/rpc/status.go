@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Code a grpc status code
+type Code uint32
+
+const (
+	// OK the call succeeded
+	OK Code = 0
+
+	// Canceled the call was canceled by the client
+	Canceled Code = 1
+
+	// Unknown an error that maps to no other code
+	Unknown Code = 2
+
+	// InvalidArgument the request message is malformed or fails validation
+	InvalidArgument Code = 3
+
+	// DeadlineExceeded the grpc-timeout of the call expired
+	DeadlineExceeded Code = 4
+
+	// NotFound the record does not exist
+	NotFound Code = 5
+
+	// PermissionDenied the caller may not perform the call
+	PermissionDenied Code = 7
+
+	// ResourceExhausted the request message is too large or the caller exceeded its rate limit
+	ResourceExhausted Code = 8
+
+	// Unimplemented the service or method does not exist
+	Unimplemented Code = 12
+
+	// Internal the db failed to perform the call
+	Internal Code = 13
+
+	// Unavailable the server is shutting down
+	Unavailable Code = 14
+
+	// Unauthenticated the call carries no valid credentials
+	Unauthenticated Code = 16
+)
+
+// Status the outcome of a call, sent in the grpc-status and grpc-message trailers
+type Status struct {
+	Code    Code
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", s.Code, s.Message)
+}
+
+// Errorf a status with code and a formatted message
+func Errorf(code Code, format string, args ...interface{}) *Status {
+	return &Status{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// StatusOf the status of the error returned by a handler, errors that are not a *Status are Unknown
+func StatusOf(err error) *Status {
+	if err == nil {
+		return &Status{Code: OK}
+	}
+	if status, ok := err.(*Status); ok {
+		return status
+	}
+	return &Status{Code: Unknown, Message: err.Error()}
+}
+
+// trailers the grpc-status and grpc-message trailer values of s, bytes of the message that are not printable ascii are percent encoded
+func (s *Status) trailers() (string, string) {
+	var b strings.Builder
+	for i := 0; i < len(s.Message); i++ {
+		if c := s.Message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return strconv.FormatUint(uint64(s.Code), 10), b.String()
+}
@@ -0,0 +1,58 @@
+// Package sqlscript splits sql scripts, ie migrations and blazer queries, into their statements
+package sqlscript
+
+import "strings"
+
+// Split split sql into its statements at the semicolons outside of quotes and comments, comments and empty statements are
+// dropped
+func Split(sql string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte
+	)
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(sql) {
+				i++
+				current.WriteByte(sql[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteByte(c)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
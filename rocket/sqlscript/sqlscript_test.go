@@ -0,0 +1,27 @@
+package sqlscript
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"", nil},
+		{" ;; ", nil},
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"SELECT 1;\nSELECT 2;\n", []string{"SELECT 1", "SELECT 2"}},
+		{"a;; -- x;\n b 'c;d' \"e;\" `f;`; # g;\n/* h; */ i", []string{"a", "b 'c;d' \"e;\" `f;`", "i"}},
+		{`SELECT 'it\'s;' FROM t; SELECT 2`, []string{`SELECT 'it\'s;' FROM t`, "SELECT 2"}},
+		{"SELECT 1 /* unterminated; comment", []string{"SELECT 1"}},
+	}
+
+	for _, tt := range tests {
+		if got := Split(tt.sql); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
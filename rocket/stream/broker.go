@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rocket/dao"
+	"rocket/model"
+)
+
+// ignoredTables tables whose changes are not streamed, bookkeeping written as a side effect of other changes
+var ignoredTables = map[string]bool{
+	"audit_logs":         true,
+	"webhook_deliveries": true,
+}
+
+// Event a create, update or delete as sent to stream subscribers
+type Event struct {
+	// ID position of the event in the stream, "<epoch>-<sequence>", sent as the SSE id
+	ID string `json:"id"`
+
+	Table      string                      `json:"table"`
+	Action     string                      `json:"action"`
+	RecordID   string                      `json:"record_id"`
+	OccurredAt time.Time                   `json:"occurred_at"`
+	Record     interface{}                 `json:"record"`
+	Changes    map[string]*dao.FieldChange `json:"changes,omitempty"`
+
+	// Data json encoding of the event, the SSE data
+	Data []byte `json:"-"`
+
+	// Source the changed record before redaction, used for row level authorization of subscribers
+	Source interface{} `json:"-"`
+
+	seq uint64
+}
+
+// Subscription receives the events published after it was created, C is closed when the subscriber falls too far behind or is cancelled
+type Subscription struct {
+	C <-chan *Event
+
+	c      chan *Event
+	broker *Broker
+	once   sync.Once
+}
+
+// Broker keeps the last Size events in a ring buffer and fans new events out to subscribers,
+// clients resume after a reconnect from the buffer with the id of the last event they received
+type Broker struct {
+	// Size number of events kept for resuming, older events are dropped
+	Size int
+
+	// Backlog number of events queued per subscriber before it is disconnected
+	Backlog int
+
+	// Now returns the current time, replaceable for deterministic runs
+	Now func() time.Time
+
+	epoch       string
+	seq         uint64
+	buffer      []*Event
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	mu          sync.Mutex
+}
+
+// NewBroker create a Broker keeping size events, ids are prefixed with the start time so ids of a previous run are detected
+func NewBroker(size int) *Broker {
+	return &Broker{
+		Size:        size,
+		Backlog:     256,
+		Now:         time.Now,
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// RecordChange dao.ChangeRecorderFunc publishing every create, update and delete
+func (b *Broker) RecordChange(ctx context.Context, table string, action model.Action, before, after interface{}) {
+	if ignoredTables[table] {
+		return
+	}
+
+	record := after
+	if record == nil {
+		record = before
+	}
+
+	changes := dao.RecordChanges(table, before, after)
+	if _, touched := changes["updated_at"]; action == model.Update && (len(changes) == 0 || (touched && len(changes) == 1)) {
+		return
+	}
+
+	event := &Event{
+		Table:      table,
+		Action:     action.String(),
+		RecordID:   dao.PrimaryKey(table, record),
+		OccurredAt: b.Now().UTC(),
+		Record:     record,
+		Source:     record,
+	}
+
+	if m, ok := record.(model.Model); ok {
+		event.Record = model.Redact(m)
+	}
+
+	if action == model.Update {
+		event.Changes = changes
+	}
+
+	b.Publish(event)
+}
+
+// Publish assign the next id to event, keep it in the buffer and send it to every subscriber
+func (b *Broker) Publish(event *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.seq = b.seq
+	event.ID = fmt.Sprintf("%s-%d", b.epoch, b.seq)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("events: encoding %s %s %s failed, the error is '%v'", event.Action, event.Table, event.RecordID, err)
+		return
+	}
+	event.Data = data
+
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > b.Size {
+		b.buffer = b.buffer[len(b.buffer)-b.Size:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.c <- event:
+		default:
+			// a slow client is dropped rather than holding up every other subscriber, it resumes with Last-Event-ID
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe start receiving events, with a lastEventID the buffered events published after it are returned first.
+// resumed is false when the events after lastEventID are no longer buffered or lastEventID belongs to a previous run,
+// the client missed events and must reload its state.
+func (b *Broker) Subscribe(lastEventID string) (sub *Subscription, missed []*Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan *Event, b.Backlog)
+	sub = &Subscription{C: c, c: c, broker: b}
+	b.subscribers[sub] = struct{}{}
+
+	if b.closed {
+		b.remove(sub)
+		return sub, nil, true
+	}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	seq, ok := b.parseID(lastEventID)
+	if !ok || seq > b.seq {
+		return sub, nil, false
+	}
+
+	if seq < b.seq && (len(b.buffer) == 0 || b.buffer[0].seq > seq+1) {
+		// events between lastEventID and the oldest buffered event were dropped
+		return sub, nil, false
+	}
+
+	for _, event := range b.buffer {
+		if event.seq > seq {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed, true
+}
+
+// Close disconnect every subscriber, ie when the server shuts down, subscriptions made afterwards are closed right away
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// Close stop the subscription, C is closed
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(b.subscribers, sub)
+		close(sub.c)
+	})
+}
+
+func (b *Broker) parseID(id string) (uint64, bool) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 || id[:i] != b.epoch {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	return seq, err == nil
+}
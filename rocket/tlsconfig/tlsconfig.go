@@ -0,0 +1,217 @@
+// Package tlsconfig serves a certificate and client authorities loaded from pem files, reloading them when the files change
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config the pem files of a Reloader
+type Config struct {
+	// CertFile certificate chain of the server, leaf first
+	CertFile string
+
+	// KeyFile private key of the certificate
+	KeyFile string
+
+	// ClientCAFile authorities client certificates are verified with, empty disables client certificates
+	ClientCAFile string
+
+	// RequireClientCert reject clients without a valid certificate, otherwise a certificate is only verified when presented
+	RequireClientCert bool
+}
+
+// Reloader serves the certificate and client authorities loaded last, Reload loads them again when a file changed so renewed
+// certificates are served without a restart
+type Reloader struct {
+	config Config
+
+	mu      sync.RWMutex
+	current *tls.Config
+	stamps  map[string]fileStamp
+}
+
+// fileStamp modification time and size of a file, a changed stamp triggers a reload
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New create a Reloader with the files of config loaded, an error is returned when they cannot be loaded
+func New(config Config) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("a certificate and a key file are required")
+	}
+
+	if config.RequireClientCert && config.ClientCAFile == "" {
+		return nil, errors.New("requiring client certificates needs a client ca file")
+	}
+
+	r := &Reloader{config: config}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig config for http.Server.TLSConfig, every handshake uses the certificate and authorities loaded last
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.current, nil
+		},
+	}
+}
+
+// Reload load the files again when one of them changed since they were loaded, on error the files loaded before are kept
+func (r *Reloader) Reload() (changed bool, err error) {
+	stamps, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	changed = !sameStamps(r.stamps, stamps)
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	current, err := r.load()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.current, r.stamps = current, stamps
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Run check the files for changes every interval until ctx is cancelled
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := r.Reload()
+		if err != nil {
+			log.Printf("tls: reloading %s failed, the certificate loaded before is kept, the error is '%v'", r.config.CertFile, err)
+		} else if changed {
+			log.Printf("tls: reloaded %s", r.config.CertFile)
+		}
+	}
+}
+
+// load read the files into the config served to clients
+func (r *Reloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"h2", "http/1.1"},
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.config.ClientCAFile == "" {
+		return config, nil
+	}
+
+	pem, err := ioutil.ReadFile(r.config.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", r.config.ClientCAFile)
+	}
+
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if r.config.RequireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// stat the stamps of the files
+func (r *Reloader) stat() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, 3)
+	for _, name := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		stamps[name] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, stamp := range a {
+		if other, ok := b[name]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// RedirectHandler redirect every request to the same host and uri over https on httpsPort, the port is left out when it is
+// empty or 443
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
@@ -0,0 +1,330 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// pair a certificate and its private key, pem encoded as Reloader reads them
+type pair struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newPair generate a certificate for localhost named cn, self signed when issuer is nil. Self signed certificates are authorities
+// so they can sign client certificates.
+func newPair(t *testing.T, cn string, issuer *pair) *pair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  issuer == nil,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &pair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// tlsCertificate the pair as presented by a client
+func (p *pair) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(p.certPEM, p.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFile write data to name with a modification time later than any write before, so Reload sees the change even when the
+// file system keeps coarse times
+func writeFile(t *testing.T, name string, data []byte) {
+	t.Helper()
+	if err := ioutil.WriteFile(name, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stamp := time.Now().Add(time.Duration(len(data)) * time.Second)
+	if info, err := os.Stat(name); err == nil && !info.ModTime().Before(stamp) {
+		stamp = info.ModTime().Add(time.Second)
+	}
+	if err := os.Chtimes(name, stamp, stamp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tempDir a directory removed when the test ends
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// serve an https server answering 200 with the config of r, the address is returned. Rejected handshakes are not logged.
+func serve(t *testing.T, r *Reloader) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }),
+		TLSConfig: r.TLSConfig(),
+		ErrorLog:  log.New(ioutil.Discard, "", 0),
+	}
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+// get request addr over a new connection trusting roots, the certificate served is returned. A given client certificate is
+// presented even when the server does not list its issuer, so the server is the one rejecting it.
+func get(addr string, roots []*x509.Certificate, certs ...tls.Certificate) (*x509.Certificate, error) {
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root)
+	}
+
+	config := &tls.Config{RootCAs: pool, ServerName: "localhost"}
+	if len(certs) > 0 {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return &certs[0], nil }
+	}
+
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true},
+	}
+
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if _, err := ioutil.ReadAll(resp.Body); err != nil {
+		return nil, err
+	}
+	return resp.TLS.PeerCertificates[0], nil
+}
+
+func TestNew(t *testing.T) {
+	dir := tempDir(t)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	server := newPair(t, "server", nil)
+	writeFile(t, certFile, server.certPEM)
+	writeFile(t, keyFile, server.keyPEM)
+
+	r, err := New(Config{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("New with a self signed pair returned %v", err)
+	}
+
+	got, err := get(serve(t, r), []*x509.Certificate{server.cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject.CommonName != "server" {
+		t.Errorf("served certificate %q, want server", got.Subject.CommonName)
+	}
+
+	other := newPair(t, "other", nil)
+	otherKey := filepath.Join(dir, "other.key")
+	writeFile(t, otherKey, other.keyPEM)
+
+	for name, config := range map[string]Config{
+		"no key":                          {CertFile: certFile},
+		"missing file":                    {CertFile: certFile, KeyFile: filepath.Join(dir, "missing.pem")},
+		"key of another certificate":      {CertFile: certFile, KeyFile: otherKey},
+		"client ca without certificates":  {CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile},
+		"required client cert without ca": {CertFile: certFile, KeyFile: keyFile, RequireClientCert: true},
+	} {
+		if _, err := New(config); err == nil {
+			t.Errorf("New with %s returned no error", name)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := tempDir(t)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	first := newPair(t, "first", nil)
+	writeFile(t, certFile, first.certPEM)
+	writeFile(t, keyFile, first.keyPEM)
+
+	r, err := New(Config{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := serve(t, r)
+
+	if changed, err := r.Reload(); changed || err != nil {
+		t.Errorf("Reload of unchanged files = %v, %v, want false, nil", changed, err)
+	}
+
+	served := func(want string, roots ...*x509.Certificate) {
+		t.Helper()
+		got, err := get(addr, roots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Subject.CommonName != want {
+			t.Errorf("served certificate %q, want %q", got.Subject.CommonName, want)
+		}
+	}
+
+	// a renewed certificate is served once reloaded
+	second := newPair(t, "second", nil)
+	writeFile(t, certFile, second.certPEM)
+	writeFile(t, keyFile, second.keyPEM)
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Fatalf("Reload of rewritten files = %v, %v, want true, nil", changed, err)
+	}
+	served("second", second.cert)
+
+	// a certificate that does not load keeps the one loaded before
+	writeFile(t, certFile, []byte("-----BEGIN CERTIFICATE-----\nbroken\n-----END CERTIFICATE-----\n"))
+	if changed, err := r.Reload(); changed || err == nil {
+		t.Fatalf("Reload of a broken certificate = %v, %v, want false and an error", changed, err)
+	}
+	served("second", second.cert)
+
+	// so does a certificate written before its key
+	third := newPair(t, "third", nil)
+	writeFile(t, certFile, third.certPEM)
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Reload of a certificate with the key of another returned no error")
+	}
+	served("second", second.cert)
+
+	writeFile(t, keyFile, third.keyPEM)
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Fatalf("Reload once the key was written = %v, %v, want true, nil", changed, err)
+	}
+	served("third", third.cert)
+
+	// a removed file keeps the certificate loaded before
+	os.Remove(keyFile)
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Reload without a key file returned no error")
+	}
+	served("third", third.cert)
+}
+
+func TestRequireClientCert(t *testing.T) {
+	dir := tempDir(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem")
+	server := newPair(t, "server", nil)
+	ca := newPair(t, "clients", nil)
+	writeFile(t, certFile, server.certPEM)
+	writeFile(t, keyFile, server.keyPEM)
+	writeFile(t, caFile, ca.certPEM)
+
+	client := newPair(t, "gateway", ca)
+	stranger := newPair(t, "stranger", newPair(t, "other ca", nil))
+
+	for _, require := range []bool{true, false} {
+		r, err := New(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: require})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := serve(t, r)
+		roots := []*x509.Certificate{server.cert}
+
+		if _, err := get(addr, roots, client.tlsCertificate(t)); err != nil {
+			t.Errorf("require %v: a client with a certificate of the ca was rejected, %v", require, err)
+		}
+		if _, err := get(addr, roots, stranger.tlsCertificate(t)); err == nil {
+			t.Errorf("require %v: a client with a certificate of another ca was accepted", require)
+		}
+
+		_, err = get(addr, roots)
+		if require && err == nil {
+			t.Error("a client without a certificate was accepted")
+		}
+		if !require && err != nil {
+			t.Errorf("a client without a certificate was rejected though certificates are optional, %v", err)
+		}
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		method, host, uri, port string
+		want                    string
+		code                    int
+	}{
+		{"GET", "example.com", "/leads?page=2", "443", "https://example.com/leads?page=2", http.StatusMovedPermanently},
+		{"GET", "example.com:8080", "/", "", "https://example.com/", http.StatusMovedPermanently},
+		{"HEAD", "example.com:8080", "/a%20b", "8443", "https://example.com:8443/a%20b", http.StatusMovedPermanently},
+		{"POST", "example.com", "/auth/login", "8443", "https://example.com:8443/auth/login", http.StatusPermanentRedirect},
+		{"GET", "10.0.0.1:80", "/", "8443", "https://10.0.0.1:8443/", http.StatusMovedPermanently},
+		{"GET", "[2001:db8::1]:8080", "/x", "8443", "https://[2001:db8::1]:8443/x", http.StatusMovedPermanently},
+		{"GET", "[2001:db8::1]:8080", "/x", "443", "https://[2001:db8::1]/x", http.StatusMovedPermanently},
+		{"PUT", "[::1]", "/x", "", "https://[::1]/x", http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.uri, nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		RedirectHandler(tt.port).ServeHTTP(w, req)
+
+		if w.Code != tt.code || w.Header().Get("Location") != tt.want {
+			t.Errorf("%s %s%s to port %q redirected %d to %q, want %d to %q", tt.method, tt.host, tt.uri, tt.port,
+				w.Code, w.Header().Get("Location"), tt.code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rocket/dao"
+	"rocket/logging"
+	"rocket/model"
+
+	"github.com/guregu/null"
+)
+
+// maxErrorLength longest last_error stored for a failed attempt, response bodies can be large
+const maxErrorLength = 1024
+
+// ignoredTables tables whose changes are never sent, the webhook tables themselves, the audit log and the api keys
+var ignoredTables = map[string]bool{
+	"api_keys":              true,
+	"audit_logs":            true,
+	"webhook_deliveries":    true,
+	"webhook_subscriptions": true,
+}
+
+// Event body POSTed to subscribers
+type Event struct {
+	Event      string                      `json:"event" example:"interventions.update"`
+	Table      string                      `json:"table" example:"interventions"`
+	Action     string                      `json:"action" example:"Update"`
+	RecordID   string                      `json:"record_id" example:"12"`
+	OccurredAt time.Time                   `json:"occurred_at"`
+	Actor      *dao.Actor                  `json:"actor,omitempty"`
+	Record     interface{}                 `json:"record"`
+	Changes    map[string]*dao.FieldChange `json:"changes,omitempty"`
+}
+
+// Dispatcher queues a webhook_deliveries row per matching subscription when a record changes and POSTs them in the background,
+// failed attempts are retried with exponential backoff until MaxAttempts is reached
+type Dispatcher struct {
+	// Client used to POST events, its timeout bounds a single attempt
+	Client *http.Client
+
+	// Interval how often webhook_deliveries is scanned for due deliveries, new changes are sent without waiting for it
+	Interval time.Duration
+
+	// MaxAttempts number of attempts after which a delivery is marked failed
+	MaxAttempts int64
+
+	// BaseBackoff delay before the first retry, doubled for every further attempt up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// BatchSize maximum number of deliveries sent per scan
+	BatchSize int
+
+	// Now returns the current time, replaceable for deterministic runs
+	Now func() time.Time
+
+	wake chan struct{}
+	mu   sync.Mutex
+}
+
+// NewDispatcher create a Dispatcher scanning every 5 seconds and retrying 8 times, from 30 seconds up to 6 hours apart
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Interval:    5 * time.Second,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		BatchSize:   100,
+		Now:         time.Now,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Backoff delay before the attempt following attempt number attempt, base doubled per attempt and capped at max
+func Backoff(base, max time.Duration, attempt int64) time.Duration {
+	delay := base
+	for i := int64(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= max || delay <= 0 {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// RecordChange dao.ChangeRecorderFunc queueing a delivery of the change for every active subscription of table and action.
+// Failures are logged and do not undo the change.
+func (d *Dispatcher) RecordChange(ctx context.Context, table string, action model.Action, before, after interface{}) {
+	if ignoredTables[table] {
+		return
+	}
+
+	subscriptions, err := dao.GetActiveWebhookSubscriptions(ctx, table)
+	if err != nil {
+		logging.Error(ctx, "webhooks: subscriptions failed", logging.Fields{"table": table, "error": err})
+		return
+	}
+
+	var matching []*model.WebhookSubscriptions
+	for _, subscription := range subscriptions {
+		if Subscribed(subscription.Actions, action) {
+			matching = append(matching, subscription)
+		}
+	}
+
+	if len(matching) == 0 {
+		return
+	}
+
+	event := d.newEvent(ctx, table, action, before, after)
+	if event == nil {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logging.Error(ctx, "webhooks: encoding failed", logging.Fields{"event": event.Event, "record_id": event.RecordID, "error": err})
+		return
+	}
+
+	deliveries := make([]*model.WebhookDeliveries, 0, len(matching))
+	for _, subscription := range matching {
+		deliveries = append(deliveries, &model.WebhookDeliveries{
+			SubscriptionID: subscription.ID,
+			Event:          event.Event,
+			TableName_:     table,
+			RecordID:       event.RecordID,
+			Payload:        payload,
+			Status:         dao.WebhookPending,
+			NextAttemptAt:  null.TimeFrom(event.OccurredAt),
+		})
+	}
+
+	if err = dao.AddWebhookDeliveries(ctx, deliveries); err != nil {
+		logging.Error(ctx, "webhooks: queueing failed", logging.Fields{"event": event.Event, "record_id": event.RecordID, "error": err})
+		return
+	}
+
+	d.Wake()
+}
+
+// Subscribed reports if a comma separated webhook_subscriptions.actions list, ie "Create,Update" or "*", includes action
+func Subscribed(actions string, action model.Action) bool {
+	for _, name := range strings.Split(actions, ",") {
+		name = strings.TrimSpace(name)
+		if name == "*" || strings.EqualFold(name, action.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// newEvent the event of a change, nil for updates that changed nothing but updated_at
+func (d *Dispatcher) newEvent(ctx context.Context, table string, action model.Action, before, after interface{}) *Event {
+	record := after
+	if record == nil {
+		record = before
+	}
+
+	changes := dao.RecordChanges(table, before, after)
+	if _, touched := changes["updated_at"]; action == model.Update && (len(changes) == 0 || (touched && len(changes) == 1)) {
+		return nil
+	}
+
+	event := &Event{
+		Event:      table + "." + strings.ToLower(action.String()),
+		Table:      table,
+		Action:     action.String(),
+		RecordID:   dao.PrimaryKey(table, record),
+		OccurredAt: d.Now().UTC(),
+		Actor:      dao.ActorFromContext(ctx),
+		Record:     record,
+	}
+
+	if m, ok := record.(model.Model); ok {
+		event.Record = model.Redact(m)
+	}
+
+	if action == model.Update {
+		event.Changes = changes
+	}
+	return event
+}
+
+// Wake start sending queued deliveries without waiting for the next scan
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries every Interval, or sooner when woken, until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	// a slow scan must not overlap with the next tick
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		deliveries, err := dao.GetDueWebhookDeliveries(ctx, d.Now(), d.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err := d.Deliver(ctx, delivery); err != nil {
+				log.Printf("webhook delivery %d: %v", delivery.ID, err)
+			}
+		}
+
+		if len(deliveries) < d.BatchSize {
+			return nil
+		}
+	}
+}
+
+// Deliver makes one attempt at POSTing a delivery to its subscription and stores the outcome,
+// the returned error is only set when the outcome could not be stored
+func (d *Dispatcher) Deliver(ctx context.Context, delivery *model.WebhookDeliveries) error {
+	now := d.Now()
+	delivery.Attempts++
+
+	subscription, err := dao.GetWebhookSubscriptions(ctx, delivery.SubscriptionID)
+	switch {
+	case err != nil:
+		d.fail(delivery, fmt.Sprintf("subscription %d not found", delivery.SubscriptionID))
+	case !subscription.Active:
+		d.fail(delivery, fmt.Sprintf("subscription %d is not active", delivery.SubscriptionID))
+	default:
+		code, err := d.post(ctx, subscription, delivery, now)
+		if code != 0 {
+			delivery.LastStatusCode = null.IntFrom(int64(code))
+		}
+
+		switch {
+		case err == nil:
+			delivery.Status = dao.WebhookDelivered
+			delivery.DeliveredAt = null.TimeFrom(now)
+			delivery.NextAttemptAt = null.Time{}
+			delivery.LastError = null.String{}
+		case delivery.Attempts >= d.MaxAttempts:
+			d.fail(delivery, err.Error())
+		default:
+			delivery.LastError = null.StringFrom(truncate(err.Error()))
+			delivery.NextAttemptAt = null.TimeFrom(now.Add(Backoff(d.BaseBackoff, d.MaxBackoff, delivery.Attempts)))
+		}
+	}
+
+	return dao.SaveWebhookDeliveryAttempt(ctx, delivery)
+}
+
+func (d *Dispatcher) fail(delivery *model.WebhookDeliveries, reason string) {
+	delivery.Status = dao.WebhookFailed
+	delivery.NextAttemptAt = null.Time{}
+	delivery.LastError = null.StringFrom(truncate(reason))
+}
+
+// post send the payload of delivery signed with the subscription secret, any non 2xx response is reported as an error
+func (d *Dispatcher) post(ctx context.Context, subscription *model.WebhookSubscriptions, delivery *model.WebhookDeliveries, now time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.TargetURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rocket-webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, now, delivery.Payload))
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post to %s failed: %v", subscription.TargetURL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("post to %s returned status %d", subscription.TargetURL, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
@@ -0,0 +1,241 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"rocket/dao"
+	"rocket/dao/daotest"
+	"rocket/model"
+
+	"github.com/guregu/null"
+)
+
+// attempt a request received by a receiverStandIn
+type attempt struct {
+	delivery string
+	event    string
+	verified bool
+}
+
+// receiverStandIn a local http server answering every POST with the status returned by status, the received requests are sent to the
+// returned channel with the outcome of verifying their signature against secret
+func receiverStandIn(t *testing.T, secret string, status func() int) (string, <-chan attempt) {
+	attempts := make(chan attempt, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		code := status()
+		attempts <- attempt{
+			delivery: r.Header.Get(HeaderDelivery),
+			event:    r.Header.Get(HeaderEvent),
+			verified: Verify(secret, r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)),
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, attempts
+}
+
+// openTestDB point dao.DB at a sqlite database holding the webhook tables, with a subscription posting to targetURL
+func openTestDB(t *testing.T, targetURL, secret string) *model.WebhookSubscriptions {
+	db := daotest.Open(t,
+		"CREATE TABLE webhook_subscriptions (id integer primary key autoincrement, table_name varchar(255), actions varchar(255) default '*', target_url varchar(2048), secret varchar(255), active tinyint, description varchar(255), created_at datetime, updated_at datetime)",
+		"CREATE TABLE webhook_deliveries (id integer primary key autoincrement, subscription_id bigint, event varchar(255), table_name varchar(255), record_id varchar(255), payload text, status varchar(16), attempts bigint default 0, next_attempt_at datetime, last_status_code bigint, last_error text, delivered_at datetime, replay_of bigint, created_at datetime, updated_at datetime)",
+	)
+
+	subscription := &model.WebhookSubscriptions{TableName_: "interventions", Actions: "*", TargetURL: targetURL, Secret: secret, Active: true}
+	if err := db.Create(subscription).Error; err != nil {
+		t.Fatal(err)
+	}
+	return subscription
+}
+
+// queue store a pending delivery of subscription due at now
+func queue(t *testing.T, subscription *model.WebhookSubscriptions, now time.Time) *model.WebhookDeliveries {
+	delivery := &model.WebhookDeliveries{
+		SubscriptionID: subscription.ID,
+		Event:          "interventions.update",
+		TableName_:     "interventions",
+		RecordID:       "12",
+		Payload:        []byte(`{"event":"interventions.update","record_id":"12"}`),
+		Status:         dao.WebhookPending,
+		NextAttemptAt:  null.TimeFrom(now),
+	}
+	if err := dao.AddWebhookDeliveries(context.Background(), []*model.WebhookDeliveries{delivery}); err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func reload(t *testing.T, delivery *model.WebhookDeliveries) *model.WebhookDeliveries {
+	t.Helper()
+	reloaded, err := dao.GetWebhookDeliveries(context.Background(), delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reloaded
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int64
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(30*time.Second, 6*time.Hour, tt.attempt); got != tt.want {
+			t.Errorf("Backoff(30s, 6h, %d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	if got := Backoff(time.Minute, 30*time.Second, 1); got != 30*time.Second {
+		t.Errorf("Backoff with a base above max = %v, want the max", got)
+	}
+}
+
+func TestDeliverRetriesUntilFailed(t *testing.T) {
+	url, attempts := receiverStandIn(t, "s3cret", func() int { return http.StatusServiceUnavailable })
+	subscription := openTestDB(t, url, "s3cret")
+	ctx := context.Background()
+
+	now := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	d := NewDispatcher()
+	d.MaxAttempts = 3
+	d.Now = func() time.Time { return now }
+	delivery := queue(t, subscription, now)
+
+	received := func() attempt {
+		t.Helper()
+		select {
+		case a := <-attempts:
+			return a
+		default:
+			t.Fatal("the delivery was not attempted")
+			return attempt{}
+		}
+	}
+
+	notReceived := func() {
+		t.Helper()
+		select {
+		case a := <-attempts:
+			t.Fatalf("unexpected attempt %+v", a)
+		default:
+		}
+	}
+
+	// the first and second attempts are retried after 30 seconds and 1 minute
+	for i, backoff := range []time.Duration{30 * time.Second, time.Minute} {
+		if err := d.DeliverDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		a := received()
+		if !a.verified || a.delivery != strconv.FormatInt(delivery.ID, 10) || a.event != "interventions.update" {
+			t.Errorf("attempt %d = %+v, want a verified signature of delivery %d", i+1, a, delivery.ID)
+		}
+
+		got := reload(t, delivery)
+		if got.Status != dao.WebhookPending || got.Attempts != int64(i+1) {
+			t.Fatalf("after attempt %d the delivery is %s with %d attempts", i+1, got.Status, got.Attempts)
+		}
+		if !got.NextAttemptAt.Valid || !got.NextAttemptAt.Time.Equal(now.Add(backoff)) {
+			t.Errorf("after attempt %d the next attempt is at %v, want %v", i+1, got.NextAttemptAt.Time, now.Add(backoff))
+		}
+		if got.LastStatusCode.Int64 != http.StatusServiceUnavailable || !got.LastError.Valid {
+			t.Errorf("after attempt %d the last status is %d and error %q", i+1, got.LastStatusCode.Int64, got.LastError.String)
+		}
+
+		// not retried before its backoff elapsed
+		now = now.Add(backoff - time.Second)
+		if err := d.DeliverDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		notReceived()
+		now = now.Add(time.Second)
+	}
+
+	// the last attempt marks the delivery failed
+	if err := d.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	received()
+
+	got := reload(t, delivery)
+	if got.Status != dao.WebhookFailed || got.Attempts != 3 || got.NextAttemptAt.Valid {
+		t.Fatalf("after the last attempt the delivery is %s with %d attempts, next attempt %v", got.Status, got.Attempts, got.NextAttemptAt)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if err := d.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	notReceived()
+}
+
+func TestReplayDelivery(t *testing.T) {
+	status := http.StatusInternalServerError
+	url, attempts := receiverStandIn(t, "s3cret", func() int { return status })
+	subscription := openTestDB(t, url, "s3cret")
+	ctx := context.Background()
+
+	now := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	d := NewDispatcher()
+	d.MaxAttempts = 1
+	d.Now = func() time.Time { return now }
+
+	original := queue(t, subscription, now)
+	if err := d.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-attempts
+	if got := reload(t, original); got.Status != dao.WebhookFailed {
+		t.Fatalf("the original delivery is %s, want %s", got.Status, dao.WebhookFailed)
+	}
+
+	// the receiver recovered
+	status = http.StatusNoContent
+	now = now.Add(time.Hour)
+	replay, err := dao.ReplayWebhookDelivery(ctx, original.ID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.ID == original.ID || replay.ReplayOf.Int64 != original.ID || replay.Status != dao.WebhookPending || string(replay.Payload) != string(original.Payload) {
+		t.Fatalf("replay = %+v, want a pending copy of delivery %d", replay, original.ID)
+	}
+
+	if err := d.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case a := <-attempts:
+		if !a.verified || a.delivery != strconv.FormatInt(replay.ID, 10) {
+			t.Errorf("replayed attempt = %+v, want a verified signature of delivery %d", a, replay.ID)
+		}
+	default:
+		t.Fatal("the replay was not delivered")
+	}
+
+	got := reload(t, replay)
+	if got.Status != dao.WebhookDelivered || !got.DeliveredAt.Valid || got.LastStatusCode.Int64 != http.StatusNoContent {
+		t.Errorf("the replay is %s, delivered at %v with status %d", got.Status, got.DeliveredAt, got.LastStatusCode.Int64)
+	}
+	if got := reload(t, original); got.Status != dao.WebhookFailed || got.Attempts != 1 {
+		t.Errorf("replaying changed the original delivery to %s with %d attempts", got.Status, got.Attempts)
+	}
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderEvent header carrying the event name, ie interventions.update
+	HeaderEvent = "X-Rocket-Event"
+
+	// HeaderDelivery header carrying the webhook_deliveries id, it is the same for every retry of a delivery
+	HeaderDelivery = "X-Rocket-Delivery"
+
+	// HeaderTimestamp header carrying the unix time the request was signed at
+	HeaderTimestamp = "X-Rocket-Timestamp"
+
+	// HeaderSignature header carrying the signature of the request, see Sign
+	HeaderSignature = "X-Rocket-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign the signature of a request body sent at ts, the hex encoded HMAC-SHA256 of "<unix ts>.<body>" keyed with the subscription secret.
+// Including the timestamp lets receivers reject replayed requests.
+func Sign(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports if signature is the signature of body sent at the unix time timestamp, as received in the X-Rocket-* headers
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, time.Unix(unix, 0), body)), []byte(signature))
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	ts := time.Date(2021, 3, 4, 10, 11, 12, 0, time.UTC)
+	body := []byte(`{"event":"interventions.update","record_id":"12"}`)
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+
+	signature := Sign("s3cret", ts, body)
+	if !strings.HasPrefix(signature, "sha256=") || len(signature) != len("sha256=")+64 {
+		t.Fatalf("Sign = %q, want sha256= followed by a hex encoded sha256", signature)
+	}
+	if Sign("s3cret", ts, body) != signature {
+		t.Error("Sign is not deterministic")
+	}
+
+	if !Verify("s3cret", timestamp, body, signature) {
+		t.Error("Verify rejected the signature returned by Sign")
+	}
+
+	tests := []struct {
+		name                         string
+		secret, timestamp, signature string
+		body                         []byte
+	}{
+		{"other secret", "other", timestamp, signature, body},
+		{"other timestamp", "s3cret", strconv.FormatInt(ts.Unix()+1, 10), signature, body},
+		{"bad timestamp", "s3cret", "yesterday", signature, body},
+		{"tampered body", "s3cret", timestamp, signature, []byte(`{"event":"interventions.update","record_id":"13"}`)},
+		{"no prefix", "s3cret", timestamp, strings.TrimPrefix(signature, "sha256="), body},
+		{"empty signature", "s3cret", timestamp, "", body},
+	}
+
+	for _, tt := range tests {
+		if Verify(tt.secret, tt.timestamp, tt.body, tt.signature) {
+			t.Errorf("Verify accepted a signature with %s", tt.name)
+		}
+	}
+}